@@ -1,12 +1,26 @@
 package main
 
 import (
+	_Forum "DbGODZ/internal/app"
+	_Cache "DbGODZ/internal/app/cache"
 	_Handlers "DbGODZ/internal/app/delivery"
+	_Filter "DbGODZ/internal/app/filter"
+	_RateLimit "DbGODZ/internal/app/ratelimit"
 	_Repo "DbGODZ/internal/app/repository"
+	_Scheduler "DbGODZ/internal/app/scheduler"
+	_Storage "DbGODZ/internal/app/storage"
+	_Stream "DbGODZ/internal/app/stream"
+	_Views "DbGODZ/internal/app/views"
+	_Webhook "DbGODZ/internal/app/webhook"
+	_Middleware "DbGODZ/internal/pkg/middleware"
 	"github.com/fasthttp/router"
 	"github.com/jackc/pgx"
 	"github.com/rs/zerolog/log"
 	"github.com/valyala/fasthttp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func main() {
@@ -16,6 +30,13 @@ func main() {
 		return
 	}
 
+	if value, err := strconv.Atoi(os.Getenv("FORUM_MAX_DEPTH")); err == nil && value > 0 {
+		if pgxConn.RuntimeParams == nil {
+			pgxConn.RuntimeParams = map[string]string{}
+		}
+		pgxConn.RuntimeParams["forum.max_depth"] = strconv.Itoa(value)
+	}
+
 	// CONFIG DB
 	config := pgx.ConnPoolConfig{
 		ConnConfig:     pgxConn,
@@ -26,36 +47,202 @@ func main() {
 	connPool, err := pgx.NewConnPool(config)
 	if err != nil {
 		log.Error().Msgf(err.Error())
+		return
 	}
 	forumRepo := _Repo.NewPostgresForumRepository(connPool)
-	forumHandler := _Handlers.NewHandler(forumRepo)
+	streamHub := _Stream.NewHub(connPool, forumRepo)
+	go streamHub.Run()
+	webhookDispatcher := _Webhook.NewDispatcher(forumRepo)
+	go webhookDispatcher.Run()
+	viewCounter := _Views.NewCounter(forumRepo)
+	go viewCounter.Run()
+	attachmentStorage, err := newStorage()
+	if err != nil {
+		log.Error().Msgf(err.Error())
+		return
+	}
+	go _Storage.NewCollector(forumRepo, attachmentStorage).Run()
+	handlerRepo := newCachedRepository(forumRepo, connPool)
+	go _Scheduler.NewPublisher(handlerRepo).Run()
+	limiter, err := newLimiter(forumRepo)
+	if err != nil {
+		log.Error().Msgf(err.Error())
+		return
+	}
+	filters := _Filter.DefaultPipeline(forumRepo, newFilterConfig())
+	var admins []string
+	if value := os.Getenv("ADMIN_NICKNAMES"); value != "" {
+		admins = strings.Split(value, ",")
+	}
+	limits := _Handlers.DefaultLimits
+	if value, err := strconv.Atoi(os.Getenv("MAX_POST_BATCH")); err == nil && value > 0 {
+		limits.MaxPostBatch = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("MAX_MESSAGE_LENGTH")); err == nil && value > 0 {
+		limits.MaxMessageLength = value
+	}
+	forumHandler := _Handlers.NewHandler(handlerRepo, streamHub, viewCounter, attachmentStorage, limiter, filters,
+		admins, limits)
 
 	r := router.New()
-	r.POST("/api/user/{nickname}/create", forumHandler.Add)
-	r.GET("/api/user/{nickname}/profile", forumHandler.Get)
-	r.POST("/api/user/{nickname}/profile", forumHandler.Update)
-	r.GET("/api/forum/{slug}/users", forumHandler.GetByForum)
-	r.POST("/api/forum/create", forumHandler.AddForum)
-	r.GET("/api/forum/{slug}/details", forumHandler.GetForum)
-	r.POST("/api/forum/{slug}/create", forumHandler.AddThreadForum)
-	r.GET("/api/forum/{slug}/threads", forumHandler.GetThreadsForum)
-	r.GET("/api/thread/{slug_or_id}/details", forumHandler.GetThreadDetailsSlugForum)
-	r.POST("/api/thread/{slug_or_id}/details", forumHandler.UpdateThreadBySlugOrIDForum)
-	r.POST("/api/thread/{slug_or_id}/create", forumHandler.AddPostSlugForum)
-	r.GET("/api/thread/{slug_or_id}/posts", forumHandler.GetPostsSlugForum)
-	r.GET("/api/post/{id:[0-9]+}/details", forumHandler.GetPostByIDForum)
-	r.POST("/api/post/{id:[0-9]+}/details", forumHandler.UpdatePostForum)
-	r.POST("/api/thread/{id:[0-9]+}/vote", forumHandler.AddVoteIDForum)
-	r.POST("/api/thread/{slug}/vote", forumHandler.AddVoteSlugForum)
-	r.GET("/api/service/status", forumHandler.GetServiceStatusForum)
-	r.POST("/api/service/clear", forumHandler.ClearDataBaseForum)
-
-	log.Error().Msgf(fasthttp.ListenAndServe(":5000", JSONSetContentType(r.Handler)).Error())
+	r.SaveMatchedRoutePath = true
+	r.POST("/api/user/{nickname}/create", limiter.Handler(forumHandler.Add))
+	r.GET("/api/user/{nickname}/profile", limiter.Handler(forumHandler.Get))
+	r.POST("/api/user/{nickname}/profile", limiter.Handler(forumHandler.Update))
+	r.GET("/api/user/{nickname}/notifications", limiter.Handler(forumHandler.GetNotificationsForum))
+	r.POST("/api/user/{nickname}/notifications/read", limiter.Handler(forumHandler.MarkNotificationsReadForum))
+	r.GET("/api/user/{nickname}/subscriptions", limiter.Handler(forumHandler.GetSubscriptionsForum))
+	r.POST("/api/user/{nickname}/block", limiter.Handler(forumHandler.BlockUserForum))
+	r.POST("/api/user/{nickname}/unblock", limiter.Handler(forumHandler.UnblockUserForum))
+	r.GET("/api/user/{nickname}/blocks", limiter.Handler(forumHandler.GetBlocksForum))
+	r.GET("/api/user/{nickname}/drafts", limiter.Handler(forumHandler.GetDraftsForum))
+	r.GET("/api/users/leaderboard", limiter.Handler(forumHandler.GetLeaderboardForum))
+	r.GET("/api/tags", limiter.Handler(forumHandler.GetTagsForum))
+	r.POST("/api/conversation/create", limiter.Handler(forumHandler.AddConversationForum))
+	r.GET("/api/conversations", limiter.Handler(forumHandler.GetConversationsForum))
+	r.GET("/api/conversation/{id:[0-9]+}/messages", limiter.Handler(forumHandler.GetDirectMessagesForum))
+	r.POST("/api/conversation/{id:[0-9]+}/messages", limiter.Handler(forumHandler.AddDirectMessageForum))
+	r.GET("/api/forum/{slug}/users", limiter.Handler(forumHandler.GetByForum))
+	r.POST("/api/forum/create", limiter.Handler(forumHandler.AddForum))
+	r.GET("/api/forum/{slug}/details", limiter.Handler(forumHandler.GetForum))
+	r.POST("/api/forum/{slug}/create", limiter.Handler(forumHandler.AddThreadForum))
+	r.GET("/api/forum/{slug}/threads", limiter.Handler(forumHandler.GetThreadsForum))
+	r.GET("/api/forum/{slug}/stream", limiter.Handler(forumHandler.StreamForum))
+	r.GET("/api/forum/{slug}/leaderboard", limiter.Handler(forumHandler.GetForumLeaderboardForum))
+	r.POST("/api/forum/{slug}/subscribe", limiter.Handler(forumHandler.SubscribeForum))
+	r.POST("/api/forum/{slug}/unsubscribe", limiter.Handler(forumHandler.UnsubscribeForum))
+	r.GET("/api/thread/{slug_or_id}/details", limiter.Handler(forumHandler.GetThreadDetailsSlugForum))
+	r.POST("/api/thread/{slug_or_id}/details", limiter.Handler(forumHandler.UpdateThreadBySlugOrIDForum))
+	r.POST("/api/thread/{slug_or_id}/create", limiter.Handler(forumHandler.AddPostSlugForum))
+	r.GET("/api/thread/{slug_or_id}/posts", limiter.Handler(forumHandler.GetPostsSlugForum))
+	r.GET("/api/thread/{slug_or_id}/stream", limiter.Handler(forumHandler.StreamThreadForum))
+	r.POST("/api/thread/{slug_or_id}/subscribe", limiter.Handler(forumHandler.SubscribeThreadForum))
+	r.POST("/api/thread/{slug_or_id}/unsubscribe", limiter.Handler(forumHandler.UnsubscribeThreadForum))
+	r.POST("/api/thread/{slug_or_id}/read", limiter.Handler(forumHandler.MarkThreadReadForum))
+	r.POST("/api/thread/{slug_or_id}/mute", limiter.Handler(forumHandler.MuteThreadForum))
+	r.POST("/api/thread/{slug_or_id}/unmute", limiter.Handler(forumHandler.UnmuteThreadForum))
+	r.GET("/api/thread/{slug_or_id}/poll", limiter.Handler(forumHandler.GetPollForum))
+	r.POST("/api/thread/{slug_or_id}/poll/vote", limiter.Handler(forumHandler.VotePollForum))
+	r.POST("/api/thread/{slug_or_id}/approve", limiter.Handler(forumHandler.ApproveThreadForum))
+	r.POST("/api/thread/{slug_or_id}/publish", limiter.Handler(forumHandler.PublishThreadForum))
+	r.POST("/api/thread/{slug_or_id}/reject", limiter.Handler(forumHandler.RejectThreadForum))
+	r.GET("/api/post/{id:[0-9]+}/details", limiter.Handler(forumHandler.GetPostByIDForum))
+	r.POST("/api/post/{id:[0-9]+}/details", limiter.Handler(forumHandler.UpdatePostForum))
+	r.POST("/api/thread/{id:[0-9]+}/vote", limiter.Handler(forumHandler.AddVoteIDForum))
+	r.POST("/api/thread/{slug}/vote", limiter.Handler(forumHandler.AddVoteSlugForum))
+	r.GET("/api/thread/{slug_or_id}/votes", limiter.Handler(forumHandler.GetVotesForum))
+	r.POST("/api/post/{id:[0-9]+}/vote", limiter.Handler(forumHandler.VotePostForum))
+	r.POST("/api/post/{id:[0-9]+}/reactions", limiter.Handler(forumHandler.AddReactionForum))
+	r.DELETE("/api/post/{id:[0-9]+}/reactions", limiter.Handler(forumHandler.DeleteReactionForum))
+	r.POST("/api/post/{id:[0-9]+}/report", limiter.Handler(forumHandler.AddPostReportForum))
+	r.POST("/api/post/{id:[0-9]+}/attachments", limiter.Handler(forumHandler.AddAttachmentForum))
+	r.POST("/api/post/{id:[0-9]+}/approve", limiter.Handler(forumHandler.ApprovePostForum))
+	r.POST("/api/post/{id:[0-9]+}/reject", limiter.Handler(forumHandler.RejectPostForum))
+	r.GET("/api/attachment/{id:[0-9]+}", limiter.Handler(forumHandler.GetAttachmentForum))
+	r.POST("/api/thread/{slug_or_id}/report", limiter.Handler(forumHandler.AddThreadReportForum))
+	r.GET("/api/forum/{slug}/reports", limiter.Handler(forumHandler.GetReportsForum))
+	r.POST("/api/forum/{slug}/moderators", limiter.Handler(forumHandler.AddModeratorForum))
+	r.POST("/api/forum/{slug}/slowmode", limiter.Handler(forumHandler.SetSlowModeForum))
+	r.GET("/api/forum/{slug}/blocklist", limiter.Handler(forumHandler.GetBlocklistForum))
+	r.POST("/api/forum/{slug}/blocklist", limiter.Handler(forumHandler.AddBlockedWordForum))
+	r.DELETE("/api/forum/{slug}/blocklist", limiter.Handler(forumHandler.DeleteBlockedWordForum))
+	r.GET("/api/forum/{slug}/moderation", limiter.Handler(forumHandler.GetModerationQueueForum))
+	r.POST("/api/forum/{slug}/premoderation", limiter.Handler(forumHandler.SetPremoderationForum))
+	r.POST("/api/forum/{slug}/webhooks", limiter.Handler(forumHandler.AddWebhookForum))
+	r.GET("/api/forum/{slug}/webhooks", limiter.Handler(forumHandler.GetWebhooksForum))
+	r.GET("/api/forum/{slug}/webhooks/dead", limiter.Handler(forumHandler.GetDeadDeliveriesForum))
+	r.DELETE("/api/webhook/{id:[0-9]+}", limiter.Handler(forumHandler.DeleteWebhookForum))
+	r.POST("/api/webhook/delivery/{id:[0-9]+}/retry", limiter.Handler(forumHandler.RetryDeliveryForum))
+	r.POST("/api/report/{id:[0-9]+}/resolve", limiter.Handler(forumHandler.ResolveReportForum))
+	r.POST("/api/report/{id:[0-9]+}/dismiss", limiter.Handler(forumHandler.DismissReportForum))
+	r.GET("/api/admin/audit", limiter.Handler(forumHandler.GetAuditForum))
+	r.GET("/api/service/status", limiter.Handler(forumHandler.GetServiceStatusForum))
+	r.GET("/api/service/cache", limiter.Handler(forumHandler.GetCacheStatsForum))
+	r.POST("/api/service/clear", limiter.Handler(forumHandler.ClearDataBaseForum))
+
+	handler := r.Handler
+	if limiter != nil {
+		go limiter.Run()
+	}
+
+	maxRequestBodySize := 12 << 20
+	if value, err := strconv.Atoi(os.Getenv("MAX_REQUEST_BODY_SIZE")); err == nil && value > 0 {
+		maxRequestBodySize = value
+	}
+	handler = _Middleware.ConditionalGET(handler)
+	if os.Getenv("COMPRESSION_DISABLED") == "" {
+		handler = _Middleware.Compress(handler)
+	}
+
+	server := &fasthttp.Server{
+		Handler:            _Middleware.RequestID(_Middleware.JSONSetContentType(handler)),
+		ErrorHandler:       _Middleware.RequestError,
+		MaxRequestBodySize: maxRequestBodySize,
+	}
+	log.Error().Msgf(server.ListenAndServe(":5000").Error())
+}
+
+func newStorage() (_Storage.Storage, error) {
+	if endpoint := os.Getenv("STORAGE_S3_ENDPOINT"); endpoint != "" {
+		return _Storage.NewS3Storage(endpoint, os.Getenv("STORAGE_S3_REGION"), os.Getenv("STORAGE_S3_BUCKET"),
+			os.Getenv("STORAGE_S3_ACCESS_KEY"), os.Getenv("STORAGE_S3_SECRET_KEY"))
+	}
+
+	root := os.Getenv("STORAGE_DIR")
+	if root == "" {
+		root = "uploads"
+	}
+	return _Storage.NewLocalStorage(root)
+}
+
+func newCachedRepository(forumRepo _Forum.Repository, connPool *pgx.ConnPool) _Forum.Repository {
+	if os.Getenv("CACHE_DISABLED") != "" {
+		return forumRepo
+	}
+
+	size := 10000
+	if value, err := strconv.Atoi(os.Getenv("CACHE_SIZE")); err == nil && value > 0 {
+		size = value
+	}
+	ttl := 30 * time.Second
+	if value, err := time.ParseDuration(os.Getenv("CACHE_TTL")); err == nil && value > 0 {
+		ttl = value
+	}
+
+	cachedRepo := _Cache.NewRepository(forumRepo, connPool, _Cache.NewLRU(size, ttl))
+	go cachedRepo.Run()
+	return cachedRepo
 }
 
-func JSONSetContentType(req fasthttp.RequestHandler) fasthttp.RequestHandler {
-	return func(ctx *fasthttp.RequestCtx) {
-		ctx.Response.Header.Set("Content-Type", "application/json")
-		req(ctx)
+func newLimiter(forumRepo _Forum.Repository) (*_RateLimit.Limiter, error) {
+	if os.Getenv("RATE_LIMIT_ENABLED") == "" {
+		return nil, nil
+	}
+
+	budgets, err := _RateLimit.ParseBudgets(os.Getenv("RATE_LIMIT_BUDGETS"), _RateLimit.DefaultBudgets)
+	if err != nil {
+		return nil, err
+	}
+	if os.Getenv("RATE_LIMIT_STORE") == "postgres" {
+		return _RateLimit.NewLimiter(_RateLimit.NewPostgresStore(forumRepo), budgets, _RateLimit.DefaultRoutes), nil
+	}
+	return _RateLimit.NewLimiter(_RateLimit.NewMemoryStore(), budgets, _RateLimit.DefaultRoutes), nil
+}
+
+func newFilterConfig() _Filter.Config {
+	var config _Filter.Config
+	if os.Getenv("CONTENT_FILTER_ENABLED") != "" {
+		config = _Filter.DefaultConfig
+	}
+
+	if value, err := strconv.Atoi(os.Getenv("CONTENT_FILTER_MAX_LINKS")); err == nil && value >= 0 {
+		config.MaxLinks = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("CONTENT_FILTER_LINK_ACCOUNT_AGE")); err == nil && value > 0 {
+		config.LinkAccountAge = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("CONTENT_FILTER_DUPLICATE_WINDOW")); err == nil && value > 0 {
+		config.DuplicateWindow = value
 	}
+	return config
 }
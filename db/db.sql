@@ -19,7 +19,9 @@ CREATE UNLOGGED TABLE "users"
     About    text,
     Email    citext UNIQUE,
     FullName text NOT NULL,
-    Nickname citext COLLATE "ucs_basic" PRIMARY KEY
+    Nickname citext COLLATE "ucs_basic" PRIMARY KEY,
+    Karma    BIGINT DEFAULT 0,
+    Created  timestamp with time zone default now()
 );
 
 CREATE UNLOGGED TABLE forum
@@ -29,9 +31,48 @@ CREATE UNLOGGED TABLE forum
     Slug    citext PRIMARY KEY,
     Threads INT    DEFAULT 0,
     title   text,
-    FOREIGN KEY ("user") REFERENCES "users" (nickname)
+    parent   citext,
+    position INT    DEFAULT 0,
+    depth    INT    DEFAULT 0,
+    path     text[] DEFAULT array []::text[],
+    slowMode INT    DEFAULT 0,
+    premoderated BOOLEAN DEFAULT FALSE,
+    FOREIGN KEY ("user") REFERENCES "users" (nickname),
+    FOREIGN KEY (parent) REFERENCES "forum" (slug)
 );
 
+CREATE OR REPLACE FUNCTION max_forum_depth() RETURNS INT AS
+$$
+SELECT COALESCE(NULLIF(current_setting('forum.max_depth', true), '')::INT, 3);
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION update_forum_path() RETURNS TRIGGER AS
+$$
+DECLARE
+    parent_slug  citext;
+    parent_depth INT;
+    parent_path  text[];
+BEGIN
+    IF (NEW.parent IS NULL) THEN
+        NEW.depth := 0;
+        NEW.path := ARRAY [NEW.slug::text];
+    ELSE
+        SELECT slug, depth, path FROM forum WHERE slug = NEW.parent INTO parent_slug, parent_depth, parent_path;
+        IF NOT FOUND THEN
+            RAISE EXCEPTION 'parent forum not found' USING ERRCODE = '00404';
+        end if;
+        IF parent_depth + 1 >= max_forum_depth() THEN
+            RAISE EXCEPTION 'forum depth limit of % exceeded', max_forum_depth() USING ERRCODE = '00400';
+        end if;
+
+        NEW.parent := parent_slug;
+        NEW.depth := parent_depth + 1;
+        NEW.path := parent_path || NEW.slug::text;
+    end if;
+    RETURN NEW;
+end
+$$ LANGUAGE plpgsql;
+
 CREATE UNLOGGED TABLE thread
 (
     author  citext,
@@ -42,13 +83,25 @@ CREATE UNLOGGED TABLE thread
     slug    citext UNIQUE,
     title   text not null,
     votes   INT                      default 0,
+    postCount  INT                   default 0,
+    lastPostAt timestamp with time zone,
+    views      BIGINT                default 0,
+    tags       text[]                default array []::text[],
+    messageHtml text                 default '',
+    status     text                  default 'published',
+    statusReason text,
+    publishAt  timestamp with time zone,
+    edited     timestamp with time zone,
     FOREIGN KEY (author) REFERENCES "users" (nickname),
-    FOREIGN KEY (forum) REFERENCES "forum" (slug)
+    FOREIGN KEY (forum) REFERENCES "forum" (slug),
+    CHECK (status IN ('published', 'pending', 'rejected', 'draft', 'scheduled'))
 );
 CREATE OR REPLACE FUNCTION update_user_forum() RETURNS TRIGGER AS
 $$
 BEGIN
-    INSERT INTO users_forum (nickname, Slug) VALUES (NEW.author, NEW.forum) on conflict do nothing;
+    IF (NEW.status = 'published') THEN
+        INSERT INTO users_forum (nickname, Slug) VALUES (NEW.author, NEW.forum) on conflict do nothing;
+    end if;
     return NEW;
 end
 $$ LANGUAGE plpgsql;
@@ -65,10 +118,18 @@ CREATE UNLOGGED TABLE post
     parent   BIGINT                   DEFAULT 0,
     thread   INT,
     path     BIGINT[]                 default array []::INTEGER[],
+    score    INT                      DEFAULT 0,
+    reactions jsonb                   DEFAULT '{}',
+    messageHtml text                  DEFAULT '',
+    attachments jsonb                 DEFAULT '[]',
+    status   text                     DEFAULT 'published',
+    statusReason text,
+    edited   timestamp with time zone,
     FOREIGN KEY (author) REFERENCES "users" (nickname),
     FOREIGN KEY (forum) REFERENCES "forum" (slug),
     FOREIGN KEY (thread) REFERENCES "thread" (id),
-    FOREIGN KEY (parent) REFERENCES "post" (id)
+    FOREIGN KEY (parent) REFERENCES "post" (id),
+    CHECK (status IN ('published', 'pending', 'rejected'))
 );
 
 CREATE OR REPLACE FUNCTION update_path() RETURNS TRIGGER AS
@@ -88,7 +149,9 @@ BEGIN
 
         NEW.path := NEW.path || parent_path || new.id;
     end if;
-    UPDATE forum SET Posts=Posts + 1 WHERE lower(forum.slug) = lower(new.forum);
+    IF (NEW.status = 'published') THEN
+        UPDATE forum SET Posts=Posts + 1 WHERE slug = ANY ((SELECT path FROM forum f WHERE f.slug = new.forum)::citext[]);
+    end if;
     RETURN new;
 end
 $$ LANGUAGE plpgsql;
@@ -98,10 +161,12 @@ CREATE UNLOGGED TABLE vote
     nickname citext NOT NULL,
     voice    INT,
     idThread INT,
+    voted    timestamp with time zone default now(),
 
     FOREIGN KEY (nickname) REFERENCES "users" (nickname),
     FOREIGN KEY (idThread) REFERENCES "thread" (id),
-    UNIQUE (nickname, idThread)
+    UNIQUE (nickname, idThread),
+    CHECK (voice IN (-1, 1))
 );
 
 
@@ -114,26 +179,671 @@ CREATE UNLOGGED TABLE users_forum
     UNIQUE (nickname, Slug)
 );
 
+CREATE UNLOGGED TABLE moderator
+(
+    nickname citext COLLATE "ucs_basic" NOT NULL,
+    forum    citext NOT NULL,
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    FOREIGN KEY (forum) REFERENCES "forum" (slug),
+    UNIQUE (nickname, forum)
+);
+
+CREATE UNLOGGED TABLE report
+(
+    author   citext NOT NULL,
+    closedBy citext,
+    created  timestamp with time zone default now(),
+    forum    citext NOT NULL,
+    id       BIGSERIAL PRIMARY KEY,
+    post     BIGINT,
+    reason   text   NOT NULL,
+    status   text                     default 'open',
+    thread   INT    NOT NULL,
+    FOREIGN KEY (author) REFERENCES "users" (nickname),
+    FOREIGN KEY (closedBy) REFERENCES "users" (nickname),
+    FOREIGN KEY (forum) REFERENCES "forum" (slug),
+    FOREIGN KEY (post) REFERENCES "post" (id),
+    FOREIGN KEY (thread) REFERENCES "thread" (id),
+    CHECK (status IN ('open', 'resolved', 'dismissed'))
+);
+
+CREATE TABLE audit
+(
+    action     text NOT NULL,
+    actor      citext,
+    anonymous  boolean NOT NULL DEFAULT FALSE,
+    after      jsonb,
+    before     jsonb,
+    created    timestamp with time zone default now(),
+    forum      citext,
+    id         BIGSERIAL PRIMARY KEY,
+    requestId  text NOT NULL,
+    targetId   text NOT NULL,
+    targetType text NOT NULL,
+    CHECK (anonymous = (actor IS NULL))
+);
+
+CREATE OR REPLACE FUNCTION audit_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit log is append-only' USING ERRCODE = '42501';
+end
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE webhook
+(
+    created timestamp with time zone default now(),
+    events  text[] NOT NULL,
+    forum   citext NOT NULL,
+    id      SERIAL PRIMARY KEY,
+    secret  text   NOT NULL,
+    url     text   NOT NULL
+);
+
+CREATE TABLE outbox
+(
+    attempts    INT                      default 0,
+    created     timestamp with time zone default now(),
+    event       text  NOT NULL,
+    id          BIGSERIAL PRIMARY KEY,
+    lastError   text,
+    nextAttempt timestamp with time zone default now(),
+    payload     jsonb NOT NULL,
+    status      text                     default 'pending',
+    webhook     INT   NOT NULL,
+    FOREIGN KEY (webhook) REFERENCES "webhook" (id) ON DELETE CASCADE,
+    CHECK (status IN ('pending', 'dead'))
+);
+
+CREATE OR REPLACE FUNCTION enqueue_webhooks(forum_slug citext, event_name text, payload jsonb) RETURNS VOID AS
+$$
+BEGIN
+    INSERT INTO outbox (webhook, event, payload)
+    SELECT id, event_name, payload
+    FROM webhook
+    WHERE forum = forum_slug
+      AND event_name = ANY (events);
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION outbox_thread() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (NEW.status <> 'published' OR (TG_OP = 'UPDATE' AND OLD.status = 'published')) THEN
+        return NEW;
+    end if;
+    PERFORM enqueue_webhooks(NEW.forum, 'thread.created', json_build_object(
+            'author', NEW.author,
+            'created', NEW.created,
+            'forum', NEW.forum,
+            'id', NEW.id,
+            'message', NEW.message,
+            'slug', NEW.slug,
+            'title', NEW.title,
+            'votes', NEW.votes)::jsonb);
+    return NEW;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION outbox_post() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (NEW.status <> 'published') THEN
+        return NEW;
+    end if;
+    PERFORM enqueue_webhooks(NEW.forum,
+                             CASE
+                                 WHEN TG_OP = 'INSERT' OR OLD.status <> 'published' THEN 'post.created'
+                                 ELSE 'post.updated' END,
+                             json_build_object(
+                                     'author', NEW.author,
+                                     'created', NEW.created,
+                                     'forum', NEW.forum,
+                                     'id', NEW.id,
+                                     'isEdited', NEW.isEdited,
+                                     'message', NEW.message,
+                                     'parent', NEW.parent,
+                                     'thread', NEW.thread)::jsonb);
+    return NEW;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION outbox_votes() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM enqueue_webhooks(NEW.forum, 'vote.changed', json_build_object(
+            'forum', NEW.forum,
+            'thread', NEW.id,
+            'slug', NEW.slug,
+            'votes', NEW.votes)::jsonb);
+    return NEW;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE UNLOGGED TABLE notification
+(
+    author   citext NOT NULL,
+    created  timestamp with time zone default now(),
+    forum    citext NOT NULL,
+    id       BIGSERIAL PRIMARY KEY,
+    isRead   BOOLEAN                  DEFAULT FALSE,
+    kind     text   NOT NULL,
+    nickname citext NOT NULL,
+    post     BIGINT NOT NULL,
+    thread   INT    NOT NULL,
+    FOREIGN KEY (author) REFERENCES "users" (nickname),
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    FOREIGN KEY (post) REFERENCES "post" (id),
+    UNIQUE (nickname, post)
+);
+
+CREATE OR REPLACE FUNCTION create_notifications(post_ids BIGINT[]) RETURNS VOID AS
+$$
+BEGIN
+    INSERT INTO notification (nickname, kind, author, post, thread, forum)
+    SELECT DISTINCT ON (t.nickname, t.post) t.nickname, t.kind, t.author, t.post, t.thread, t.forum
+    FROM (SELECT parent.author AS nickname, 'reply' AS kind, 0 AS priority, n.author, n.id AS post, n.thread, n.forum
+          FROM post n
+                   JOIN post parent ON parent.id = n.parent
+          WHERE n.id = ANY (post_ids)
+          UNION ALL
+          SELECT u.nickname, 'mention', 1, n.author, n.id, n.thread, n.forum
+          FROM post n
+                   CROSS JOIN LATERAL regexp_matches(n.message, '@([A-Za-z0-9_.]+)', 'g') AS m
+                   JOIN users u ON u.nickname = rtrim(m[1], '.')
+          WHERE n.id = ANY (post_ids)) t
+    WHERE t.nickname <> t.author
+      AND NOT EXISTS(SELECT 1 FROM user_block b WHERE b.nickname = t.nickname AND b.blocked = t.author)
+      AND NOT EXISTS(SELECT 1 FROM thread_mute m WHERE m.nickname = t.nickname AND m.thread = t.thread)
+    ORDER BY t.nickname, t.post, t.priority
+    ON CONFLICT DO NOTHING;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_users() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM create_notifications(ARRAY(SELECT id FROM new_posts WHERE status = 'published'));
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION update_thread_activity() RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE thread
+    SET postCount  = thread.postCount + n.count,
+        lastPostAt = GREATEST(thread.lastPostAt, n.lastPostAt)
+    FROM (SELECT new_posts.thread, COUNT(*) AS count, MAX(new_posts.created) AS lastPostAt
+          FROM new_posts
+          WHERE new_posts.status = 'published'
+          GROUP BY new_posts.thread) n
+    WHERE thread.id = n.thread;
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE UNLOGGED TABLE user_block
+(
+    nickname citext NOT NULL,
+    blocked  citext NOT NULL,
+    created  timestamp with time zone default now(),
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    FOREIGN KEY (blocked) REFERENCES "users" (nickname),
+    PRIMARY KEY (nickname, blocked),
+    CHECK (nickname <> blocked)
+);
+
+CREATE UNLOGGED TABLE thread_mute
+(
+    nickname citext NOT NULL,
+    thread   INT    NOT NULL,
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    FOREIGN KEY (thread) REFERENCES "thread" (id),
+    PRIMARY KEY (nickname, thread)
+);
+
+CREATE UNLOGGED TABLE thread_subscription
+(
+    nickname citext NOT NULL,
+    thread   INT    NOT NULL,
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    FOREIGN KEY (thread) REFERENCES "thread" (id),
+    PRIMARY KEY (nickname, thread)
+);
+
+CREATE UNLOGGED TABLE forum_subscription
+(
+    lastRead BIGINT DEFAULT 0,
+    nickname citext NOT NULL,
+    forum    citext NOT NULL,
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    FOREIGN KEY (forum) REFERENCES "forum" (slug),
+    PRIMARY KEY (nickname, forum)
+);
+
+CREATE UNLOGGED TABLE thread_read
+(
+    lastRead BIGINT DEFAULT 0,
+    nickname citext NOT NULL,
+    thread   INT    NOT NULL,
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    FOREIGN KEY (thread) REFERENCES "thread" (id),
+    PRIMARY KEY (thread, nickname)
+);
+
+CREATE UNLOGGED TABLE user_karma
+(
+    forum    citext NOT NULL,
+    karma    BIGINT DEFAULT 0,
+    nickname citext NOT NULL,
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    FOREIGN KEY (forum) REFERENCES "forum" (slug),
+    PRIMARY KEY (forum, nickname)
+);
+
+CREATE OR REPLACE FUNCTION add_karma(author citext, voter citext, forum_slug citext, delta INT) RETURNS VOID AS
+$$
+BEGIN
+    IF (delta = 0 OR author = voter) THEN
+        RETURN;
+    end if;
+    UPDATE users SET karma=karma + delta WHERE nickname = author;
+    INSERT INTO user_karma (forum, nickname, karma)
+    VALUES (forum_slug, author, delta)
+    ON CONFLICT (forum, nickname) DO UPDATE SET karma=user_karma.karma + EXCLUDED.karma;
+end
+$$ LANGUAGE plpgsql;
+
 CREATE OR REPLACE FUNCTION insert_votes() RETURNS TRIGGER AS
 $$
+DECLARE
+    thread_author citext;
+    thread_forum  citext;
 BEGIN
-    UPDATE thread SET votes=(votes+NEW.voice) WHERE id=NEW.idThread;
+    UPDATE thread SET votes=(votes+NEW.voice) WHERE id=NEW.idThread RETURNING author, forum INTO thread_author, thread_forum;
+    PERFORM add_karma(thread_author, NEW.nickname, thread_forum, NEW.voice);
     return NEW;
 end
 $$ LANGUAGE plpgsql;
 
 CREATE OR REPLACE FUNCTION update_votes() RETURNS TRIGGER AS
 $$
+DECLARE
+    thread_author citext;
+    thread_forum  citext;
 BEGIN
-    UPDATE thread SET votes=(votes+NEW.voice*2) WHERE id=NEW.idThread;
+    IF (NEW.voice <> OLD.voice) THEN
+        UPDATE thread SET votes=(votes+NEW.voice-OLD.voice) WHERE id=NEW.idThread RETURNING author, forum INTO thread_author, thread_forum;
+        PERFORM add_karma(thread_author, NEW.nickname, thread_forum, NEW.voice - OLD.voice);
+    end if;
     return NEW;
 end
 $$ LANGUAGE plpgsql;
 
+CREATE OR REPLACE FUNCTION delete_votes() RETURNS TRIGGER AS
+$$
+DECLARE
+    thread_author citext;
+    thread_forum  citext;
+BEGIN
+    UPDATE thread SET votes=(votes-OLD.voice) WHERE id=OLD.idThread RETURNING author, forum INTO thread_author, thread_forum;
+    PERFORM add_karma(thread_author, OLD.nickname, thread_forum, -OLD.voice);
+    return OLD;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE UNLOGGED TABLE post_vote
+(
+    nickname citext NOT NULL,
+    post     BIGINT NOT NULL,
+    voice    INT    NOT NULL,
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    FOREIGN KEY (post) REFERENCES "post" (id),
+    PRIMARY KEY (post, nickname),
+    CHECK (voice IN (-1, 1))
+);
+
+CREATE OR REPLACE FUNCTION update_post_score() RETURNS TRIGGER AS
+$$
+DECLARE
+    post_author citext;
+    post_forum  citext;
+BEGIN
+    IF (TG_OP = 'INSERT') THEN
+        UPDATE post SET score=score + NEW.voice WHERE id = NEW.post RETURNING author, forum INTO post_author, post_forum;
+        PERFORM add_karma(post_author, NEW.nickname, post_forum, NEW.voice);
+    ELSIF (TG_OP = 'UPDATE') THEN
+        UPDATE post SET score=score + NEW.voice - OLD.voice WHERE id = NEW.post RETURNING author, forum INTO post_author, post_forum;
+        PERFORM add_karma(post_author, NEW.nickname, post_forum, NEW.voice - OLD.voice);
+    ELSE
+        UPDATE post SET score=score - OLD.voice WHERE id = OLD.post RETURNING author, forum INTO post_author, post_forum;
+        PERFORM add_karma(post_author, OLD.nickname, post_forum, -OLD.voice);
+    end if;
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE UNLOGGED TABLE post_reaction
+(
+    emoji    text   NOT NULL,
+    nickname citext NOT NULL,
+    post     BIGINT NOT NULL,
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    FOREIGN KEY (post) REFERENCES "post" (id),
+    PRIMARY KEY (post, nickname, emoji)
+);
+
+CREATE OR REPLACE FUNCTION update_post_reactions() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (TG_OP = 'INSERT') THEN
+        UPDATE post
+        SET reactions=jsonb_set(reactions, ARRAY [NEW.emoji],
+                                to_jsonb(COALESCE((reactions ->> NEW.emoji)::INT, 0) + 1))
+        WHERE id = NEW.post;
+    ELSE
+        UPDATE post
+        SET reactions=CASE
+                          WHEN COALESCE((reactions ->> OLD.emoji)::INT, 0) <= 1 THEN reactions - OLD.emoji
+                          ELSE jsonb_set(reactions, ARRAY [OLD.emoji], to_jsonb((reactions ->> OLD.emoji)::INT - 1))
+            END
+        WHERE id = OLD.post;
+    end if;
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE UNLOGGED TABLE poll
+(
+    id       SERIAL PRIMARY KEY,
+    thread   INT    NOT NULL UNIQUE,
+    question text   NOT NULL,
+    multiple BOOLEAN                  DEFAULT FALSE,
+    closesAt timestamp with time zone,
+    voters   INT                      DEFAULT 0,
+    FOREIGN KEY (thread) REFERENCES "thread" (id)
+);
+
+CREATE UNLOGGED TABLE poll_option
+(
+    id       SERIAL PRIMARY KEY,
+    poll     INT  NOT NULL,
+    position INT  NOT NULL,
+    text     text NOT NULL,
+    votes    INT DEFAULT 0,
+    FOREIGN KEY (poll) REFERENCES "poll" (id),
+    UNIQUE (poll, id)
+);
+
+CREATE UNLOGGED TABLE poll_voter
+(
+    poll     INT    NOT NULL,
+    nickname citext NOT NULL,
+    FOREIGN KEY (poll) REFERENCES "poll" (id),
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    PRIMARY KEY (poll, nickname)
+);
+
+CREATE UNLOGGED TABLE poll_vote
+(
+    poll     INT    NOT NULL,
+    option   INT    NOT NULL,
+    nickname citext NOT NULL,
+    FOREIGN KEY (poll, nickname) REFERENCES "poll_voter" (poll, nickname),
+    FOREIGN KEY (poll, option) REFERENCES "poll_option" (poll, id),
+    PRIMARY KEY (poll, nickname, option)
+);
+
+CREATE OR REPLACE FUNCTION check_poll_open() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF EXISTS(SELECT 1 FROM poll WHERE id = NEW.poll AND closesAt <= now()) THEN
+        RAISE EXCEPTION 'poll is closed' USING ERRCODE = '00403';
+    end if;
+    UPDATE poll SET voters=voters + 1 WHERE id = NEW.poll;
+    return NEW;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION update_poll_votes() RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE poll_option SET votes=votes + 1 WHERE id = NEW.option;
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE UNLOGGED TABLE attachment
+(
+    id          BIGSERIAL PRIMARY KEY,
+    post        BIGINT,
+    author      citext NOT NULL,
+    filename    text   NOT NULL,
+    contentType text   NOT NULL,
+    size        BIGINT NOT NULL,
+    storageKey  text   NOT NULL UNIQUE,
+    created     timestamp with time zone default now()
+);
+
+CREATE OR REPLACE FUNCTION update_post_attachments() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (TG_OP = 'INSERT') THEN
+        UPDATE post
+        SET attachments=attachments || jsonb_build_array(jsonb_build_object(
+                'author', NEW.author,
+                'contentType', NEW.contentType,
+                'created', to_char(NEW.created AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.MS"Z"'),
+                'filename', NEW.filename,
+                'id', NEW.id,
+                'post', NEW.post,
+                'size', NEW.size,
+                'url', '/api/attachment/' || NEW.id))
+        WHERE id = NEW.post;
+    ELSIF (OLD.post IS NOT NULL) THEN
+        UPDATE post
+        SET attachments=(SELECT COALESCE(jsonb_agg(a), '[]')
+                         FROM jsonb_array_elements(attachments) a
+                         WHERE (a ->> 'id')::BIGINT <> OLD.id)
+        WHERE id = OLD.post;
+    end if;
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION orphan_attachments() RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE attachment SET post = NULL WHERE post = OLD.id;
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE UNLOGGED TABLE conversation
+(
+    id            SERIAL PRIMARY KEY,
+    created       timestamp with time zone default now(),
+    lastMessageAt timestamp with time zone
+);
+
+CREATE UNLOGGED TABLE conversation_member
+(
+    conversation INT    NOT NULL,
+    nickname     citext NOT NULL,
+    lastRead     BIGINT DEFAULT 0,
+    FOREIGN KEY (conversation) REFERENCES "conversation" (id),
+    FOREIGN KEY (nickname) REFERENCES "users" (nickname),
+    PRIMARY KEY (conversation, nickname)
+);
+
+CREATE UNLOGGED TABLE direct_message
+(
+    id           BIGSERIAL PRIMARY KEY,
+    conversation INT    NOT NULL,
+    author       citext NOT NULL,
+    message      text   NOT NULL,
+    created      timestamp with time zone default now(),
+    FOREIGN KEY (conversation, author) REFERENCES "conversation_member" (conversation, nickname)
+);
+
+CREATE OR REPLACE FUNCTION update_conversation() RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE conversation SET lastMessageAt=NEW.created WHERE id = NEW.conversation;
+    UPDATE conversation_member
+    SET lastRead=NEW.id
+    WHERE conversation = NEW.conversation
+      AND nickname = NEW.author;
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE UNLOGGED TABLE rate_bucket
+(
+    key     text PRIMARY KEY,
+    tokens  double precision NOT NULL,
+    updated timestamp with time zone default now()
+);
+
+CREATE UNLOGGED TABLE forum_blocklist
+(
+    action  text   NOT NULL,
+    created timestamp with time zone default now(),
+    forum   citext NOT NULL,
+    word    text   NOT NULL,
+    FOREIGN KEY (forum) REFERENCES "forum" (slug),
+    UNIQUE (forum, word),
+    CHECK (action IN ('reject', 'hold'))
+);
+
 CREATE OR REPLACE FUNCTION update_threads_count() RETURNS TRIGGER AS
 $$
 BEGIN
-    UPDATE forum SET Threads=(Threads+1) WHERE LOWER(slug)=LOWER(NEW.forum);
+    IF (NEW.status = 'published') THEN
+        UPDATE forum SET Threads=(Threads+1) WHERE slug = ANY ((SELECT path FROM forum f WHERE f.slug = NEW.forum)::citext[]);
+    end if;
+    return NEW;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION update_thread_status() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (NEW.status = 'published') THEN
+        UPDATE forum SET Threads=(Threads+1) WHERE slug = ANY ((SELECT path FROM forum f WHERE f.slug = NEW.forum)::citext[]);
+        INSERT INTO users_forum (nickname, Slug) VALUES (NEW.author, NEW.forum) on conflict do nothing;
+    ELSIF (OLD.status = 'published') THEN
+        UPDATE forum SET Threads=(Threads-1) WHERE slug = ANY ((SELECT path FROM forum f WHERE f.slug = NEW.forum)::citext[]);
+    end if;
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION update_post_status() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (NEW.status = 'published') THEN
+        UPDATE forum SET Posts=Posts + 1 WHERE slug = ANY ((SELECT path FROM forum f WHERE f.slug = NEW.forum)::citext[]);
+        UPDATE thread
+        SET postCount  = postCount + 1,
+            lastPostAt = GREATEST(lastPostAt, NEW.created)
+        WHERE id = NEW.thread;
+        INSERT INTO users_forum (nickname, Slug) VALUES (NEW.author, NEW.forum) on conflict do nothing;
+        PERFORM create_notifications(ARRAY [NEW.id]);
+    ELSIF (OLD.status = 'published') THEN
+        UPDATE forum SET Posts=Posts - 1 WHERE slug = ANY ((SELECT path FROM forum f WHERE f.slug = NEW.forum)::citext[]);
+        UPDATE thread SET postCount = postCount - 1 WHERE id = NEW.thread;
+    end if;
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_post() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (NEW.status <> 'published') THEN
+        return NEW;
+    end if;
+    PERFORM pg_notify('forum_events', json_build_object(
+            'event', CASE
+                         WHEN TG_OP = 'INSERT' OR OLD.status <> 'published' THEN 'post.created'
+                         ELSE 'post.updated' END,
+            'id', NEW.id,
+            'thread', NEW.thread,
+            'forum', NEW.forum)::text);
+    return NEW;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_cache_forum() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (TG_OP = 'TRUNCATE') THEN
+        PERFORM pg_notify('cache_invalidate', 'all');
+        return NULL;
+    end if;
+    PERFORM pg_notify('cache_invalidate', 'forum:' || lower(NEW.slug));
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_cache_thread() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('cache_invalidate', 'thread:' || NEW.id);
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_cache_post() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('cache_invalidate', 'thread:' || NEW.thread);
+    IF (TG_OP = 'UPDATE') THEN
+        PERFORM pg_notify('cache_invalidate', 'post:' || NEW.id);
+    end if;
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_cache_user() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('cache_invalidate', 'user:' || lower(NEW.nickname));
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_cache_karma() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('cache_invalidate', 'leaderboard:' || lower(NEW.forum));
+    PERFORM pg_notify('cache_invalidate', 'leaderboard:');
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_cache_block() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (TG_OP = 'DELETE') THEN
+        PERFORM pg_notify('cache_invalidate', 'viewer:' || lower(OLD.nickname));
+    ELSE
+        PERFORM pg_notify('cache_invalidate', 'viewer:' || lower(NEW.nickname));
+    end if;
+    return NULL;
+end
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_votes() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('forum_events', json_build_object(
+            'event', 'vote.changed',
+            'id', NEW.id,
+            'thread', NEW.id,
+            'forum', NEW.forum)::text);
     return NEW;
 end
 $$ LANGUAGE plpgsql;
@@ -157,11 +867,59 @@ CREATE TRIGGER path_update_trigger
 EXECUTE PROCEDURE update_path();
 
 CREATE TRIGGER add_vote
-    BEFORE INSERT
+    AFTER INSERT
     ON vote
     FOR EACH ROW
 EXECUTE PROCEDURE insert_votes();
 
+CREATE TRIGGER post_vote_score
+    AFTER INSERT OR UPDATE OR DELETE
+    ON post_vote
+    FOR EACH ROW
+EXECUTE PROCEDURE update_post_score();
+
+CREATE TRIGGER post_reaction_count
+    AFTER INSERT OR DELETE
+    ON post_reaction
+    FOR EACH ROW
+EXECUTE PROCEDURE update_post_reactions();
+
+CREATE TRIGGER poll_voter_open
+    BEFORE INSERT
+    ON poll_voter
+    FOR EACH ROW
+EXECUTE PROCEDURE check_poll_open();
+
+CREATE TRIGGER poll_vote_count
+    AFTER INSERT
+    ON poll_vote
+    FOR EACH ROW
+EXECUTE PROCEDURE update_poll_votes();
+
+CREATE TRIGGER forum_path_trigger
+    BEFORE INSERT
+    ON forum
+    FOR EACH ROW
+EXECUTE PROCEDURE update_forum_path();
+
+CREATE TRIGGER attachment_post_list
+    AFTER INSERT OR DELETE
+    ON attachment
+    FOR EACH ROW
+EXECUTE PROCEDURE update_post_attachments();
+
+CREATE TRIGGER post_delete_attachments
+    AFTER DELETE
+    ON post
+    FOR EACH ROW
+EXECUTE PROCEDURE orphan_attachments();
+
+CREATE TRIGGER direct_message_conversation
+    AFTER INSERT
+    ON direct_message
+    FOR EACH ROW
+EXECUTE PROCEDURE update_conversation();
+
 CREATE TRIGGER add_thread_to_forum
     BEFORE INSERT
     ON thread
@@ -169,19 +927,142 @@ CREATE TRIGGER add_thread_to_forum
 EXECUTE PROCEDURE update_threads_count();
 
 CREATE TRIGGER edit_vote
-    BEFORE UPDATE
+    AFTER UPDATE
     ON vote
     FOR EACH ROW
 EXECUTE PROCEDURE update_votes();
 
+CREATE TRIGGER remove_vote
+    AFTER DELETE
+    ON vote
+    FOR EACH ROW
+EXECUTE PROCEDURE delete_votes();
+
+CREATE TRIGGER post_notify
+    AFTER INSERT OR UPDATE OF message, status
+    ON post
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_post();
+
+CREATE TRIGGER thread_votes_notify
+    AFTER UPDATE OF votes
+    ON thread
+    FOR EACH ROW
+    WHEN (OLD.votes IS DISTINCT FROM NEW.votes)
+EXECUTE PROCEDURE notify_votes();
+
+CREATE TRIGGER thread_outbox
+    AFTER INSERT OR UPDATE OF status
+    ON thread
+    FOR EACH ROW
+EXECUTE PROCEDURE outbox_thread();
+
+CREATE TRIGGER post_outbox
+    AFTER INSERT OR UPDATE OF message, status
+    ON post
+    FOR EACH ROW
+EXECUTE PROCEDURE outbox_post();
+
+CREATE TRIGGER thread_votes_outbox
+    AFTER UPDATE OF votes
+    ON thread
+    FOR EACH ROW
+    WHEN (OLD.votes IS DISTINCT FROM NEW.votes)
+EXECUTE PROCEDURE outbox_votes();
+
+CREATE TRIGGER post_notify_users
+    AFTER INSERT
+    ON post
+    REFERENCING NEW TABLE AS new_posts
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE notify_users();
+
+CREATE TRIGGER thread_status_counters
+    AFTER UPDATE OF status
+    ON thread
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status)
+EXECUTE PROCEDURE update_thread_status();
+
+CREATE TRIGGER post_status_counters
+    AFTER UPDATE OF status
+    ON post
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status)
+EXECUTE PROCEDURE update_post_status();
+
+CREATE TRIGGER post_thread_activity
+    AFTER INSERT
+    ON post
+    REFERENCING NEW TABLE AS new_posts
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE update_thread_activity();
+
+CREATE TRIGGER forum_cache
+    AFTER UPDATE
+    ON forum
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_cache_forum();
+
+CREATE TRIGGER forum_cache_truncate
+    AFTER TRUNCATE
+    ON forum
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE notify_cache_forum();
+
+CREATE TRIGGER thread_cache
+    AFTER UPDATE
+    ON thread
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_cache_thread();
+
+CREATE TRIGGER post_cache
+    AFTER INSERT OR UPDATE
+    ON post
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_cache_post();
+
+CREATE TRIGGER users_cache
+    AFTER UPDATE
+    ON users
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_cache_user();
+
+CREATE TRIGGER user_karma_cache
+    AFTER INSERT OR UPDATE
+    ON user_karma
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_cache_karma();
+
+CREATE TRIGGER user_block_cache
+    AFTER INSERT OR DELETE
+    ON user_block
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_cache_block();
+
+CREATE TRIGGER audit_no_update
+    BEFORE UPDATE OR DELETE
+    ON audit
+    FOR EACH ROW
+EXECUTE PROCEDURE audit_append_only();
+
+CREATE TRIGGER audit_no_truncate
+    BEFORE TRUNCATE
+    ON audit
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE audit_append_only();
+
 CREATE INDEX post_first_parent_thread_index ON post ((post.path[1]), thread);
 CREATE INDEX post_first_parent_id_index ON post ((post.path[1]), id);
 CREATE INDEX post_first_parent_index ON post ((post.path[1]));
 CREATE INDEX post_path_index ON post ((post.path));
 CREATE INDEX post_thread_index ON post (thread);
 CREATE INDEX post_thread_id_index ON post (thread, id);
+CREATE INDEX post_forum_id_index ON post (forum, id);
+CREATE INDEX post_thread_created_index ON post (thread, created);
 
 CREATE INDEX forum_slug_lower_index ON forum ((forum.Slug));
+CREATE INDEX forum_parent_position_index ON forum (parent, position, slug);
 
 CREATE INDEX users_email_index ON users (Email);
 
@@ -191,10 +1072,44 @@ CREATE INDEX thread_slug_index ON thread (slug);
 CREATE INDEX thread_forum_lower_index ON thread (forum);
 CREATE INDEX thread_id_forum_index ON thread (id, forum);
 CREATE INDEX thread_created_index ON thread (created);
+CREATE INDEX thread_forum_votes_index ON thread (forum, votes);
+CREATE INDEX thread_forum_views_index ON thread (forum, views);
+CREATE INDEX thread_tags_index ON thread USING GIN (tags);
+CREATE INDEX thread_forum_activity_index ON thread (forum, (COALESCE(lastPostAt, created)));
 
 CREATE INDEX vote_nickname ON vote (nickname, idThread, voice);
+CREATE INDEX vote_thread_voted_index ON vote (idThread, voted);
 
 CREATE INDEX post_path_id_index ON post (id, (post.path));
 CREATE INDEX post_thread_path_id_index ON post (thread, (post.parent), id);
 
-CREATE INDEX users_forum_forum_index ON users_forum ((users_forum.Slug));
\ No newline at end of file
+CREATE INDEX users_forum_forum_index ON users_forum ((users_forum.Slug));
+
+CREATE INDEX moderator_forum_index ON moderator (forum);
+CREATE INDEX report_forum_status_index ON report (forum, status, id);
+CREATE INDEX audit_actor_index ON audit (actor, id);
+CREATE INDEX audit_action_index ON audit (action, id);
+CREATE INDEX audit_target_index ON audit (targetType, targetId, id);
+CREATE INDEX audit_forum_index ON audit (forum, id);
+CREATE INDEX webhook_forum_index ON webhook (forum);
+CREATE INDEX outbox_pending_index ON outbox (nextAttempt) WHERE status = 'pending';
+CREATE INDEX outbox_webhook_status_index ON outbox (webhook, status, id);
+CREATE INDEX poll_option_poll_index ON poll_option (poll, position);
+CREATE INDEX attachment_post_index ON attachment (post);
+CREATE INDEX attachment_orphan_index ON attachment (id) WHERE post IS NULL;
+CREATE INDEX conversation_member_nickname_index ON conversation_member (nickname, conversation);
+CREATE INDEX direct_message_conversation_index ON direct_message (conversation, id);
+CREATE INDEX user_block_blocked_index ON user_block (blocked);
+CREATE INDEX post_author_created_index ON post (author, created);
+CREATE INDEX post_forum_pending_index ON post (forum, id) WHERE status = 'pending';
+CREATE INDEX thread_author_created_index ON thread (author, created);
+CREATE INDEX thread_forum_pending_index ON thread (forum, id) WHERE status = 'pending';
+CREATE INDEX thread_scheduled_index ON thread (publishAt) WHERE status = 'scheduled';
+CREATE INDEX thread_author_drafts_index ON thread (author, id) WHERE status IN ('draft', 'scheduled');
+CREATE INDEX rate_bucket_updated_index ON rate_bucket (updated);
+CREATE INDEX notification_nickname_index ON notification (nickname, id);
+CREATE INDEX notification_unread_index ON notification (nickname) WHERE NOT isRead;
+CREATE INDEX post_vote_nickname_index ON post_vote (nickname);
+CREATE INDEX post_reaction_nickname_index ON post_reaction (nickname);
+CREATE INDEX users_karma_index ON users (Karma DESC, Nickname);
+CREATE INDEX user_karma_forum_index ON user_karma (forum, karma DESC, nickname);
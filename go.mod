@@ -3,6 +3,7 @@ module DbGODZ
 go 1.14
 
 require (
+	github.com/andybalholm/brotli v1.0.0
 	github.com/cockroachdb/apd v1.1.0 // indirect
 	github.com/fasthttp/router v1.0.4
 	github.com/go-openapi/strfmt v0.19.5
@@ -16,5 +17,7 @@ require (
 	github.com/rs/zerolog v1.18.0
 	github.com/shopspring/decimal v1.2.0 // indirect
 	github.com/valyala/fasthttp v1.12.0
+	github.com/yuin/goldmark v1.2.1
+	golang.org/x/net v0.0.0-20200324143707-d3edc9973b7e
 	google.golang.org/appengine v1.6.6 // indirect
 )
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const Channel = "cache_invalidate"
+
+func (r *Repository) Run() {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := r.listen()
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		log.Error().Msgf("cache: %s, reconnecting in %s", err.Error(), backoff)
+		time.Sleep(backoff)
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (r *Repository) listen() error {
+	conn, err := r.pool.Acquire()
+	if err != nil {
+		return err
+	}
+	defer r.pool.Release(conn)
+
+	if err = conn.Listen(Channel); err != nil {
+		return err
+	}
+	r.lru.Purge()
+
+	for {
+		n, err := conn.WaitForNotification(context.Background())
+		if err != nil {
+			return err
+		}
+		if n.Payload == "all" {
+			r.lru.Purge()
+			continue
+		}
+		r.lru.Invalidate(n.Payload)
+	}
+}
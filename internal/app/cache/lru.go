@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type Stats struct {
+	Entries       int    `json:"entries"`
+	Evictions     uint64 `json:"evictions"`
+	Hits          uint64 `json:"hits"`
+	Invalidations uint64 `json:"invalidations"`
+	Misses        uint64 `json:"misses"`
+}
+
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+	tags    []string
+}
+
+type LRU struct {
+	mu          sync.Mutex
+	capacity    int
+	ttl         time.Duration
+	generation  uint64
+	floor       uint64
+	invalidated map[string]uint64
+	order       *list.List
+	items       map[string]*list.Element
+	tags        map[string]map[string]struct{}
+	stats       Stats
+}
+
+func NewLRU(capacity int, ttl time.Duration) *LRU {
+	return &LRU{
+		capacity:    capacity,
+		ttl:         ttl,
+		invalidated: make(map[string]uint64),
+		order:       list.New(),
+		items:       make(map[string]*list.Element),
+		tags:        make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *LRU) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	e := element.Value.(*entry)
+	if time.Now().After(e.expires) {
+		c.remove(element)
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	c.stats.Hits++
+	return e.value, true
+}
+
+func (c *LRU) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+func (c *LRU) Set(key string, value interface{}, generation uint64, tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation < c.floor {
+		return
+	}
+	for _, tag := range tags {
+		if c.invalidated[tag] > generation {
+			return
+		}
+	}
+	if element, ok := c.items[key]; ok {
+		c.remove(element)
+	}
+
+	e := &entry{key: key, value: value, expires: time.Now().Add(c.ttl), tags: tags}
+	c.items[key] = c.order.PushFront(e)
+	for _, tag := range tags {
+		keys, ok := c.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *LRU) Invalidate(tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if len(c.invalidated)+len(tags) > c.capacity {
+		c.invalidated = make(map[string]uint64)
+		c.floor = c.generation
+	}
+	for _, tag := range tags {
+		c.invalidated[tag] = c.generation
+		for key := range c.tags[tag] {
+			if element, ok := c.items[key]; ok {
+				c.remove(element)
+				c.stats.Invalidations++
+			}
+		}
+		delete(c.tags, tag)
+	}
+}
+
+func (c *LRU) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.floor = c.generation
+	c.invalidated = make(map[string]uint64)
+	c.stats.Invalidations += uint64(c.order.Len())
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+	c.tags = make(map[string]map[string]struct{})
+}
+
+func (c *LRU) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	return stats
+}
+
+func (c *LRU) remove(element *list.Element) {
+	e := c.order.Remove(element).(*entry)
+	delete(c.items, e.key)
+	for _, tag := range e.tags {
+		if keys, ok := c.tags[tag]; ok {
+			delete(keys, e.key)
+			if len(keys) == 0 {
+				delete(c.tags, tag)
+			}
+		}
+	}
+}
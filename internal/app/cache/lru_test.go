@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(2, time.Minute)
+	c.Set("a", 1, c.Generation())
+	c.Set("b", 2, c.Generation())
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a is missing")
+	}
+	c.Set("c", 3, c.Generation())
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("least recently used entry b was kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Fatalf("%s was evicted", key)
+		}
+	}
+	if stats := c.Stats(); stats.Entries != 2 || stats.Evictions != 1 || stats.Hits != 3 || stats.Misses != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestLRUExpires(t *testing.T) {
+	c := NewLRU(10, time.Millisecond)
+	c.Set("a", 1, c.Generation())
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expired entry was returned")
+	}
+	if stats := c.Stats(); stats.Entries != 0 {
+		t.Fatalf("expired entry was kept: %+v", stats)
+	}
+}
+
+func TestLRUInvalidateByTag(t *testing.T) {
+	c := NewLRU(10, time.Minute)
+	c.Set("thread", 1, c.Generation(), "thread:1")
+	c.Set("posts", 2, c.Generation(), "thread:1", "post:5")
+	c.Set("other", 3, c.Generation(), "thread:2")
+
+	c.Invalidate("post:5")
+	if _, ok := c.Get("posts"); ok {
+		t.Fatal("entry tagged post:5 survived")
+	}
+	if _, ok := c.Get("thread"); !ok {
+		t.Fatal("entry without the invalidated tag was removed")
+	}
+
+	c.Invalidate("thread:1")
+	if _, ok := c.Get("thread"); ok {
+		t.Fatal("entry tagged thread:1 survived")
+	}
+	if _, ok := c.Get("other"); !ok {
+		t.Fatal("entry tagged thread:2 was removed")
+	}
+}
+
+func TestLRUGenerationsArePerTag(t *testing.T) {
+	c := NewLRU(10, time.Minute)
+
+	generation := c.Generation()
+	c.Invalidate("thread:2")
+	c.Set("thread", 1, generation, "thread:1")
+	if _, ok := c.Get("thread"); !ok {
+		t.Fatal("unrelated invalidation dropped a fresh read")
+	}
+
+	generation = c.Generation()
+	c.Invalidate("thread:1")
+	c.Set("thread", 2, generation, "thread:1")
+	if value, ok := c.Get("thread"); ok {
+		t.Fatalf("read that raced an invalidation of its tag was cached: %v", value)
+	}
+
+	c.Set("thread", 3, c.Generation(), "thread:1")
+	if value, ok := c.Get("thread"); !ok || value != 3 {
+		t.Fatalf("read after the invalidation = %v, %v", value, ok)
+	}
+}
+
+func TestLRUPurgeRejectsInFlightReads(t *testing.T) {
+	c := NewLRU(10, time.Minute)
+	c.Set("a", 1, c.Generation(), "tag")
+
+	generation := c.Generation()
+	c.Purge()
+	c.Set("b", 2, generation, "other")
+
+	if stats := c.Stats(); stats.Entries != 0 || stats.Invalidations != 1 {
+		t.Fatalf("stats after purge = %+v", stats)
+	}
+	c.Set("b", 2, c.Generation(), "other")
+	if _, ok := c.Get("b"); !ok {
+		t.Fatal("read after the purge was dropped")
+	}
+}
+
+func TestLRUBoundsInvalidatedTags(t *testing.T) {
+	c := NewLRU(4, time.Minute)
+	generation := c.Generation()
+	for i := 0; i < 20; i++ {
+		c.Invalidate(fmt.Sprintf("post:%d", i))
+	}
+
+	if len(c.invalidated) > 4 {
+		t.Fatalf("tracked %d invalidated tags, want at most 4", len(c.invalidated))
+	}
+	c.Set("a", 1, generation, "post:0")
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("read older than the forgotten invalidations was cached")
+	}
+}
@@ -0,0 +1,365 @@
+package cache
+
+import (
+	forum "DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"fmt"
+	"github.com/jackc/pgx"
+	"strconv"
+	"strings"
+)
+
+const forumsTag = "forums"
+
+type Repository struct {
+	forum.Repository
+	pool    *pgx.ConnPool
+	lru     *LRU
+	pending *pendingInvalidation
+}
+
+type pendingInvalidation struct {
+	purge bool
+	tags  []string
+}
+
+func NewRepository(fr forum.Repository, pool *pgx.ConnPool, lru *LRU) *Repository {
+	return &Repository{Repository: fr, pool: pool, lru: lru}
+}
+
+func (r *Repository) CacheStats() Stats {
+	return r.lru.Stats()
+}
+
+func (r *Repository) invalidate(tags ...string) {
+	if r.pending != nil {
+		r.pending.tags = append(r.pending.tags, tags...)
+		return
+	}
+	r.lru.Invalidate(tags...)
+}
+
+func (r *Repository) purge() {
+	if r.pending != nil {
+		r.pending.purge = true
+		return
+	}
+	r.lru.Purge()
+}
+
+func (r *Repository) WithAuditForum(audit *models.Audit,
+	change func(repo forum.Repository) (interface{}, error)) error {
+	pending := r.pending
+	if pending == nil {
+		pending = &pendingInvalidation{}
+	}
+
+	err := r.Repository.WithAuditForum(audit, func(repo forum.Repository) (interface{}, error) {
+		return change(&Repository{Repository: repo, pool: r.pool, lru: r.lru, pending: pending})
+	})
+	if r.pending == nil {
+		if pending.purge {
+			r.lru.Purge()
+		} else if len(pending.tags) > 0 {
+			r.lru.Invalidate(pending.tags...)
+		}
+	}
+	return err
+}
+
+func forumTag(slug string) string {
+	return "forum:" + strings.ToLower(slug)
+}
+
+func threadTag(id int32) string {
+	return "thread:" + strconv.Itoa(int(id))
+}
+
+func postTag(id int64) string {
+	return "post:" + strconv.FormatInt(id, 10)
+}
+
+func userTag(nickname string) string {
+	return "user:" + strings.ToLower(nickname)
+}
+
+func viewerTag(nickname string) string {
+	return "viewer:" + strings.ToLower(nickname)
+}
+
+func leaderboardTag(slug string) string {
+	return "leaderboard:" + strings.ToLower(slug)
+}
+
+func (r *Repository) GetBySlugForum(slug string) (models.Forum, error) {
+	if r.pending != nil {
+		return r.Repository.GetBySlugForum(slug)
+	}
+
+	key := forumTag(slug)
+	if value, ok := r.lru.Get(key); ok {
+		return value.(models.Forum), nil
+	}
+
+	generation := r.lru.Generation()
+	forumObj, err := r.Repository.GetBySlugForum(slug)
+	if err == nil {
+		r.lru.Set(key, forumObj, generation, forumTag(forumObj.Slug), forumsTag)
+	}
+	return forumObj, err
+}
+
+func (r *Repository) GetByNick(nickname string) (models.User, error) {
+	if r.pending != nil {
+		return r.Repository.GetByNick(nickname)
+	}
+
+	key := userTag(nickname)
+	if value, ok := r.lru.Get(key); ok {
+		return value.(models.User), nil
+	}
+
+	generation := r.lru.Generation()
+	userObj, err := r.Repository.GetByNick(nickname)
+	if err == nil {
+		r.lru.Set(key, userObj, generation, userTag(userObj.Nickname))
+	}
+	return userObj, err
+}
+
+func (r *Repository) GetLeaderboardForum(slug string, limit int) ([]models.KarmaRank, error) {
+	if r.pending != nil {
+		return r.Repository.GetLeaderboardForum(slug, limit)
+	}
+
+	key := leaderboardTag(slug) + ":" + strconv.Itoa(limit)
+	if value, ok := r.lru.Get(key); ok {
+		return value.([]models.KarmaRank), nil
+	}
+
+	generation := r.lru.Generation()
+	leaders, err := r.Repository.GetLeaderboardForum(slug, limit)
+	if err == nil {
+		r.lru.Set(key, leaders, generation, leaderboardTag(slug))
+	}
+	return leaders, err
+}
+
+func (r *Repository) GetThreadByIDForum(id int) (models.Thread, error) {
+	if r.pending != nil {
+		return r.Repository.GetThreadByIDForum(id)
+	}
+
+	key := threadTag(int32(id))
+	if value, ok := r.lru.Get(key); ok {
+		return value.(models.Thread), nil
+	}
+
+	generation := r.lru.Generation()
+	threadObj, err := r.Repository.GetThreadByIDForum(id)
+	if err == nil {
+		r.lru.Set(key, threadObj, generation, threadTag(threadObj.Id))
+	}
+	return threadObj, err
+}
+
+func (r *Repository) GetThreadBySlugForum(slug string) (models.Thread, error) {
+	if r.pending != nil {
+		return r.Repository.GetThreadBySlugForum(slug)
+	}
+
+	key := "thread-slug:" + strings.ToLower(slug)
+	if value, ok := r.lru.Get(key); ok {
+		return value.(models.Thread), nil
+	}
+
+	generation := r.lru.Generation()
+	threadObj, err := r.Repository.GetThreadBySlugForum(slug)
+	if err == nil {
+		r.lru.Set(key, threadObj, generation, threadTag(threadObj.Id))
+	}
+	return threadObj, err
+}
+
+func (r *Repository) GetThreadIDBySlugForum(slug string) (int, error) {
+	if r.pending != nil {
+		return r.Repository.GetThreadIDBySlugForum(slug)
+	}
+
+	key := "thread-id:" + strings.ToLower(slug)
+	if value, ok := r.lru.Get(key); ok {
+		return value.(int), nil
+	}
+
+	generation := r.lru.Generation()
+	id, err := r.Repository.GetThreadIDBySlugForum(slug)
+	if err == nil {
+		r.lru.Set(key, id, generation, threadTag(int32(id)))
+	}
+	return id, err
+}
+
+func (r *Repository) GetPostsForum(postSlugOrId models.Thread, limit, since int, sort string, desc bool,
+	viewer string) ([]models.Post, error) {
+	if r.pending != nil {
+		return r.Repository.GetPostsForum(postSlugOrId, limit, since, sort, desc, viewer)
+	}
+
+	if postSlugOrId.Id <= 0 {
+		id, err := r.GetThreadIDBySlugForum(postSlugOrId.Slug.String)
+		if err != nil {
+			return nil, err
+		}
+		postSlugOrId.Id = int32(id)
+	}
+
+	key := fmt.Sprintf("posts:%d:%d:%d:%s:%t:%s", postSlugOrId.Id, limit, since, sort, desc,
+		strings.ToLower(viewer))
+	if value, ok := r.lru.Get(key); ok {
+		return append([]models.Post(nil), value.([]models.Post)...), nil
+	}
+
+	generation := r.lru.Generation()
+	posts, err := r.Repository.GetPostsForum(postSlugOrId, limit, since, sort, desc, viewer)
+	if err != nil {
+		return posts, err
+	}
+
+	tags := []string{threadTag(postSlugOrId.Id)}
+	if viewer != "" {
+		tags = append(tags, viewerTag(viewer))
+	}
+	for _, post := range posts {
+		tags = append(tags, postTag(post.Id))
+	}
+	r.lru.Set(key, append([]models.Post(nil), posts...), generation, tags...)
+	return posts, nil
+}
+
+func (r *Repository) Update(user models.User) (models.User, error) {
+	userObj, err := r.Repository.Update(user)
+	r.invalidate(userTag(user.Nickname))
+	return userObj, err
+}
+
+func (r *Repository) AddThreadForum(thread models.Thread) (models.Thread, error) {
+	threadObj, err := r.Repository.AddThreadForum(thread)
+	r.invalidate(forumsTag)
+	return threadObj, err
+}
+
+func (r *Repository) UpdateThreadForum(newThread models.Thread) (models.Thread, error) {
+	threadObj, err := r.Repository.UpdateThreadForum(newThread)
+	if err == nil {
+		r.invalidate(threadTag(threadObj.Id))
+	}
+	return threadObj, err
+}
+
+func (r *Repository) AddPostsForum(posts []models.Post, threadID int) ([]models.Post, error) {
+	data, err := r.Repository.AddPostsForum(posts, threadID)
+	r.invalidate(threadTag(int32(threadID)), forumsTag)
+	return data, err
+}
+
+func (r *Repository) UpdatePostForum(newPost models.Post) (models.Post, error) {
+	post, err := r.Repository.UpdatePostForum(newPost)
+	r.invalidate(postTag(newPost.Id), threadTag(post.Thread))
+	return post, err
+}
+
+func (r *Repository) VoteForum(vote models.Vote, thread models.Thread) (models.Thread, error) {
+	threadObj, err := r.Repository.VoteForum(vote, thread)
+	if err == nil {
+		r.invalidate(threadTag(threadObj.Id), userTag(threadObj.Author),
+			leaderboardTag(threadObj.Forum), leaderboardTag(""))
+	}
+	return threadObj, err
+}
+
+func (r *Repository) VotePostForum(vote models.PostVote) error {
+	err := r.Repository.VotePostForum(vote)
+	tags := []string{postTag(vote.IdPost), leaderboardTag("")}
+	if related, lookupErr := r.Repository.GetPostForum(int(vote.IdPost), []string{}); lookupErr == nil {
+		post := related["post"].(models.Post)
+		tags = append(tags, userTag(post.Author), leaderboardTag(post.Forum))
+	}
+	r.invalidate(tags...)
+	return err
+}
+
+func (r *Repository) AddReactionForum(reaction models.Reaction) error {
+	err := r.Repository.AddReactionForum(reaction)
+	r.invalidate(postTag(reaction.IdPost))
+	return err
+}
+
+func (r *Repository) DeleteReactionForum(reaction models.Reaction) error {
+	err := r.Repository.DeleteReactionForum(reaction)
+	r.invalidate(postTag(reaction.IdPost))
+	return err
+}
+
+func (r *Repository) AddAttachmentForum(attachment models.Attachment, limit int) (models.Attachment, error) {
+	attachmentObj, err := r.Repository.AddAttachmentForum(attachment, limit)
+	r.invalidate(postTag(attachment.Post))
+	return attachmentObj, err
+}
+
+func (r *Repository) AddBlockForum(nickname, blocked string) error {
+	err := r.Repository.AddBlockForum(nickname, blocked)
+	r.invalidate(viewerTag(nickname))
+	return err
+}
+
+func (r *Repository) DeleteBlockForum(nickname, blocked string) error {
+	err := r.Repository.DeleteBlockForum(nickname, blocked)
+	r.invalidate(viewerTag(nickname))
+	return err
+}
+
+func (r *Repository) SetSlowModeForum(slug string, seconds int32) (models.Forum, error) {
+	forumObj, err := r.Repository.SetSlowModeForum(slug, seconds)
+	r.invalidate(forumTag(slug))
+	return forumObj, err
+}
+
+func (r *Repository) SetPremoderationForum(slug string, enabled bool) (models.Forum, error) {
+	forumObj, err := r.Repository.SetPremoderationForum(slug, enabled)
+	r.invalidate(forumTag(slug))
+	return forumObj, err
+}
+
+func (r *Repository) SetPostStatusForum(id int64, status string) (models.Post, error) {
+	post, err := r.Repository.SetPostStatusForum(id, status)
+	r.invalidate(postTag(id), threadTag(post.Thread), forumsTag)
+	return post, err
+}
+
+func (r *Repository) SetThreadStatusForum(id int, status string) (models.Thread, error) {
+	threadObj, err := r.Repository.SetThreadStatusForum(id, status)
+	r.invalidate(threadTag(int32(id)), forumsTag)
+	return threadObj, err
+}
+
+func (r *Repository) PublishDraftForum(id int, status, publishAt string) (models.Thread, error) {
+	threadObj, err := r.Repository.PublishDraftForum(id, status, publishAt)
+	r.invalidate(threadTag(int32(id)), forumsTag)
+	return threadObj, err
+}
+
+func (r *Repository) PublishDueThreadsForum(limit int) ([]models.Thread, error) {
+	threads, err := r.Repository.PublishDueThreadsForum(limit)
+	tags := []string{forumsTag}
+	for _, threadObj := range threads {
+		tags = append(tags, threadTag(threadObj.Id))
+	}
+	r.invalidate(tags...)
+	return threads, err
+}
+
+func (r *Repository) ClearDatabaseForum() error {
+	err := r.Repository.ClearDatabaseForum()
+	r.purge()
+	return err
+}
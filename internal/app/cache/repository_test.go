@@ -0,0 +1,232 @@
+package cache
+
+import (
+	forum "DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type countingRepository struct {
+	forum.Repository
+	reads   map[string]int
+	threads map[int]models.Thread
+	posts   map[int]models.Post
+	users   map[string]models.User
+	karma   map[string]int64
+}
+
+func newCountingRepository() *countingRepository {
+	return &countingRepository{
+		reads:   make(map[string]int),
+		threads: map[int]models.Thread{1: {Id: 1, Author: "alice", Forum: "forum", Title: "before"}},
+		posts:   map[int]models.Post{1: {Id: 1, Author: "alice", Forum: "Forum", Thread: 1}},
+		users:   map[string]models.User{"alice": {Nickname: "alice"}},
+		karma:   make(map[string]int64),
+	}
+}
+
+func (r *countingRepository) GetByNick(nickname string) (models.User, error) {
+	r.reads[userTag(nickname)]++
+	return r.users[strings.ToLower(nickname)], nil
+}
+
+func (r *countingRepository) GetThreadByIDForum(id int) (models.Thread, error) {
+	r.reads[threadTag(int32(id))]++
+	return r.threads[id], nil
+}
+
+func (r *countingRepository) UpdateThreadForum(newThread models.Thread) (models.Thread, error) {
+	r.threads[int(newThread.Id)] = newThread
+	return newThread, nil
+}
+
+func (r *countingRepository) VoteForum(vote models.Vote, thread models.Thread) (models.Thread, error) {
+	threadObj := r.threads[int(thread.Id)]
+	threadObj.Votes += vote.Voice
+	r.threads[int(thread.Id)] = threadObj
+	userObj := r.users[strings.ToLower(threadObj.Author)]
+	userObj.Karma += int64(vote.Voice)
+	r.users[strings.ToLower(threadObj.Author)] = userObj
+	return threadObj, nil
+}
+
+func (r *countingRepository) GetPostForum(id int, related []string) (map[string]interface{}, error) {
+	return map[string]interface{}{"post": r.posts[id]}, nil
+}
+
+func (r *countingRepository) VotePostForum(vote models.PostVote) error {
+	post := r.posts[int(vote.IdPost)]
+	userObj := r.users[strings.ToLower(post.Author)]
+	userObj.Karma += int64(vote.Voice)
+	r.users[strings.ToLower(post.Author)] = userObj
+	r.karma[strings.ToLower(post.Forum)] += int64(vote.Voice)
+	return nil
+}
+
+func (r *countingRepository) GetLeaderboardForum(slug string, limit int) ([]models.KarmaRank, error) {
+	r.reads[leaderboardTag(slug)]++
+	karma := r.users["alice"].Karma
+	if slug != "" {
+		karma = r.karma[strings.ToLower(slug)]
+	}
+	return []models.KarmaRank{{Nickname: "alice", Karma: karma, Rank: 1}}, nil
+}
+
+func (r *countingRepository) WithAuditForum(audit *models.Audit,
+	change func(repo forum.Repository) (interface{}, error)) error {
+	_, err := change(r)
+	return err
+}
+
+func TestRepositoryVoteForumInvalidatesAuthorKarma(t *testing.T) {
+	base := newCountingRepository()
+	repo := NewRepository(base, nil, NewLRU(100, time.Minute))
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.GetByNick("Alice"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if base.reads["user:alice"] != 1 {
+		t.Fatalf("user reads = %d, want 1", base.reads["user:alice"])
+	}
+
+	if _, err := repo.VoteForum(models.Vote{Nickname: "bob", Voice: 1}, models.Thread{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	userObj, err := repo.GetByNick("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base.reads["user:alice"] != 2 || userObj.Karma != 1 {
+		t.Fatalf("user after vote = %+v after %d reads, want fresh karma", userObj, base.reads["user:alice"])
+	}
+}
+
+func TestRepositoryVotePostForumInvalidatesAuthorKarma(t *testing.T) {
+	base := newCountingRepository()
+	repo := NewRepository(base, nil, NewLRU(100, time.Minute))
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.GetByNick("alice"); err != nil {
+			t.Fatal(err)
+		}
+		for _, slug := range []string{"forum", ""} {
+			if _, err := repo.GetLeaderboardForum(slug, 10); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, tag := range []string{"user:alice", "leaderboard:forum", "leaderboard:"} {
+		if base.reads[tag] != 1 {
+			t.Fatalf("%s reads = %d, want 1", tag, base.reads[tag])
+		}
+	}
+
+	if err := repo.VotePostForum(models.PostVote{Nickname: "bob", Voice: 1, IdPost: 1}); err != nil {
+		t.Fatal(err)
+	}
+	userObj, err := repo.GetByNick("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userObj.Karma != 1 {
+		t.Fatalf("user after post vote = %+v, want fresh karma", userObj)
+	}
+	for _, slug := range []string{"forum", ""} {
+		leaders, err := repo.GetLeaderboardForum(slug, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if leaders[0].Karma != 1 {
+			t.Fatalf("leaderboard %q after post vote = %+v, want fresh karma", slug, leaders)
+		}
+	}
+}
+
+func TestRepositoryVoteForumInvalidatesLeaderboards(t *testing.T) {
+	base := newCountingRepository()
+	repo := NewRepository(base, nil, NewLRU(100, time.Minute))
+	if _, err := repo.GetLeaderboardForum("", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.VoteForum(models.Vote{Nickname: "bob", Voice: 1}, models.Thread{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	leaders, err := repo.GetLeaderboardForum("", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base.reads["leaderboard:"] != 2 || leaders[0].Karma != 1 {
+		t.Fatalf("leaderboard after vote = %+v after %d reads, want it reloaded", leaders, base.reads["leaderboard:"])
+	}
+}
+
+func TestRepositoryWithAuditForumDefersInvalidation(t *testing.T) {
+	base := newCountingRepository()
+	lru := NewLRU(100, time.Minute)
+	repo := NewRepository(base, nil, lru)
+	if _, err := repo.GetThreadByIDForum(1); err != nil {
+		t.Fatal(err)
+	}
+
+	err := repo.WithAuditForum(&models.Audit{}, func(tx forum.Repository) (interface{}, error) {
+		threadObj, err := tx.GetThreadByIDForum(1)
+		if err != nil {
+			return nil, err
+		}
+		threadObj.Title = "after"
+		if _, err = tx.UpdateThreadForum(threadObj); err != nil {
+			return nil, err
+		}
+
+		if _, ok := lru.Get(threadTag(1)); !ok {
+			t.Fatal("cache was invalidated before the audited change committed")
+		}
+		if threadObj, err = tx.GetThreadByIDForum(1); err != nil || threadObj.Title != "after" {
+			t.Fatalf("read inside the change = %+v, %v, want the uncommitted title", threadObj, err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base.reads["thread:1"] != 3 {
+		t.Fatalf("thread reads = %d, want reads inside the change to bypass the cache", base.reads["thread:1"])
+	}
+
+	threadObj, err := repo.GetThreadByIDForum(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if threadObj.Title != "after" {
+		t.Fatalf("thread after the change = %+v, want it reloaded", threadObj)
+	}
+}
+
+func TestRepositoryWithAuditForumInvalidatesOnError(t *testing.T) {
+	base := newCountingRepository()
+	lru := NewLRU(100, time.Minute)
+	repo := NewRepository(base, nil, lru)
+	if _, err := repo.GetThreadByIDForum(1); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("audit failed")
+	err := repo.WithAuditForum(&models.Audit{}, func(tx forum.Repository) (interface{}, error) {
+		if _, err := tx.UpdateThreadForum(models.Thread{Id: 1, Author: "alice", Title: "after"}); err != nil {
+			return nil, err
+		}
+		return nil, failure
+	})
+	if err != failure {
+		t.Fatalf("err = %v, want %v", err, failure)
+	}
+	if _, ok := lru.Get(threadTag(1)); ok {
+		t.Fatal("entry touched by a failed change was kept")
+	}
+}
@@ -0,0 +1,226 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/app/storage"
+	"DbGODZ/internal/pkg/res"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/jackc/pgx"
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxAttachmentSize     = 10 << 20
+	maxAttachmentsPerPost = 10
+	maxFilenameLength     = 255
+)
+
+var attachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"application/zip": true,
+	"image/gif":       true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"text/plain":      true,
+}
+
+func cleanFilenameForum(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.Replace(name, "\\", "/", -1)))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		name = "file"
+	}
+	name = strings.ToValidUTF8(name, "")
+	if utf8.RuneCountInString(name) > maxFilenameLength {
+		name = string([]rune(name)[:maxFilenameLength])
+	}
+	return name
+}
+
+func newStorageKeyForum(postID int64) (string, error) {
+	buf := make([]byte, 16)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("posts/%d/%s", postID, hex.EncodeToString(buf)), nil
+}
+
+func (f *handler) AddAttachmentForum(ctx *fasthttp.RequestCtx) {
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := extractPostIDForum(ctx)
+	if !ok {
+		return
+	}
+
+	postObj, err := f.forumRepo.GetPostForum(int(id), []string{})
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find post with id: %d", id),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	post := postObj["post"].(models.Post)
+	if !strings.EqualFold(post.Author, userObj.Nickname) {
+		res.SendResponse(403, res.HttpError{Message: "only the author can attach files to a post"}, ctx)
+		return
+	}
+	if len(post.Attachments) >= maxAttachmentsPerPost {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("post can have at most %d attachments", maxAttachmentsPerPost),
+		}
+		res.SendResponse(400, errHTTP, ctx)
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		res.SendResponse(400, res.HttpError{Message: "multipart field \"file\" is required"}, ctx)
+		return
+	}
+	if fileHeader.Size > maxAttachmentSize {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("attachment is larger than %d bytes", maxAttachmentSize),
+		}
+		res.SendResponse(413, errHTTP, ctx)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(file, maxAttachmentSize+1))
+	file.Close()
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	if len(data) > maxAttachmentSize {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("attachment is larger than %d bytes", maxAttachmentSize),
+		}
+		res.SendResponse(413, errHTTP, ctx)
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !attachmentTypes[mediaType] {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("attachments of type %s are not allowed", contentType),
+		}
+		res.SendResponse(415, errHTTP, ctx)
+		return
+	}
+
+	key, err := newStorageKeyForum(id)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	err = f.storage.Put(key, contentType, data)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	attachment, err := f.forumRepo.AddAttachmentForum(models.Attachment{
+		Author:      userObj.Nickname,
+		ContentType: contentType,
+		Filename:    cleanFilenameForum(fileHeader.Filename),
+		Post:        id,
+		Size:        int64(len(data)),
+		StorageKey:  key,
+	}, maxAttachmentsPerPost)
+	if err != nil {
+		if deleteErr := f.storage.Delete(key); deleteErr != nil {
+			log.Error().Msgf("attachments: %s", deleteErr.Error())
+		}
+		if err == pgx.ErrNoRows {
+			if _, err = f.forumRepo.GetPostForum(int(id), []string{}); err == nil {
+				errHTTP := res.HttpError{
+					Message: fmt.Sprintf("post can have at most %d attachments", maxAttachmentsPerPost),
+				}
+				res.SendResponse(400, errHTTP, ctx)
+				return
+			}
+			errHTTP := res.HttpError{
+				Message: fmt.Sprintf("Can't find post with id: %d", id),
+			}
+			res.SendResponse(404, errHTTP, ctx)
+			return
+		}
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponse(201, attachment, ctx)
+}
+
+func (f *handler) GetAttachmentForum(ctx *fasthttp.RequestCtx) {
+	ValueStr, found := ctx.UserValue("id").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	id, err := strconv.ParseInt(ValueStr, 10, 64)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	attachment, err := f.forumRepo.GetAttachmentForum(id)
+	if err == nil {
+		var data []byte
+		data, err = f.storage.Get(attachment.StorageKey)
+		if err == nil {
+			disposition := "attachment"
+			if strings.HasPrefix(attachment.ContentType, "image/") {
+				disposition = "inline"
+			}
+
+			ctx.SetContentType(attachment.ContentType)
+			ctx.Response.Header.Set("X-Content-Type-Options", "nosniff")
+			ctx.Response.Header.Set("Content-Disposition",
+				mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}))
+			ctx.SetStatusCode(fasthttp.StatusOK)
+			ctx.SetBody(data)
+			return
+		}
+	}
+
+	if err == pgx.ErrNoRows || err == storage.ErrNotFound {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find attachment with id: %d", id),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	res.SendServerError(err.Error(), ctx)
+}
@@ -0,0 +1,127 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/app/storage"
+	"bytes"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestCleanFilenameForum(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"report.pdf", "report.pdf"},
+		{"../../etc/passwd", "passwd"},
+		{`C:\Users\me\photo.png`, "photo.png"},
+		{"evil\".png", "evil.png"},
+		{"line\r\nbreak.txt", "linebreak.txt"},
+		{"  ", "file"},
+		{"/", "file"},
+		{"bad\xffname", "bad\uFFFDname"},
+		{strings.Repeat("ж", 300), strings.Repeat("ж", maxFilenameLength)},
+	}
+
+	for _, tt := range tests {
+		if got := cleanFilenameForum(tt.name); got != tt.want {
+			t.Errorf("cleanFilenameForum(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+type attachmentStorageForum struct {
+	storage.Storage
+	objects map[string][]byte
+}
+
+func (s *attachmentStorageForum) Put(key, contentType string, data []byte) error {
+	s.objects[key] = data
+	return nil
+}
+
+func (s *attachmentStorageForum) Delete(key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+type attachmentRepositoryForum struct {
+	usersRepositoryForum
+	post     models.Post
+	addedErr error
+}
+
+func (r *attachmentRepositoryForum) GetPostForum(id int, related []string) (map[string]interface{}, error) {
+	if int64(id) != r.post.Id {
+		return nil, pgx.ErrNoRows
+	}
+	return map[string]interface{}{"post": r.post}, nil
+}
+
+func (r *attachmentRepositoryForum) AddAttachmentForum(attachment models.Attachment,
+	limit int) (models.Attachment, error) {
+	if r.addedErr != nil {
+		return models.Attachment{}, r.addedErr
+	}
+	attachment.Id = 1
+	return attachment, nil
+}
+
+func newAttachmentCtxForum(t *testing.T, actor, filename string, data []byte) *fasthttp.RequestCtx {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	writer.Close()
+
+	ctx := newTestCtxForum("POST", actor, body.String(), map[string]string{"id": "5"})
+	ctx.Request.Header.SetContentType(writer.FormDataContentType())
+	return ctx
+}
+
+func TestAddAttachmentForum(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+
+	tests := []struct {
+		name       string
+		actor      string
+		data       []byte
+		addedErr   error
+		attached   int
+		wantStatus int
+	}{
+		{"image", "alice", png, nil, 0, 201},
+		{"not the author", "bob", png, nil, 0, 403},
+		{"unsupported type", "alice", []byte("<html><script></script></html>"), nil, 0, 415},
+		{"listed limit reached", "alice", png, nil, maxAttachmentsPerPost, 400},
+		{"limit reached concurrently", "alice", png, pgx.ErrNoRows, 0, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &attachmentRepositoryForum{usersRepositoryForum: newUsersRepositoryForum("alice", "bob"),
+				post:     models.Post{Author: "alice", Id: 5, Attachments: make([]models.Attachment, tt.attached)},
+				addedErr: tt.addedErr}
+			objects := &attachmentStorageForum{objects: map[string][]byte{}}
+			f := &handler{forumRepo: repo, storage: objects}
+			ctx := newAttachmentCtxForum(t, tt.actor, "a.png", tt.data)
+
+			f.AddAttachmentForum(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", ctx.Response.StatusCode(), tt.wantStatus, ctx.Response.Body())
+			}
+			if stored := len(objects.objects); (stored == 1) != (tt.wantStatus == 201) {
+				t.Fatalf("storage holds %d objects after status %d", stored, tt.wantStatus)
+			}
+		})
+	}
+}
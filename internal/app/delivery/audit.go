@@ -0,0 +1,110 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/res"
+	"encoding/json"
+	"github.com/valyala/fasthttp"
+	"strings"
+)
+
+func extractRequestIDForum(ctx *fasthttp.RequestCtx) string {
+	if requestID, ok := ctx.UserValue("request_id").(string); ok {
+		return requestID
+	}
+	return string(ctx.Request.Header.Peek("X-Request-Id"))
+}
+
+func newAuditForum(ctx *fasthttp.RequestCtx, action, targetType, targetID, forumSlug string,
+	before interface{}) (models.Audit, error) {
+	auditObj := models.Audit{
+		Action:     action,
+		RequestId:  extractRequestIDForum(ctx),
+		TargetId:   targetID,
+		TargetType: targetType,
+	}
+	auditObj.Actor.String = extractActorForum(ctx)
+	auditObj.Forum.String = forumSlug
+
+	var err error
+	if before != nil {
+		auditObj.Before, err = json.Marshal(before)
+	}
+	return auditObj, err
+}
+
+func (f *handler) auditedForum(ctx *fasthttp.RequestCtx, action, targetType, targetID, forumSlug string,
+	before interface{}, change func(repo forum.Repository) (interface{}, error)) error {
+	auditObj, err := newAuditForum(ctx, action, targetType, targetID, forumSlug, before)
+	if err != nil {
+		return err
+	}
+	return f.forumRepo.WithAuditForum(&auditObj, change)
+}
+
+func isAuthorForum(ctx *fasthttp.RequestCtx, author string) bool {
+	return strings.EqualFold(extractActorForum(ctx), author)
+}
+
+func (f *handler) isAdminForum(nickname string) bool {
+	return nickname != "" && f.admins[strings.ToLower(nickname)]
+}
+
+func (f *handler) GetAuditForum(ctx *fasthttp.RequestCtx) {
+	actor := extractActorForum(ctx)
+	if actor == "" {
+		res.SendResponse(401, res.HttpError{Message: "X-Nickname header is required"}, ctx)
+		return
+	}
+	forumSlug := string(ctx.QueryArgs().Peek("forum"))
+	if !f.isAdminForum(actor) {
+		if forumSlug == "" {
+			errHTTP := res.HttpError{Message: "only administrators can read the audit log of all forums"}
+			res.SendResponse(403, errHTTP, ctx)
+			return
+		}
+		if !f.checkModeratorForum(ctx, forumSlug) {
+			return
+		}
+	}
+
+	limit, err := extractIntValueForum(ctx, "limit")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	since, err := extractIntValueForum(ctx, "since")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	desc, err := extractBoolValueForum(ctx, "desc")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	filter := models.AuditFilter{
+		Action:     string(ctx.QueryArgs().Peek("action")),
+		Actor:      string(ctx.QueryArgs().Peek("actor")),
+		Forum:      forumSlug,
+		From:       string(ctx.QueryArgs().Peek("from")),
+		TargetId:   string(ctx.QueryArgs().Peek("targetId")),
+		TargetType: string(ctx.QueryArgs().Peek("targetType")),
+		To:         string(ctx.QueryArgs().Peek("to")),
+		Limit:      limit,
+		Since:      int64(since),
+		Desc:       desc,
+	}
+
+	records, err := f.forumRepo.GetAuditForum(filter)
+	if err != nil {
+		res.SendResponse(400, res.HttpError{Message: err.Error()}, ctx)
+		return
+	}
+
+	res.SendResponseOK(records, ctx)
+}
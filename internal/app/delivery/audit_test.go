@@ -0,0 +1,147 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type auditRepositoryForum struct {
+	usersRepositoryForum
+	moderators map[string]bool
+	filters    []models.AuditFilter
+	audits     []models.Audit
+	auditErr   error
+	thread     models.Thread
+	updates    int
+}
+
+func (r *auditRepositoryForum) IsModeratorForum(slug, nickname string) (bool, error) {
+	return r.moderators[slug+"/"+strings.ToLower(nickname)], nil
+}
+
+func (r *auditRepositoryForum) GetAuditForum(filter models.AuditFilter) ([]models.Audit, error) {
+	r.filters = append(r.filters, filter)
+	return []models.Audit{}, nil
+}
+
+func (r *auditRepositoryForum) WithAuditForum(audit *models.Audit,
+	change func(repo forum.Repository) (interface{}, error)) error {
+	if _, err := change(r); err != nil {
+		return err
+	}
+	if r.auditErr != nil {
+		return r.auditErr
+	}
+	r.audits = append(r.audits, *audit)
+	return nil
+}
+
+func (r *auditRepositoryForum) GetThreadByIDForum(id int) (models.Thread, error) {
+	return r.thread, nil
+}
+
+func (r *auditRepositoryForum) UpdateThreadForum(newThread models.Thread) (models.Thread, error) {
+	r.updates++
+	if newThread.Message != "" {
+		r.thread.Message = newThread.Message
+	}
+	return r.thread, nil
+}
+
+func TestGetAuditForumRequiresModeratorOrAdmin(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      string
+		query      string
+		wantStatus int
+	}{
+		{"missing header", "", "", 401},
+		{"user without forum filter", "alice", "", 403},
+		{"moderator of another forum", "alice", "forum=other", 403},
+		{"moderator of the forum", "alice", "forum=news", 200},
+		{"admin without forum filter", "root", "", 200},
+		{"admin with forum filter", "ROOT", "forum=other", 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &auditRepositoryForum{moderators: map[string]bool{"news/alice": true}}
+			f := &handler{forumRepo: repo, admins: map[string]bool{"root": true}}
+			ctx := newTestCtxForum("GET", tt.actor, "", nil)
+			ctx.Request.URI().SetQueryString(tt.query)
+
+			f.GetAuditForum(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", ctx.Response.StatusCode(), tt.wantStatus)
+			}
+			if tt.wantStatus != 200 && len(repo.filters) != 0 {
+				t.Fatalf("audit log was read: %v", repo.filters)
+			}
+		})
+	}
+}
+
+func TestUpdateThreadForumAuditsNonAuthorEdits(t *testing.T) {
+	tests := []struct {
+		name          string
+		actor         string
+		body          string
+		wantAudits    int
+		wantAnonymous bool
+	}{
+		{"author edit", "alice", `{"message":"new"}`, 0, false},
+		{"moderator edit", "bob", `{"message":"new"}`, 1, false},
+		{"edit without header", "", `{"message":"new"}`, 1, true},
+		{"unchanged edit", "bob", `{"message":"old"}`, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &auditRepositoryForum{thread: models.Thread{Author: "alice", Forum: "news", Id: 3,
+				Message: "old", Title: "title"}}
+			f := &handler{forumRepo: repo}
+			ctx := newTestCtxForum("POST", tt.actor, tt.body, map[string]string{"slug_or_id": "3"})
+			ctx.SetUserValue("request_id", "req")
+
+			f.UpdateThreadBySlugOrIDForum(ctx)
+
+			if ctx.Response.StatusCode() != 200 {
+				t.Fatalf("status = %d, want 200", ctx.Response.StatusCode())
+			}
+			if repo.updates != 1 {
+				t.Fatalf("thread was updated %d times, want once", repo.updates)
+			}
+			if len(repo.audits) != tt.wantAudits {
+				t.Fatalf("audits = %v, want %d", repo.audits, tt.wantAudits)
+			}
+			if tt.wantAudits == 0 {
+				return
+			}
+			auditObj := repo.audits[0]
+			if auditObj.Action != "thread.update" || auditObj.RequestId != "req" || auditObj.TargetId != "3" ||
+				len(auditObj.Before) == 0 {
+				t.Errorf("audit = %+v", auditObj)
+			}
+			if (auditObj.Actor.String == "") != tt.wantAnonymous {
+				t.Errorf("audit actor = %q, want anonymous %t", auditObj.Actor.String, tt.wantAnonymous)
+			}
+		})
+	}
+}
+
+func TestUpdateThreadForumFailsWhenAuditFails(t *testing.T) {
+	repo := &auditRepositoryForum{thread: models.Thread{Author: "alice", Forum: "news", Id: 3, Message: "old"},
+		auditErr: errors.New("audit insert failed")}
+	f := &handler{forumRepo: repo}
+	ctx := newTestCtxForum("POST", "bob", `{"message":"new"}`, map[string]string{"slug_or_id": "3"})
+
+	f.UpdateThreadBySlugOrIDForum(ctx)
+
+	if ctx.Response.StatusCode() == 200 {
+		t.Fatal("update succeeded although the audit record could not be written")
+	}
+}
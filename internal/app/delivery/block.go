@@ -0,0 +1,122 @@
+package delivery
+
+import (
+	"DbGODZ/internal/pkg/res"
+	"fmt"
+	"github.com/valyala/fasthttp"
+	"strings"
+)
+
+func (f *handler) blockUserForum(ctx *fasthttp.RequestCtx, block bool) {
+	nickname, found := ctx.UserValue("nickname").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	actorObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	userObj, err := f.forumRepo.GetByNick(nickname)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find user by nickname: %s", nickname),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if strings.EqualFold(actorObj.Nickname, userObj.Nickname) {
+		res.SendResponse(400, res.HttpError{Message: "users can't block themselves"}, ctx)
+		return
+	}
+
+	if block {
+		err = f.forumRepo.AddBlockForum(actorObj.Nickname, userObj.Nickname)
+	} else {
+		err = f.forumRepo.DeleteBlockForum(actorObj.Nickname, userObj.Nickname)
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	blocks, err := f.forumRepo.GetBlocksForum(actorObj.Nickname)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	res.SendResponseOK(blocks, ctx)
+}
+
+func (f *handler) BlockUserForum(ctx *fasthttp.RequestCtx) {
+	f.blockUserForum(ctx, true)
+}
+
+func (f *handler) UnblockUserForum(ctx *fasthttp.RequestCtx) {
+	f.blockUserForum(ctx, false)
+}
+
+func (f *handler) GetBlocksForum(ctx *fasthttp.RequestCtx) {
+	nickname, found := ctx.UserValue("nickname").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	actorObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(actorObj.Nickname, nickname) {
+		res.SendResponse(403, res.HttpError{Message: "users can only list their own blocks"}, ctx)
+		return
+	}
+
+	blocks, err := f.forumRepo.GetBlocksForum(actorObj.Nickname)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	res.SendResponseOK(blocks, ctx)
+}
+
+func (f *handler) muteThreadForum(ctx *fasthttp.RequestCtx, mute bool) {
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	threadObj, ok := f.getVisibleThreadForum(ctx)
+	if !ok {
+		return
+	}
+	id := int(threadObj.Id)
+
+	var err error
+	if mute {
+		err = f.forumRepo.AddThreadMuteForum(userObj.Nickname, id)
+	} else {
+		err = f.forumRepo.DeleteThreadMuteForum(userObj.Nickname, id)
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	thread, err := f.forumRepo.GetThreadByIDForum(id)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	res.SendResponseOK(thread, ctx)
+}
+
+func (f *handler) MuteThreadForum(ctx *fasthttp.RequestCtx) {
+	f.muteThreadForum(ctx, true)
+}
+
+func (f *handler) UnmuteThreadForum(ctx *fasthttp.RequestCtx) {
+	f.muteThreadForum(ctx, false)
+}
@@ -0,0 +1,88 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+type blockRepositoryForum struct {
+	usersRepositoryForum
+	blocks map[string][]string
+}
+
+func (r *blockRepositoryForum) AddBlockForum(nickname, blocked string) error {
+	r.blocks[nickname] = append(r.blocks[nickname], blocked)
+	return nil
+}
+
+func (r *blockRepositoryForum) DeleteBlockForum(nickname, blocked string) error {
+	kept := r.blocks[nickname][:0]
+	for _, existing := range r.blocks[nickname] {
+		if existing != blocked {
+			kept = append(kept, existing)
+		}
+	}
+	r.blocks[nickname] = kept
+	return nil
+}
+
+func (r *blockRepositoryForum) GetBlocksForum(nickname string) ([]models.Block, error) {
+	data := make([]models.Block, 0)
+	for _, blocked := range r.blocks[nickname] {
+		data = append(data, models.Block{Blocked: blocked, Nickname: nickname})
+	}
+	return data, nil
+}
+
+func TestBlockUserForum(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      string
+		target     string
+		wantStatus int
+		wantBlocks int
+	}{
+		{"block", "alice", "bob", 200, 1},
+		{"self", "alice", "ALICE", 400, 0},
+		{"unknown target", "alice", "nobody", 404, 0},
+		{"missing header", "", "bob", 401, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &blockRepositoryForum{usersRepositoryForum: newUsersRepositoryForum("alice", "bob"),
+				blocks: map[string][]string{}}
+			f := &handler{forumRepo: repo}
+			ctx := newTestCtxForum("POST", tt.actor, "", map[string]string{"nickname": tt.target})
+
+			f.BlockUserForum(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", ctx.Response.StatusCode(), tt.wantStatus)
+			}
+			if len(repo.blocks["alice"]) != tt.wantBlocks {
+				t.Fatalf("alice blocks = %v, want %d", repo.blocks["alice"], tt.wantBlocks)
+			}
+		})
+	}
+}
+
+func TestUnblockAndGetBlocksForum(t *testing.T) {
+	repo := &blockRepositoryForum{usersRepositoryForum: newUsersRepositoryForum("alice", "bob"),
+		blocks: map[string][]string{"alice": {"bob"}}}
+	f := &handler{forumRepo: repo}
+
+	ctx := newTestCtxForum("GET", "bob", "", map[string]string{"nickname": "alice"})
+	f.GetBlocksForum(ctx)
+	if ctx.Response.StatusCode() != 403 {
+		t.Fatalf("listing another user's blocks status = %d, want 403", ctx.Response.StatusCode())
+	}
+
+	ctx = newTestCtxForum("POST", "alice", "", map[string]string{"nickname": "bob"})
+	f.UnblockUserForum(ctx)
+	var blocks []models.Block
+	decodeResponseForum(t, ctx, &blocks)
+	if ctx.Response.StatusCode() != 200 || len(blocks) != 0 {
+		t.Fatalf("unblock = %d with %v, want 200 and no blocks", ctx.Response.StatusCode(), blocks)
+	}
+}
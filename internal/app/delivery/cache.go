@@ -0,0 +1,20 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/cache"
+	"DbGODZ/internal/pkg/res"
+	"github.com/valyala/fasthttp"
+)
+
+type cacheStatsProvider interface {
+	CacheStats() cache.Stats
+}
+
+func (f *handler) GetCacheStatsForum(ctx *fasthttp.RequestCtx) {
+	provider, ok := f.forumRepo.(cacheStatsProvider)
+	if !ok {
+		res.SendResponse(404, res.HttpError{Message: "cache is disabled"}, ctx)
+		return
+	}
+	res.SendResponseOK(provider.CacheStats(), ctx)
+}
@@ -0,0 +1,392 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/filter"
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/res"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"strconv"
+	"strings"
+)
+
+type premoderationUpdate struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (f *handler) filterForum(ctx *fasthttp.RequestCtx, contents []filter.Content) ([]filter.Verdict, bool) {
+	verdicts, err := f.filters.Run(contents)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return nil, false
+	}
+
+	for i, verdict := range verdicts {
+		if verdict.Action != filter.Reject {
+			continue
+		}
+		if len(contents) > 1 {
+			verdict.Reason = fmt.Sprintf("%s %d: %s", contents[i].Kind, i+1, verdict.Reason)
+		}
+		res.SendResponse(422, verdict, ctx)
+		return nil, false
+	}
+	return verdicts, true
+}
+
+func heldStatusForum(verdict filter.Verdict) (string, string) {
+	if verdict.Action == filter.Hold {
+		return "pending", verdict.Code
+	}
+	return "", ""
+}
+
+func (f *handler) premoderatedAuthorsForum(forumSlug string, authors []string) (map[string]bool, error) {
+	held := make(map[string]bool)
+
+	forumObj, err := f.forumRepo.GetBySlugForum(forumSlug)
+	if err == pgx.ErrNoRows {
+		return held, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !forumObj.Premoderated {
+		return held, nil
+	}
+
+	for _, author := range authors {
+		key := strings.ToLower(author)
+		if _, ok := held[key]; ok {
+			continue
+		}
+		isModerator, err := f.forumRepo.IsModeratorForum(forumObj.Slug, author)
+		if err != nil {
+			return nil, err
+		}
+		held[key] = !isModerator
+	}
+	return held, nil
+}
+
+func (f *handler) canViewForum(ctx *fasthttp.RequestCtx, author, forumSlug, status string) (bool, error) {
+	if status == "" || status == "published" {
+		return true, nil
+	}
+
+	actor := extractActorForum(ctx)
+	if actor == "" {
+		return false, nil
+	}
+	if status != "rejected" && strings.EqualFold(actor, author) {
+		return true, nil
+	}
+	return f.forumRepo.IsModeratorForum(forumSlug, actor)
+}
+
+func (f *handler) getVisibleThreadForum(ctx *fasthttp.RequestCtx) (models.Thread, bool) {
+	slugOrID, found := ctx.UserValue("slug_or_id").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return models.Thread{}, false
+	}
+	return f.lookupVisibleThreadForum(ctx, slugOrID)
+}
+
+func (f *handler) lookupVisibleThreadForum(ctx *fasthttp.RequestCtx, slugOrID string) (models.Thread, bool) {
+	errHTTP := res.HttpError{
+		Message: fmt.Sprintf("Can't find thread by slug or id: %s", slugOrID),
+	}
+
+	id, err := f.getThreadIDForum(slugOrID)
+	if err != nil {
+		res.SendResponse(404, errHTTP, ctx)
+		return models.Thread{}, false
+	}
+	threadObj, err := f.forumRepo.GetThreadByIDForum(id)
+	if err != nil {
+		res.SendResponse(404, errHTTP, ctx)
+		return models.Thread{}, false
+	}
+
+	visible, err := f.canViewForum(ctx, threadObj.Author, threadObj.Forum, threadObj.Status)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return models.Thread{}, false
+	}
+	if !visible {
+		res.SendResponse(404, errHTTP, ctx)
+		return models.Thread{}, false
+	}
+	return threadObj, true
+}
+
+func (f *handler) SetPremoderationForum(ctx *fasthttp.RequestCtx) {
+	slug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	forumObj, err := f.forumRepo.GetBySlugForum(slug)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find forum with slug: %s", slug),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if !checkOwnerForum(ctx, forumObj) {
+		return
+	}
+
+	var update premoderationUpdate
+	err = json.Unmarshal(ctx.PostBody(), &update)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	var forumDB models.Forum
+	err = f.auditedForum(ctx, "forum.premoderation", "forum", forumObj.Slug, forumObj.Slug,
+		premoderationUpdate{Enabled: forumObj.Premoderated}, func(repo forum.Repository) (interface{}, error) {
+			var err error
+			forumDB, err = repo.SetPremoderationForum(forumObj.Slug, update.Enabled)
+			return premoderationUpdate{Enabled: forumDB.Premoderated}, err
+		})
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(forumDB, ctx)
+}
+
+func (f *handler) getModeratedForumForum(ctx *fasthttp.RequestCtx) (models.Forum, bool) {
+	slug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return models.Forum{}, false
+	}
+
+	forumObj, err := f.forumRepo.GetBySlugForum(slug)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find forum with slug: %s", slug),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return models.Forum{}, false
+	}
+	if !f.checkModeratorForum(ctx, forumObj.Slug) {
+		return models.Forum{}, false
+	}
+	return forumObj, true
+}
+
+func (f *handler) GetBlocklistForum(ctx *fasthttp.RequestCtx) {
+	forumObj, ok := f.getModeratedForumForum(ctx)
+	if !ok {
+		return
+	}
+
+	words, err := f.forumRepo.GetBlockedWordsForum(forumObj.Slug)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	res.SendResponseOK(words, ctx)
+}
+
+func (f *handler) AddBlockedWordForum(ctx *fasthttp.RequestCtx) {
+	forumObj, ok := f.getModeratedForumForum(ctx)
+	if !ok {
+		return
+	}
+
+	var newWord models.BlockedWord
+	err := json.Unmarshal(ctx.PostBody(), &newWord)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	newWord.Forum = forumObj.Slug
+	newWord.Word = filter.NormalizeText(newWord.Word)
+	if newWord.Action == "" {
+		newWord.Action = "reject"
+	}
+	if newWord.Word == "" || (newWord.Action != "reject" && newWord.Action != "hold") {
+		res.SendResponse(400, res.HttpError{Message: "word is required and action must be reject or hold"}, ctx)
+		return
+	}
+
+	var wordDB models.BlockedWord
+	err = f.auditedForum(ctx, "blocklist.add", "forum", forumObj.Slug, forumObj.Slug, nil,
+		func(repo forum.Repository) (interface{}, error) {
+			var err error
+			wordDB, err = repo.AddBlockedWordForum(newWord)
+			return wordDB, err
+		})
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponse(201, wordDB, ctx)
+}
+
+func (f *handler) DeleteBlockedWordForum(ctx *fasthttp.RequestCtx) {
+	forumObj, ok := f.getModeratedForumForum(ctx)
+	if !ok {
+		return
+	}
+
+	var word models.BlockedWord
+	err := json.Unmarshal(ctx.PostBody(), &word)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	word.Forum = forumObj.Slug
+	word.Word = filter.NormalizeText(word.Word)
+
+	err = f.auditedForum(ctx, "blocklist.remove", "forum", forumObj.Slug, forumObj.Slug, word,
+		func(repo forum.Repository) (interface{}, error) {
+			return nil, repo.DeleteBlockedWordForum(word.Forum, word.Word)
+		})
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(word, ctx)
+}
+
+func (f *handler) GetModerationQueueForum(ctx *fasthttp.RequestCtx) {
+	forumObj, ok := f.getModeratedForumForum(ctx)
+	if !ok {
+		return
+	}
+
+	limit, err := extractIntValueForum(ctx, "limit")
+	if err != nil {
+		res.SendResponse(400, res.HttpError{Message: "limit must be an integer"}, ctx)
+		return
+	}
+
+	queue, err := f.forumRepo.GetModerationQueueForum(forumObj.Slug, limit)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	res.SendResponseOK(queue, ctx)
+}
+
+func (f *handler) setPostStatusForum(ctx *fasthttp.RequestCtx, status string) {
+	id, err := strconv.ParseInt(ctx.UserValue("id").(string), 10, 64)
+	if err != nil {
+		res.SendResponse(400, res.HttpError{Message: "bad post id"}, ctx)
+		return
+	}
+
+	postMap, err := f.forumRepo.GetPostForum(int(id), []string{})
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find post with id: %d", id),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	before := postMap["post"].(models.Post)
+	if !f.checkModeratorForum(ctx, before.Forum) {
+		return
+	}
+
+	var post models.Post
+	err = f.auditedForum(ctx, "post."+status, "post", strconv.FormatInt(id, 10), before.Forum, before,
+		func(repo forum.Repository) (interface{}, error) {
+			var err error
+			post, err = repo.SetPostStatusForum(id, status)
+			return post, err
+		})
+	if err == pgx.ErrNoRows {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Post %d is not pending approval", id),
+		}
+		res.SendResponse(409, errHTTP, ctx)
+		return
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(post, ctx)
+}
+
+func (f *handler) ApprovePostForum(ctx *fasthttp.RequestCtx) {
+	f.setPostStatusForum(ctx, "published")
+}
+
+func (f *handler) RejectPostForum(ctx *fasthttp.RequestCtx) {
+	f.setPostStatusForum(ctx, "rejected")
+}
+
+func (f *handler) setThreadStatusForum(ctx *fasthttp.RequestCtx, status string) {
+	slugOrID, found := ctx.UserValue("slug_or_id").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	id, err := f.getThreadIDForum(slugOrID)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find thread by slug or id: %s", slugOrID),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	before, err := f.forumRepo.GetThreadByIDForum(id)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find thread by slug or id: %s", slugOrID),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if !f.checkModeratorForum(ctx, before.Forum) {
+		return
+	}
+
+	var thread models.Thread
+	err = f.auditedForum(ctx, "thread."+status, "thread", strconv.Itoa(id), before.Forum, before,
+		func(repo forum.Repository) (interface{}, error) {
+			var err error
+			thread, err = repo.SetThreadStatusForum(id, status)
+			return thread, err
+		})
+	if err == pgx.ErrNoRows {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Thread %s is not pending approval", slugOrID),
+		}
+		res.SendResponse(409, errHTTP, ctx)
+		return
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(thread, ctx)
+}
+
+func (f *handler) ApproveThreadForum(ctx *fasthttp.RequestCtx) {
+	f.setThreadStatusForum(ctx, "published")
+}
+
+func (f *handler) RejectThreadForum(ctx *fasthttp.RequestCtx) {
+	f.setThreadStatusForum(ctx, "rejected")
+}
@@ -2,7 +2,13 @@ package delivery
 
 import (
 	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/filter"
 	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/app/ratelimit"
+	"DbGODZ/internal/app/storage"
+	"DbGODZ/internal/app/stream"
+	"DbGODZ/internal/app/views"
+	"DbGODZ/internal/pkg/middleware"
 	"DbGODZ/internal/pkg/res"
 	"database/sql"
 	"encoding/json"
@@ -15,10 +21,23 @@ import (
 
 type handler struct {
 	forumRepo forum.Repository
+	streamHub *stream.Hub
+	views     *views.Counter
+	storage   storage.Storage
+	limiter   *ratelimit.Limiter
+	filters   *filter.Pipeline
+	admins    map[string]bool
+	limits    Limits
 }
 
-func NewHandler(fr forum.Repository) *handler {
-	return &handler{forumRepo: fr}
+func NewHandler(fr forum.Repository, hub *stream.Hub, counter *views.Counter, store storage.Storage,
+	limiter *ratelimit.Limiter, filters *filter.Pipeline, admins []string, limits Limits) *handler {
+	adminSet := make(map[string]bool, len(admins))
+	for _, admin := range admins {
+		adminSet[strings.ToLower(strings.TrimSpace(admin))] = true
+	}
+	return &handler{forumRepo: fr, streamHub: hub, views: counter, storage: store, limiter: limiter,
+		filters: filters, admins: adminSet, limits: limits}
 }
 
 func (f *handler) AddForum(ctx *fasthttp.RequestCtx) {
@@ -29,6 +48,11 @@ func (f *handler) AddForum(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	if newForum.Position < 0 {
+		res.SendResponse(400, res.HttpError{Message: "position must not be negative"}, ctx)
+		return
+	}
+
 	newForumDB, err := f.forumRepo.AddForum(newForum)
 	if pgerr, ok := err.(pgx.PgError); ok {
 		switch pgerr.Code {
@@ -46,6 +70,15 @@ func (f *handler) AddForum(ctx *fasthttp.RequestCtx) {
 			}
 			res.SendResponse(404, err, ctx)
 			return
+		case "00404":
+			err := res.HttpError{
+				Message: fmt.Sprintf("Can't find parent forum with slug: %s", newForum.Parent),
+			}
+			res.SendResponse(404, err, ctx)
+			return
+		case "00400":
+			res.SendResponse(400, res.HttpError{Message: pgerr.Message}, ctx)
+			return
 		}
 
 	}
@@ -83,6 +116,19 @@ func (f *handler) GetForum(ctx *fasthttp.RequestCtx) {
 	case nil:
 	default:
 		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	forumObj.Children, err = f.forumRepo.GetForumChildrenForum(forumObj.Slug)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	forumObj.Breadcrumbs, err = f.forumRepo.GetForumBreadcrumbsForum(forumObj.Slug)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
 	}
 
 	res.SendResponseOK(forumObj, ctx)
@@ -98,12 +144,62 @@ func (f *handler) AddThreadForum(ctx *fasthttp.RequestCtx) {
 
 	newThread := models.Thread{Forum: forumSlug}
 
+	if !f.checkBodyLimitsForum(ctx) {
+		return
+	}
+
 	err := json.Unmarshal(ctx.PostBody(), &newThread)
 	if err != nil {
 		res.SendServerError(err.Error(), ctx)
 		return
 	}
 
+	var message string
+	if newThread.Tags, message = normalizeTagsForum(newThread.Tags); message != "" {
+		res.SendResponse(400, res.HttpError{Message: message}, ctx)
+		return
+	}
+
+	if newThread.Poll != nil {
+		if message := validatePollForum(newThread.Poll); message != "" {
+			res.SendResponse(400, res.HttpError{Message: message}, ctx)
+			return
+		}
+	}
+
+	if newThread.Slug.String != "" {
+		threadOld, err := f.forumRepo.GetThreadBySlugForum(newThread.Slug.String)
+		if err == nil {
+			f.sendThreadConflictForum(ctx, threadOld)
+			return
+		}
+	}
+
+	verdicts, ok := f.filterForum(ctx, []filter.Content{{
+		Author:  newThread.Author,
+		Forum:   newThread.Forum,
+		Kind:    "thread",
+		Message: newThread.Message,
+		Title:   newThread.Title,
+	}})
+	if !ok {
+		return
+	}
+	newThread.Status, newThread.StatusReason = heldStatusForum(verdicts[0])
+
+	held, err := f.premoderatedAuthorsForum(newThread.Forum, []string{newThread.Author})
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	if newThread.Status == "" && held[strings.ToLower(newThread.Author)] {
+		newThread.Status, newThread.StatusReason = "pending", "premoderation"
+	}
+	if message := scheduleThreadForum(&newThread); message != "" {
+		res.SendResponse(400, res.HttpError{Message: message}, ctx)
+		return
+	}
+
 	newThreadDB, err := f.forumRepo.AddThreadForum(newThread)
 	if pgerr, ok := err.(pgx.PgError); ok && pgerr.Code == "23505" {
 		threadOld, err := f.forumRepo.GetThreadBySlugForum(newThread.Slug.String)
@@ -111,7 +207,7 @@ func (f *handler) AddThreadForum(ctx *fasthttp.RequestCtx) {
 			res.SendServerError(err.Error(), ctx)
 			return
 		}
-		res.SendResponse(409, threadOld, ctx)
+		f.sendThreadConflictForum(ctx, threadOld)
 		return
 	}
 
@@ -124,6 +220,22 @@ func (f *handler) AddThreadForum(ctx *fasthttp.RequestCtx) {
 	res.SendResponse(201, newThreadDB, ctx)
 }
 
+func (f *handler) sendThreadConflictForum(ctx *fasthttp.RequestCtx, threadOld models.Thread) {
+	visible, err := f.canViewForum(ctx, threadOld.Author, threadOld.Forum, threadOld.Status)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	if !visible {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Thread with slug %s already exists", threadOld.Slug.String),
+		}
+		res.SendResponse(409, errHTTP, ctx)
+		return
+	}
+	res.SendResponse(409, threadOld, ctx)
+}
+
 func extractBoolValueForum(ctx *fasthttp.RequestCtx, valueName string) (bool, error) {
 	ValueStr := string(ctx.QueryArgs().Peek(valueName))
 	var value bool
@@ -157,6 +269,15 @@ func extractIntValueForum(ctx *fasthttp.RequestCtx, valueName string) (int, erro
 	return value, nil
 }
 
+var topWindows = map[string]string{
+	"":      "",
+	"all":   "",
+	"day":   "1 day",
+	"week":  "7 days",
+	"month": "1 month",
+	"year":  "1 year",
+}
+
 func (f *handler) GetThreadsForum(ctx *fasthttp.RequestCtx) {
 	forumSlug, found := ctx.UserValue("slug").(string)
 	if !found {
@@ -170,6 +291,12 @@ func (f *handler) GetThreadsForum(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	offset, err := extractIntValueForum(ctx, "offset")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
 	since := string(ctx.QueryArgs().Peek("since"))
 
 	desc, err := extractBoolValueForum(ctx, "desc")
@@ -177,7 +304,37 @@ func (f *handler) GetThreadsForum(ctx *fasthttp.RequestCtx) {
 		res.SendServerError(err.Error(), ctx)
 		return
 	}
-	threads, err := f.forumRepo.GetThreadsForum(forumSlug, limit, since, desc)
+
+	filter := models.ThreadFilter{
+		Desc:     desc,
+		Forum:    forumSlug,
+		Limit:    limit,
+		Nickname: extractActorForum(ctx),
+		Offset:   offset,
+		Since:    since,
+		Sort:     string(ctx.QueryArgs().Peek("sort")),
+		Tag:      strings.ToLower(string(ctx.QueryArgs().Peek("tag"))),
+	}
+
+	switch filter.Sort {
+	case "", "created", "hot", "active", "views":
+	case "top":
+		window, ok := topWindows[string(ctx.QueryArgs().Peek("window"))]
+		if !ok {
+			res.SendResponse(400, res.HttpError{Message: "window must be one of day, week, month, year, all"}, ctx)
+			return
+		}
+		filter.Window = window
+	default:
+		res.SendResponse(400, res.HttpError{Message: "sort must be one of created, hot, top, active, views"}, ctx)
+		return
+	}
+	if filter.Since != "" && filter.Sort != "" && filter.Sort != "created" {
+		res.SendResponse(400, res.HttpError{Message: "since is only supported with sort=created, use offset instead"}, ctx)
+		return
+	}
+
+	threads, err := f.forumRepo.GetThreadsForum(filter)
 	if err == pgx.ErrNoRows || len(threads) == 0 {
 		exists, err := f.forumRepo.CheckThreadExistsForum(forumSlug)
 		if err != nil {
@@ -204,7 +361,11 @@ func (f *handler) GetThreadsForum(ctx *fasthttp.RequestCtx) {
 	return
 }
 
-func (f *handler) createPostForum(ctx *fasthttp.RequestCtx, id int) {
+func (f *handler) createPostForum(ctx *fasthttp.RequestCtx, threadObj models.Thread) {
+	if !f.checkBodyLimitsForum(ctx) {
+		return
+	}
+
 	var newPosts []models.Post
 	err := json.Unmarshal(ctx.PostBody(), &newPosts)
 	if err != nil {
@@ -215,6 +376,42 @@ func (f *handler) createPostForum(ctx *fasthttp.RequestCtx, id int) {
 		res.SendResponse(201, newPosts, ctx)
 		return
 	}
+	if threadObj.Status != "" && threadObj.Status != "published" {
+		res.SendResponse(403, res.HttpError{Message: "thread is not published yet"}, ctx)
+		return
+	}
+	id := int(threadObj.Id)
+	if !f.limitPostsForum(ctx, id, newPosts) {
+		return
+	}
+
+	contents := make([]filter.Content, 0, len(newPosts))
+	authors := make([]string, 0, len(newPosts))
+	for _, post := range newPosts {
+		authors = append(authors, post.Author)
+		contents = append(contents, filter.Content{
+			Author:  post.Author,
+			Forum:   threadObj.Forum,
+			Kind:    "post",
+			Message: post.Message,
+			Thread:  threadObj.Id,
+		})
+	}
+	verdicts, ok := f.filterForum(ctx, contents)
+	if !ok {
+		return
+	}
+	held, err := f.premoderatedAuthorsForum(threadObj.Forum, authors)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	for i := range newPosts {
+		newPosts[i].Status, newPosts[i].StatusReason = heldStatusForum(verdicts[i])
+		if newPosts[i].Status == "" && held[strings.ToLower(newPosts[i].Author)] {
+			newPosts[i].Status, newPosts[i].StatusReason = "pending", "premoderation"
+		}
+	}
 	newPostsAuthor := newPosts[0].Author
 	newPosts, err = f.forumRepo.AddPostsForum(newPosts, id)
 	if len(newPosts) == 0 {
@@ -256,41 +453,17 @@ func (f *handler) createPostForum(ctx *fasthttp.RequestCtx, id int) {
 }
 
 func (f *handler) AddPostSlugForum(ctx *fasthttp.RequestCtx) {
-	slugOrId, found := ctx.UserValue("slug_or_id").(string)
-	if !found {
-		res.SendResponse(400, "bad request", ctx)
+	threadObj, ok := f.getVisibleThreadForum(ctx)
+	if !ok {
 		return
 	}
-	var id int
-	id, err := strconv.Atoi(slugOrId)
-	if err == nil {
-		_, err = f.forumRepo.GetThreadByIDForum(id)
-		if err != nil {
-			errHTTP := res.HttpError{
-				Message: fmt.Sprintf(err.Error()),
-			}
-			res.SendResponse(404, errHTTP, ctx)
 
-			return
-		}
-	} else {
-		id, err = f.forumRepo.GetThreadIDBySlugForum(slugOrId)
-		if err != nil {
-			errHTTP := res.HttpError{
-				Message: fmt.Sprintf(err.Error()),
-			}
-			res.SendResponse(404, errHTTP, ctx)
-			return
-		}
-	}
-
-	f.createPostForum(ctx, id)
+	f.createPostForum(ctx, threadObj)
 }
 
-func (f *handler) AddVoteSlugForum(ctx *fasthttp.RequestCtx) {
-	threadSlug, found := ctx.UserValue("slug").(string)
-	if !found {
-		res.SendResponse(400, "bad request", ctx)
+func (f *handler) voteForum(ctx *fasthttp.RequestCtx, slugOrID string) {
+	threadObj, ok := f.lookupVisibleThreadForum(ctx, slugOrID)
+	if !ok {
 		return
 	}
 
@@ -300,29 +473,13 @@ func (f *handler) AddVoteSlugForum(ctx *fasthttp.RequestCtx) {
 		res.SendServerError(err.Error(), ctx)
 		return
 	}
-	threadID, _ := f.forumRepo.GetThreadIDBySlugForum(threadSlug)
-	newVote.IdThread = int64(threadID)
-	err = f.forumRepo.AddVoteForum(newVote)
-	if err != nil {
-		pgerr, ok := err.(pgx.PgError)
-		if !ok {
-			errHTTP := res.HttpError{
-				Message: fmt.Sprintf(err.Error()),
-			}
-			res.SendResponse(404, errHTTP, ctx)
-			return
-		}
-		if pgerr.Code != "23505" {
-			errHTTP := res.HttpError{
-				Message: fmt.Sprintf(err.Error()),
-			}
-			res.SendResponse(404, errHTTP, ctx)
-			return
-		}
 
+	if newVote.Voice < -1 || newVote.Voice > 1 {
+		res.SendResponse(400, res.HttpError{Message: "voice must be -1, 0 or 1"}, ctx)
+		return
 	}
 
-	updatedThread, err := f.forumRepo.GetThreadBySlugForum(threadSlug)
+	updatedThread, err := f.forumRepo.VoteForum(newVote, models.Thread{Id: threadObj.Id})
 	if err != nil {
 		errHTTP := res.HttpError{
 			Message: fmt.Sprintf(err.Error()),
@@ -334,6 +491,16 @@ func (f *handler) AddVoteSlugForum(ctx *fasthttp.RequestCtx) {
 	res.SendResponseOK(updatedThread, ctx)
 }
 
+func (f *handler) AddVoteSlugForum(ctx *fasthttp.RequestCtx) {
+	threadSlug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	f.voteForum(ctx, threadSlug)
+}
+
 func (f *handler) AddVoteIDForum(ctx *fasthttp.RequestCtx) {
 	ValueStr, found := ctx.UserValue("id").(string)
 	if !found {
@@ -341,91 +508,65 @@ func (f *handler) AddVoteIDForum(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	value, err := strconv.Atoi(ValueStr)
-	if err != nil {
-		res.SendServerError(err.Error(), ctx)
+	f.voteForum(ctx, ValueStr)
+}
+
+func (f *handler) GetVotesForum(ctx *fasthttp.RequestCtx) {
+	threadObj, ok := f.getVisibleThreadForum(ctx)
+	if !ok {
 		return
 	}
 
-	var newVote models.Vote
-	err = json.Unmarshal(ctx.PostBody(), &newVote)
+	limit, err := extractIntValueForum(ctx, "limit")
 	if err != nil {
 		res.SendServerError(err.Error(), ctx)
 		return
 	}
-	newVote.IdThread = int64(value)
 
-	err = f.forumRepo.AddVoteForum(newVote)
+	since := string(ctx.QueryArgs().Peek("since"))
+
+	desc, err := extractBoolValueForum(ctx, "desc")
 	if err != nil {
-		pgerr, ok := err.(pgx.PgError)
-		if !ok {
-			errHTTP := res.HttpError{
-				Message: fmt.Sprintf(err.Error()),
-			}
-			res.SendResponse(404, errHTTP, ctx)
-			return
-		}
-		if pgerr.Code != "23505" {
-			errHTTP := res.HttpError{
-				Message: fmt.Sprintf(err.Error()),
-			}
-			res.SendResponse(404, errHTTP, ctx)
-			return
-		} else {
-			err = f.forumRepo.UpdateVoteForum(newVote)
-			if err != nil {
-				errHTTP := res.HttpError{
-					Message: fmt.Sprintf(err.Error()),
-				}
-				res.SendResponse(404, errHTTP, ctx)
-				return
-			}
-		}
+		res.SendServerError(err.Error(), ctx)
+		return
 	}
-	updatedThread, err := f.forumRepo.GetThreadByIDForum(value)
+
+	votes, err := f.forumRepo.GetVotesForum(int(threadObj.Id), limit, since, desc)
 	if err != nil {
-		errHTTP := res.HttpError{
-			Message: fmt.Sprintf(err.Error()),
-		}
-		res.SendResponse(404, errHTTP, ctx)
+		res.SendServerError(err.Error(), ctx)
 		return
 	}
 
-	res.SendResponseOK(updatedThread, ctx)
+	res.SendResponseOK(votes, ctx)
 }
 
 func (f *handler) GetThreadDetailsSlugForum(ctx *fasthttp.RequestCtx) {
-	threadSlug, found := ctx.UserValue("slug_or_id").(string)
-	if !found {
-		res.SendResponse(400, "bad request", ctx)
+	forumObj, ok := f.getVisibleThreadForum(ctx)
+	if !ok {
 		return
 	}
 
-	id, err := strconv.Atoi(threadSlug)
+	etag, err := threadETagForum(forumObj)
 	if err != nil {
-		id, err = f.forumRepo.GetThreadIDBySlugForum(threadSlug)
-		if err != nil {
-			errHTTP := res.HttpError{
-				Message: fmt.Sprintf(err.Error()),
-			}
-			res.SendResponse(404, errHTTP, ctx)
-			return
-		}
-	}
-
-	forumObj, err := f.forumRepo.GetThreadByIDForum(id)
-	if err != nil {
-		errHTTP := res.HttpError{
-			Message: fmt.Sprintf(err.Error()),
-		}
-		res.SendResponse(404, errHTTP, ctx)
+		res.SendServerError(err.Error(), ctx)
 		return
 	}
-
+	f.views.Add(forumObj.Id)
+	forumObj.Views += f.views.Pending(forumObj.Id)
+	ctx.Response.Header.Set("ETag", etag)
 	res.SendResponseOK(forumObj, ctx)
 	return
 }
 
+func threadETagForum(thread models.Thread) (string, error) {
+	thread.Views = 0
+	body, err := json.Marshal(thread)
+	if err != nil {
+		return "", err
+	}
+	return "W/" + middleware.ETag(body), nil
+}
+
 func (f *handler) UpdateThreadBySlugOrIDForum(ctx *fasthttp.RequestCtx) {
 	threadSlugOrID, found := ctx.UserValue("slug_or_id").(string)
 	if !found {
@@ -441,13 +582,41 @@ func (f *handler) UpdateThreadBySlugOrIDForum(ctx *fasthttp.RequestCtx) {
 		}
 	}
 
+	if !f.checkBodyLimitsForum(ctx) {
+		return
+	}
+
 	err := json.Unmarshal(ctx.PostBody(), &newThread)
 	if err != nil {
 		res.SendServerError(err.Error(), ctx)
 		return
 	}
 
-	thread, err := f.forumRepo.UpdateThreadForum(newThread)
+	var message string
+	if newThread.Tags, message = normalizeTagsForum(newThread.Tags); message != "" {
+		res.SendResponse(400, res.HttpError{Message: message}, ctx)
+		return
+	}
+
+	oldThread, ok := f.lookupVisibleThreadForum(ctx, threadSlugOrID)
+	if !ok {
+		return
+	}
+
+	changed := (newThread.Message != "" && newThread.Message != oldThread.Message) ||
+		(newThread.Title != "" && newThread.Title != oldThread.Title) ||
+		(newThread.Tags != nil && strings.Join(newThread.Tags, ",") != strings.Join(oldThread.Tags, ","))
+	var thread models.Thread
+	if changed && !isAuthorForum(ctx, oldThread.Author) {
+		err = f.auditedForum(ctx, "thread.update", "thread", strconv.Itoa(int(oldThread.Id)), oldThread.Forum,
+			oldThread, func(repo forum.Repository) (interface{}, error) {
+				var err error
+				thread, err = repo.UpdateThreadForum(newThread)
+				return thread, err
+			})
+	} else {
+		thread, err = f.forumRepo.UpdateThreadForum(newThread)
+	}
 	if err != nil {
 		res.SendResponse(404, err, ctx)
 		return
@@ -458,12 +627,6 @@ func (f *handler) UpdateThreadBySlugOrIDForum(ctx *fasthttp.RequestCtx) {
 }
 
 func (f *handler) GetPostsSlugForum(ctx *fasthttp.RequestCtx) {
-	threadSlugOrID, found := ctx.UserValue("slug_or_id").(string)
-	if !found {
-		res.SendResponse(400, "bad request", ctx)
-		return
-	}
-
 	limit, err := extractIntValueForum(ctx, "limit")
 	if err != nil {
 		res.SendServerError(err.Error(), ctx)
@@ -486,15 +649,14 @@ func (f *handler) GetPostsSlugForum(ctx *fasthttp.RequestCtx) {
 		res.SendServerError(err.Error(), ctx)
 		return
 	}
-	var slugOrID models.Thread
-	if id, err := strconv.Atoi(threadSlugOrID); err == nil {
-		slugOrID.Id = int32(id)
+
+	threadObj, ok := f.getVisibleThreadForum(ctx)
+	if !ok {
+		return
 	}
-	slug := sql.NullString{String: threadSlugOrID, Valid: true}
-	slugJSON := models.JsonNullString{NullString: slug}
-	slugOrID.Slug = slugJSON
 
-	posts, err := f.forumRepo.GetPostsForum(slugOrID, limit, since, sortType, desc)
+	posts, err := f.forumRepo.GetPostsForum(models.Thread{Id: threadObj.Id}, limit, since, sortType, desc,
+		extractActorForum(ctx))
 	if err != nil {
 		errHTTP := res.HttpError{
 			Message: fmt.Sprintf(err.Error()),
@@ -503,15 +665,8 @@ func (f *handler) GetPostsSlugForum(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	f.views.Add(threadObj.Id)
 	if posts == nil {
-		if slugOrID.Id != 0 {
-			_, err := f.forumRepo.GetThreadByIDForum(int(slugOrID.Id))
-			if err == pgx.ErrNoRows {
-				httpErr := res.HttpError{Message: err.Error()}
-				res.SendResponse(404, httpErr, ctx)
-				return
-			}
-		}
 		res.SendResponseOK([]int{}, ctx)
 		return
 	}
@@ -542,6 +697,19 @@ func (f *handler) GetPostByIDForum(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	postObj := post["post"].(models.Post)
+	visible, err := f.canViewForum(ctx, postObj.Author, postObj.Forum, postObj.Status)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	if !visible {
+		httpErr := res.HttpError{Message: fmt.Sprintf("Can't find post with id: %d", id)}
+		res.SendResponse(404, httpErr, ctx)
+		return
+	}
+
+	res.SetLastModified(ctx, postObj.Created, postObj.Edited)
 	res.SendResponseOK(post, ctx)
 	return
 }
@@ -563,13 +731,51 @@ func (f *handler) UpdatePostForum(ctx *fasthttp.RequestCtx) {
 		Id: int64(id),
 	}
 
+	if !f.checkBodyLimitsForum(ctx) {
+		return
+	}
+
 	err = json.Unmarshal(ctx.PostBody(), &newPost)
 	if err != nil {
 		res.SendServerError(err.Error(), ctx)
 		return
 	}
 
-	newPost, err = f.forumRepo.UpdatePostForum(newPost)
+	oldPost, err := f.forumRepo.GetPostForum(id, []string{})
+	if err != nil {
+		httpErr := res.HttpError{Message: err.Error()}
+		res.SendResponse(404, httpErr, ctx)
+		return
+	}
+
+	before := oldPost["post"].(models.Post)
+	newPost.Status, newPost.StatusReason = "", ""
+	if newPost.Message != "" && newPost.Message != before.Message {
+		verdicts, ok := f.filterForum(ctx, []filter.Content{{
+			Author:  before.Author,
+			Forum:   before.Forum,
+			Kind:    "post",
+			Message: newPost.Message,
+			Post:    before.Id,
+			Thread:  before.Thread,
+		}})
+		if !ok {
+			return
+		}
+		newPost.Status, newPost.StatusReason = heldStatusForum(verdicts[0])
+	}
+
+	if newPost.Message != "" && newPost.Message != before.Message && !isAuthorForum(ctx, before.Author) {
+		update := newPost
+		err = f.auditedForum(ctx, "post.update", "post", strconv.Itoa(id), before.Forum, before,
+			func(repo forum.Repository) (interface{}, error) {
+				var err error
+				newPost, err = repo.UpdatePostForum(update)
+				return newPost, err
+			})
+	} else {
+		newPost, err = f.forumRepo.UpdatePostForum(newPost)
+	}
 	if err != nil {
 		httpErr := res.HttpError{Message: err.Error()}
 		res.SendResponse(404, httpErr, ctx)
@@ -591,7 +797,16 @@ func (f *handler) GetServiceStatusForum(ctx *fasthttp.RequestCtx) {
 }
 
 func (f *handler) ClearDataBaseForum(ctx *fasthttp.RequestCtx) {
-	err := f.forumRepo.ClearDatabaseForum()
+	info, err := f.forumRepo.GetServiceStatusForum()
+	if err != nil {
+		res.SendResponse(404, err.Error(), ctx)
+		return
+	}
+
+	err = f.auditedForum(ctx, "service.clear", "service", "database", "", info,
+		func(repo forum.Repository) (interface{}, error) {
+			return nil, repo.ClearDatabaseForum()
+		})
 	if err != nil {
 		res.SendResponse(404, err.Error(), ctx)
 		return
@@ -614,6 +829,7 @@ func (f *handler) Add(ctx *fasthttp.RequestCtx) {
 		res.SendServerError(err.Error(), ctx)
 		return
 	}
+	newUser.Karma = 0
 
 	err = f.forumRepo.Add(newUser)
 
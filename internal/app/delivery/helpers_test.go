@@ -0,0 +1,52 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"encoding/json"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"strings"
+	"testing"
+)
+
+type usersRepositoryForum struct {
+	forum.Repository
+	users map[string]models.User
+}
+
+func (r *usersRepositoryForum) GetByNick(nickname string) (models.User, error) {
+	userObj, ok := r.users[strings.ToLower(nickname)]
+	if !ok {
+		return models.User{}, pgx.ErrNoRows
+	}
+	return userObj, nil
+}
+
+func newUsersRepositoryForum(nicknames ...string) usersRepositoryForum {
+	users := map[string]models.User{}
+	for _, nickname := range nicknames {
+		users[strings.ToLower(nickname)] = models.User{Nickname: nickname}
+	}
+	return usersRepositoryForum{users: users}
+}
+
+func newTestCtxForum(method, actor, body string, userValues map[string]string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	if actor != "" {
+		ctx.Request.Header.Set("X-Nickname", actor)
+	}
+	ctx.Request.SetBodyString(body)
+	for key, value := range userValues {
+		ctx.SetUserValue(key, value)
+	}
+	return ctx
+}
+
+func decodeResponseForum(t *testing.T, ctx *fasthttp.RequestCtx, dest interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(ctx.Response.Body(), dest); err != nil {
+		t.Fatalf("decode %q: %s", ctx.Response.Body(), err)
+	}
+}
@@ -0,0 +1,46 @@
+package delivery
+
+import (
+	"DbGODZ/internal/pkg/res"
+	"fmt"
+	"github.com/valyala/fasthttp"
+)
+
+func (f *handler) sendLeaderboardForum(ctx *fasthttp.RequestCtx, slug string) {
+	limit, err := extractIntValueForum(ctx, "limit")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	leaders, err := f.forumRepo.GetLeaderboardForum(slug, limit)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(leaders, ctx)
+}
+
+func (f *handler) GetForumLeaderboardForum(ctx *fasthttp.RequestCtx) {
+	slug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	forumObj, err := f.forumRepo.GetBySlugForum(slug)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find forum with slug: %s", slug),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+
+	f.sendLeaderboardForum(ctx, forumObj.Slug)
+}
+
+func (f *handler) GetLeaderboardForum(ctx *fasthttp.RequestCtx) {
+	f.sendLeaderboardForum(ctx, "")
+}
@@ -0,0 +1,57 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"github.com/jackc/pgx"
+	"testing"
+)
+
+type leaderboardRepositoryForum struct {
+	forum.Repository
+	slugs []string
+}
+
+func (r *leaderboardRepositoryForum) GetBySlugForum(slug string) (models.Forum, error) {
+	if slug != "known" {
+		return models.Forum{}, pgx.ErrNoRows
+	}
+	return models.Forum{Slug: "Known"}, nil
+}
+
+func (r *leaderboardRepositoryForum) GetLeaderboardForum(slug string, limit int) ([]models.KarmaRank, error) {
+	r.slugs = append(r.slugs, slug)
+	return []models.KarmaRank{{Karma: 3, Nickname: "alice", Rank: 1}}, nil
+}
+
+func TestLeaderboardForum(t *testing.T) {
+	repo := &leaderboardRepositoryForum{}
+	f := &handler{forumRepo: repo}
+
+	ctx := newTestCtxForum("GET", "", "", map[string]string{"slug": "known"})
+	f.GetForumLeaderboardForum(ctx)
+	if ctx.Response.StatusCode() != 200 {
+		t.Fatalf("forum leaderboard status = %d, want 200", ctx.Response.StatusCode())
+	}
+	var leaders []models.KarmaRank
+	decodeResponseForum(t, ctx, &leaders)
+	if len(leaders) != 1 || leaders[0].Nickname != "alice" {
+		t.Fatalf("leaders = %v", leaders)
+	}
+
+	ctx = newTestCtxForum("GET", "", "", map[string]string{"slug": "missing"})
+	f.GetForumLeaderboardForum(ctx)
+	if ctx.Response.StatusCode() != 404 {
+		t.Fatalf("missing forum status = %d, want 404", ctx.Response.StatusCode())
+	}
+
+	ctx = newTestCtxForum("GET", "", "", nil)
+	f.GetLeaderboardForum(ctx)
+	if ctx.Response.StatusCode() != 200 {
+		t.Fatalf("global leaderboard status = %d, want 200", ctx.Response.StatusCode())
+	}
+
+	if len(repo.slugs) != 2 || repo.slugs[0] != "Known" || repo.slugs[1] != "" {
+		t.Fatalf("leaderboard slugs = %q, want the canonical forum slug then the global board", repo.slugs)
+	}
+}
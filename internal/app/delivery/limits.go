@@ -0,0 +1,94 @@
+package delivery
+
+import (
+	"DbGODZ/internal/pkg/res"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/valyala/fasthttp"
+	"unicode/utf8"
+)
+
+type Limits struct {
+	MaxPostBatch     int
+	MaxMessageLength int
+}
+
+var DefaultLimits = Limits{
+	MaxPostBatch:     1000,
+	MaxMessageLength: 64 << 10,
+}
+
+type bodyFrameForum struct {
+	object    bool
+	expectKey bool
+	key       string
+}
+
+func (f *handler) checkBodyLimitsForum(ctx *fasthttp.RequestCtx) bool {
+	message := bodyLimitsMessageForum(ctx.PostBody(), f.limits)
+	if message == "" {
+		return true
+	}
+	res.SendResponse(413, res.HttpError{Message: message}, ctx)
+	return false
+}
+
+func bodyLimitsMessageForum(body []byte, limits Limits) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	var stack []*bodyFrameForum
+	items := 0
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+
+		var top *bodyFrameForum
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if top != nil && top.object && top.expectKey {
+			if key, ok := token.(string); ok {
+				top.key = key
+				top.expectKey = false
+				continue
+			}
+		}
+
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return ""
+			}
+			if parent := stack[len(stack)-1]; parent.object {
+				parent.expectKey = true
+			}
+			continue
+		}
+
+		if len(stack) == 1 && !top.object {
+			items++
+			if limits.MaxPostBatch > 0 && items > limits.MaxPostBatch {
+				return fmt.Sprintf("at most %d posts can be created at once", limits.MaxPostBatch)
+			}
+		}
+		if text, ok := token.(string); ok && top != nil && top.object && top.key == "message" &&
+			(len(stack) == 1 || len(stack) == 2 && !stack[0].object) && limits.MaxMessageLength > 0 &&
+			utf8.RuneCountInString(text) > limits.MaxMessageLength {
+			return fmt.Sprintf("message is longer than %d characters", limits.MaxMessageLength)
+		}
+
+		if delim, ok := token.(json.Delim); ok {
+			stack = append(stack, &bodyFrameForum{object: delim == '{', expectKey: delim == '{'})
+			continue
+		}
+		if top == nil {
+			return ""
+		}
+		if top.object {
+			top.expectKey = true
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package delivery
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBodyLimitsMessageForum(t *testing.T) {
+	limits := Limits{MaxPostBatch: 3, MaxMessageLength: 5}
+	tooMany := "at most 3 posts can be created at once"
+	tooLong := "message is longer than 5 characters"
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", ``, ""},
+		{"single post", `{"author":"a","message":"hello"}`, ""},
+		{"long message", `{"author":"a","message":"hello!"}`, tooLong},
+		{"runes not bytes", `{"message":"привет"}`, tooLong},
+		{"five runes", `{"message":"héllo"}`, ""},
+		{"batch at limit", `[{"message":"a"},{"message":"b"},{"message":"c"}]`, ""},
+		{"batch over limit", `[{"message":"a"},{"message":"b"},{"message":"c"},{"message":"d"}]`, tooMany},
+		{"long message in batch", `[{"message":"a"},{"message":"hello!"}]`, tooLong},
+		{"nested arrays are not posts", `[{"tags":["a","b","c","d","e"]}]`, ""},
+		{"nested message field", `{"message":"hi","poll":{"message":"hello world"}}`, ""},
+		{"nested message in batch", `[{"parent":{"message":"hello world"}}]`, ""},
+		{"message in nested array", `{"posts":[{"message":"hello world"}]}`, ""},
+		{"non-message key", `{"title":"hello world","message":"hi"}`, ""},
+		{"message value after nested object", `{"meta":{"a":1},"message":"hello!"}`, tooLong},
+		{"message as key inside array value", `{"tags":["message","hello world"]}`, ""},
+		{"number message", `{"message":123456789}`, ""},
+		{"malformed object", `{"message":"hello!"`, tooLong},
+		{"malformed before message", `{"message" "hello!"}`, ""},
+		{"truncated batch", `[{"message":"a"},{"mess`, ""},
+		{"garbage", `not json`, ""},
+		{"batch over limit then garbage", `[1,2,3,4,}`, tooMany},
+		{"scalar", `"hello world"`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := bodyLimitsMessageForum([]byte(tt.body), limits); got != tt.want {
+			t.Fatalf("%s: %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBodyLimitsMessageForumZeroLimits(t *testing.T) {
+	body := `[{"message":"` + strings.Repeat("a", 1000) + `"},{},{},{}]`
+	if got := bodyLimitsMessageForum([]byte(body), Limits{}); got != "" {
+		t.Fatalf("zero limits rejected the body: %q", got)
+	}
+	if got := bodyLimitsMessageForum([]byte(body), DefaultLimits); got != "" {
+		t.Fatalf("default limits rejected the body: %q", got)
+	}
+}
+
+func TestCheckBodyLimitsForum(t *testing.T) {
+	f := &handler{limits: Limits{MaxPostBatch: 1}}
+
+	ctx := newTestCtxForum("POST", "", `[{},{}]`, nil)
+	if f.checkBodyLimitsForum(ctx) || ctx.Response.StatusCode() != 413 {
+		t.Fatalf("status = %d, want 413", ctx.Response.StatusCode())
+	}
+	ctx = newTestCtxForum("POST", "", `[{}]`, nil)
+	if !f.checkBodyLimitsForum(ctx) {
+		t.Fatalf("status = %d, want the body accepted", ctx.Response.StatusCode())
+	}
+}
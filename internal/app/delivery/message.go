@@ -0,0 +1,277 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/res"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxConversationParticipants = 20
+	defaultMessagePage          = 50
+	maxMessagePage              = 200
+)
+
+func (f *handler) getConversationForum(ctx *fasthttp.RequestCtx, nickname string) (models.Conversation, bool) {
+	ValueStr, found := ctx.UserValue("id").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return models.Conversation{}, false
+	}
+
+	id, err := strconv.Atoi(ValueStr)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return models.Conversation{}, false
+	}
+
+	conversation, err := f.forumRepo.GetConversationForum(id, nickname)
+	if err == pgx.ErrNoRows {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find conversation with id: %d", id),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return models.Conversation{}, false
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return models.Conversation{}, false
+	}
+	return conversation, true
+}
+
+func (f *handler) checkNotBlockedForum(ctx *fasthttp.RequestCtx, nickname string, others []string) bool {
+	blocked, err := f.forumRepo.HasBlockBetweenForum(nickname, others)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return false
+	}
+	if blocked {
+		res.SendResponse(403, res.HttpError{Message: "can't message a user who blocked you or whom you blocked"}, ctx)
+		return false
+	}
+	return true
+}
+
+func (f *handler) AddConversationForum(ctx *fasthttp.RequestCtx) {
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	var newConversation models.ConversationCreate
+	err := json.Unmarshal(ctx.PostBody(), &newConversation)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	participants := []string{userObj.Nickname}
+	seen := map[string]bool{strings.ToLower(userObj.Nickname): true}
+	for _, nickname := range newConversation.Participants {
+		if seen[strings.ToLower(nickname)] {
+			continue
+		}
+
+		participant, err := f.forumRepo.GetByNick(nickname)
+		if err != nil {
+			errHTTP := res.HttpError{
+				Message: fmt.Sprintf("Can't find user by nickname: %s", nickname),
+			}
+			res.SendResponse(404, errHTTP, ctx)
+			return
+		}
+		seen[strings.ToLower(participant.Nickname)] = true
+		participants = append(participants, participant.Nickname)
+	}
+
+	if len(participants) < 2 || len(participants) > maxConversationParticipants {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("conversation must have between 2 and %d participants", maxConversationParticipants),
+		}
+		res.SendResponse(400, errHTTP, ctx)
+		return
+	}
+
+	if !f.checkNotBlockedForum(ctx, userObj.Nickname, participants[1:]) {
+		return
+	}
+
+	var existingID int
+	if len(participants) == 2 {
+		existingID, err = f.forumRepo.FindDirectConversationForum(participants[0], participants[1])
+		if err != nil && err != pgx.ErrNoRows {
+			res.SendServerError(err.Error(), ctx)
+			return
+		}
+	}
+
+	status := 201
+	var conversation models.Conversation
+	if existingID != 0 {
+		status = 200
+		conversation, err = f.forumRepo.GetConversationForum(existingID, userObj.Nickname)
+	} else {
+		conversation, err = f.forumRepo.AddConversationForum(participants)
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	if strings.TrimSpace(newConversation.Message) != "" {
+		message, err := f.forumRepo.AddDirectMessageForum(models.DirectMessage{
+			Author:       userObj.Nickname,
+			Conversation: conversation.Id,
+			Message:      newConversation.Message,
+		})
+		if err != nil {
+			res.SendServerError(err.Error(), ctx)
+			return
+		}
+		conversation.LastMessage = &message
+		conversation.LastMessageAt = message.Created
+	}
+
+	res.SendResponse(status, conversation, ctx)
+}
+
+func (f *handler) GetConversationsForum(ctx *fasthttp.RequestCtx) {
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	limit, err := extractIntValueForum(ctx, "limit")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	conversations, err := f.forumRepo.GetConversationsForum(userObj.Nickname, limit)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(conversations, ctx)
+}
+
+func (f *handler) AddDirectMessageForum(ctx *fasthttp.RequestCtx) {
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	conversation, ok := f.getConversationForum(ctx, userObj.Nickname)
+	if !ok {
+		return
+	}
+
+	if !f.checkBodyLimitsForum(ctx) {
+		return
+	}
+
+	var newMessage models.DirectMessage
+	err := json.Unmarshal(ctx.PostBody(), &newMessage)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	if strings.TrimSpace(newMessage.Message) == "" {
+		res.SendResponse(400, res.HttpError{Message: "message must not be empty"}, ctx)
+		return
+	}
+
+	others := make([]string, 0, len(conversation.Participants))
+	for _, participant := range conversation.Participants {
+		if !strings.EqualFold(participant, userObj.Nickname) {
+			others = append(others, participant)
+		}
+	}
+	if !f.checkNotBlockedForum(ctx, userObj.Nickname, others) {
+		return
+	}
+
+	newMessage.Author = userObj.Nickname
+	newMessage.Conversation = conversation.Id
+	message, err := f.forumRepo.AddDirectMessageForum(newMessage)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponse(201, message, ctx)
+}
+
+func (f *handler) GetDirectMessagesForum(ctx *fasthttp.RequestCtx) {
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	conversation, ok := f.getConversationForum(ctx, userObj.Nickname)
+	if !ok {
+		return
+	}
+
+	limit, err := extractIntValueForum(ctx, "limit")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	if limit <= 0 {
+		limit = defaultMessagePage
+	}
+	if limit > maxMessagePage {
+		limit = maxMessagePage
+	}
+
+	var cursor int64
+	if cursorStr := string(ctx.QueryArgs().Peek("cursor")); cursorStr != "" {
+		cursor, err = strconv.ParseInt(cursorStr, 10, 64)
+		if err != nil || cursor < 0 {
+			res.SendResponse(400, res.HttpError{Message: "invalid cursor"}, ctx)
+			return
+		}
+	}
+
+	desc, err := extractBoolValueForum(ctx, "desc")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	messages, err := f.forumRepo.GetDirectMessagesForum(int(conversation.Id), cursor, limit+1, desc)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	page := models.MessagePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		page.NextCursor = strconv.FormatInt(page.Messages[limit-1].Id, 10)
+	}
+
+	var lastRead int64
+	for _, message := range page.Messages {
+		if message.Id > lastRead {
+			lastRead = message.Id
+		}
+	}
+	if lastRead > 0 {
+		err = f.forumRepo.MarkConversationReadForum(int(conversation.Id), userObj.Nickname, lastRead)
+		if err != nil {
+			res.SendServerError(err.Error(), ctx)
+			return
+		}
+	}
+
+	res.SendResponseOK(page, ctx)
+}
@@ -0,0 +1,151 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"github.com/jackc/pgx"
+	"strings"
+	"testing"
+)
+
+type messageRepositoryForum struct {
+	usersRepositoryForum
+	blocked  bool
+	existing int
+	created  [][]string
+	messages []models.DirectMessage
+	lastRead int64
+}
+
+func (r *messageRepositoryForum) HasBlockBetweenForum(nickname string, others []string) (bool, error) {
+	return r.blocked, nil
+}
+
+func (r *messageRepositoryForum) FindDirectConversationForum(nickname, other string) (int, error) {
+	if r.existing == 0 {
+		return 0, pgx.ErrNoRows
+	}
+	return r.existing, nil
+}
+
+func (r *messageRepositoryForum) AddConversationForum(participants []string) (models.Conversation, error) {
+	r.created = append(r.created, participants)
+	return models.Conversation{Id: 9, Participants: participants}, nil
+}
+
+func (r *messageRepositoryForum) GetConversationForum(id int, nickname string) (models.Conversation, error) {
+	if id != r.existing && id != 9 {
+		return models.Conversation{}, pgx.ErrNoRows
+	}
+	return models.Conversation{Id: int32(id), Participants: []string{"alice", "bob"}}, nil
+}
+
+func (r *messageRepositoryForum) AddDirectMessageForum(message models.DirectMessage) (models.DirectMessage, error) {
+	message.Id = int64(len(r.messages) + 1)
+	r.messages = append(r.messages, message)
+	return message, nil
+}
+
+func (r *messageRepositoryForum) GetDirectMessagesForum(conversationID int, cursor int64, limit int,
+	desc bool) ([]models.DirectMessage, error) {
+	data := make([]models.DirectMessage, 0, limit)
+	for _, message := range r.messages {
+		if message.Id > cursor && len(data) < limit {
+			data = append(data, message)
+		}
+	}
+	return data, nil
+}
+
+func (r *messageRepositoryForum) MarkConversationReadForum(conversationID int, nickname string, lastRead int64) error {
+	r.lastRead = lastRead
+	return nil
+}
+
+func TestAddConversationForum(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		blocked          bool
+		existing         int
+		wantStatus       int
+		wantParticipants string
+	}{
+		{"new conversation", `{"participants":["BOB"],"message":"hi"}`, false, 0, 201, "alice,bob"},
+		{"duplicates and self are skipped", `{"participants":["bob","Bob","alice"]}`, false, 0, 201, "alice,bob"},
+		{"existing direct conversation", `{"participants":["bob"]}`, false, 4, 200, ""},
+		{"group ignores existing direct", `{"participants":["bob","carol"]}`, false, 4, 201, "alice,bob,carol"},
+		{"alone", `{"participants":["alice"]}`, false, 0, 400, ""},
+		{"unknown participant", `{"participants":["nobody"]}`, false, 0, 404, ""},
+		{"blocked", `{"participants":["bob"]}`, true, 0, 403, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &messageRepositoryForum{usersRepositoryForum: newUsersRepositoryForum("alice", "bob", "carol"),
+				blocked: tt.blocked, existing: tt.existing}
+			f := &handler{forumRepo: repo}
+			ctx := newTestCtxForum("POST", "alice", tt.body, nil)
+
+			f.AddConversationForum(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", ctx.Response.StatusCode(), tt.wantStatus, ctx.Response.Body())
+			}
+			if tt.wantParticipants == "" {
+				if len(repo.created) != 0 {
+					t.Fatalf("created conversations %v", repo.created)
+				}
+				return
+			}
+			if len(repo.created) != 1 || strings.Join(repo.created[0], ",") != tt.wantParticipants {
+				t.Fatalf("created conversations %v, want %s", repo.created, tt.wantParticipants)
+			}
+		})
+	}
+}
+
+func TestGetDirectMessagesForumPages(t *testing.T) {
+	repo := &messageRepositoryForum{usersRepositoryForum: newUsersRepositoryForum("alice", "bob"), existing: 4}
+	for i := 0; i < 5; i++ {
+		repo.AddDirectMessageForum(models.DirectMessage{Author: "bob", Conversation: 4, Message: "hi"})
+	}
+	f := &handler{forumRepo: repo}
+
+	ctx := newTestCtxForum("GET", "alice", "", map[string]string{"id": "4"})
+	ctx.Request.URI().SetQueryString("limit=3")
+	f.GetDirectMessagesForum(ctx)
+	if ctx.Response.StatusCode() != 200 {
+		t.Fatalf("status = %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var page models.MessagePage
+	decodeResponseForum(t, ctx, &page)
+	if len(page.Messages) != 3 || page.NextCursor != "3" || repo.lastRead != 3 {
+		t.Fatalf("first page = %d messages, cursor %q, read up to %d; want 3, \"3\", 3", len(page.Messages),
+			page.NextCursor, repo.lastRead)
+	}
+
+	ctx = newTestCtxForum("GET", "alice", "", map[string]string{"id": "4"})
+	ctx.Request.URI().SetQueryString("limit=3&cursor=" + page.NextCursor)
+	f.GetDirectMessagesForum(ctx)
+	page = models.MessagePage{}
+	decodeResponseForum(t, ctx, &page)
+	if len(page.Messages) != 2 || page.NextCursor != "" || repo.lastRead != 5 {
+		t.Fatalf("last page = %d messages, cursor %q, read up to %d; want 2, \"\", 5", len(page.Messages),
+			page.NextCursor, repo.lastRead)
+	}
+
+	for _, query := range []string{"cursor=-1", "cursor=abc"} {
+		ctx = newTestCtxForum("GET", "alice", "", map[string]string{"id": "4"})
+		ctx.Request.URI().SetQueryString(query)
+		f.GetDirectMessagesForum(ctx)
+		if ctx.Response.StatusCode() != 400 {
+			t.Fatalf("%s: status = %d, want 400", query, ctx.Response.StatusCode())
+		}
+	}
+
+	ctx = newTestCtxForum("GET", "alice", "", map[string]string{"id": "7"})
+	f.GetDirectMessagesForum(ctx)
+	if ctx.Response.StatusCode() != 404 {
+		t.Fatalf("foreign conversation status = %d, want 404", ctx.Response.StatusCode())
+	}
+}
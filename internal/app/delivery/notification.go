@@ -0,0 +1,99 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/res"
+	"encoding/json"
+	"github.com/valyala/fasthttp"
+	"strings"
+)
+
+func (f *handler) GetNotificationsForum(ctx *fasthttp.RequestCtx) {
+	nickname, found := ctx.UserValue("nickname").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(userObj.Nickname, nickname) {
+		res.SendResponse(403, res.HttpError{Message: "users can only read their own notifications"}, ctx)
+		return
+	}
+
+	limit, err := extractIntValueForum(ctx, "limit")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	since, err := extractIntValueForum(ctx, "since")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	desc, err := extractBoolValueForum(ctx, "desc")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	unreadOnly, err := extractBoolValueForum(ctx, "unread")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	var inbox models.NotificationInbox
+	inbox.Notifications, err = f.forumRepo.GetNotificationsForum(userObj.Nickname, limit, int64(since), desc, unreadOnly)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	inbox.Unread, err = f.forumRepo.CountUnreadNotificationsForum(userObj.Nickname)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(inbox, ctx)
+}
+
+func (f *handler) MarkNotificationsReadForum(ctx *fasthttp.RequestCtx) {
+	nickname, found := ctx.UserValue("nickname").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(userObj.Nickname, nickname) {
+		res.SendResponse(403, res.HttpError{Message: "users can only clear their own notifications"}, ctx)
+		return
+	}
+
+	var read models.NotificationRead
+	if len(ctx.PostBody()) > 0 {
+		err := json.Unmarshal(ctx.PostBody(), &read)
+		if err != nil {
+			res.SendServerError(err.Error(), ctx)
+			return
+		}
+	}
+
+	unread, err := f.forumRepo.MarkNotificationsReadForum(userObj.Nickname, read.Ids)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(map[string]int64{"unread": unread}, ctx)
+}
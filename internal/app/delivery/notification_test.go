@@ -0,0 +1,67 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+type notificationRepositoryForum struct {
+	usersRepositoryForum
+	listed  []string
+	cleared []string
+}
+
+func (r *notificationRepositoryForum) GetNotificationsForum(nickname string, limit int, since int64, desc, unreadOnly bool) ([]models.Notification, error) {
+	r.listed = append(r.listed, nickname)
+	return []models.Notification{}, nil
+}
+
+func (r *notificationRepositoryForum) CountUnreadNotificationsForum(nickname string) (int64, error) {
+	return 0, nil
+}
+
+func (r *notificationRepositoryForum) MarkNotificationsReadForum(nickname string, ids []int64) (int64, error) {
+	r.cleared = append(r.cleared, nickname)
+	return 0, nil
+}
+
+func TestNotificationsForumRequireOwner(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      string
+		nickname   string
+		wantStatus int
+	}{
+		{"owner", "alice", "alice", 200},
+		{"owner with different case", "ALICE", "alice", 200},
+		{"another user", "mallory", "alice", 403},
+		{"missing header", "", "alice", 401},
+		{"unknown actor", "nobody", "alice", 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &notificationRepositoryForum{usersRepositoryForum: newUsersRepositoryForum("alice", "mallory")}
+			f := &handler{forumRepo: repo}
+
+			ctx := newTestCtxForum("GET", tt.actor, "", map[string]string{"nickname": tt.nickname})
+			f.GetNotificationsForum(ctx)
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("list status = %d, want %d", ctx.Response.StatusCode(), tt.wantStatus)
+			}
+
+			ctx = newTestCtxForum("POST", tt.actor, `{"ids":[1]}`, map[string]string{"nickname": tt.nickname})
+			f.MarkNotificationsReadForum(ctx)
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("mark status = %d, want %d", ctx.Response.StatusCode(), tt.wantStatus)
+			}
+
+			if tt.wantStatus != 200 && (len(repo.listed) != 0 || len(repo.cleared) != 0) {
+				t.Fatalf("inbox was accessed: listed %v, cleared %v", repo.listed, repo.cleared)
+			}
+			if tt.wantStatus == 200 && (len(repo.listed) != 1 || len(repo.cleared) != 1) {
+				t.Fatalf("inbox not accessed: listed %v, cleared %v", repo.listed, repo.cleared)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/res"
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/strfmt"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"strings"
+	"time"
+)
+
+const maxPollOptions = 20
+
+func validatePollForum(poll *models.Poll) string {
+	if strings.TrimSpace(poll.Question) == "" {
+		return "poll question is required"
+	}
+	if len(poll.Options) < 2 || len(poll.Options) > maxPollOptions {
+		return fmt.Sprintf("poll must have between 2 and %d options", maxPollOptions)
+	}
+	for _, option := range poll.Options {
+		if strings.TrimSpace(option.Text) == "" {
+			return "poll options must not be empty"
+		}
+	}
+	if poll.ClosesAt != "" {
+		closesAt, err := strfmt.ParseDateTime(poll.ClosesAt)
+		if err != nil {
+			return "closesAt must be a RFC 3339 timestamp"
+		}
+		if !time.Time(closesAt).After(time.Now()) {
+			return "closesAt must be in the future"
+		}
+	}
+	return ""
+}
+
+func validateBallotForum(poll models.Poll, ballot models.PollBallot) string {
+	if len(ballot.Options) == 0 {
+		return "at least one option is required"
+	}
+	if !poll.Multiple && len(ballot.Options) > 1 {
+		return "poll allows a single choice only"
+	}
+
+	known := make(map[int32]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		known[option.Id] = true
+	}
+	chosen := make(map[int32]bool, len(ballot.Options))
+	for _, option := range ballot.Options {
+		if !known[option] {
+			return fmt.Sprintf("poll has no option with id: %d", option)
+		}
+		if chosen[option] {
+			return fmt.Sprintf("option %d is chosen twice", option)
+		}
+		chosen[option] = true
+	}
+	return ""
+}
+
+func (f *handler) GetPollForum(ctx *fasthttp.RequestCtx) {
+	threadObj, ok := f.getVisibleThreadForum(ctx)
+	if !ok {
+		return
+	}
+	threadID := int(threadObj.Id)
+
+	poll, err := f.forumRepo.GetPollForum(threadID, extractActorForum(ctx))
+	if err == pgx.ErrNoRows {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find poll in thread: %d", threadID),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(poll, ctx)
+}
+
+func (f *handler) VotePollForum(ctx *fasthttp.RequestCtx) {
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	threadObj, ok := f.getVisibleThreadForum(ctx)
+	if !ok {
+		return
+	}
+	threadID := int(threadObj.Id)
+
+	var ballot models.PollBallot
+	err := json.Unmarshal(ctx.PostBody(), &ballot)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	poll, err := f.forumRepo.GetPollForum(threadID, "")
+	if err == pgx.ErrNoRows {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find poll in thread: %d", threadID),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	if poll.Closed {
+		res.SendResponse(403, res.HttpError{Message: "poll is closed"}, ctx)
+		return
+	}
+	if message := validateBallotForum(poll, ballot); message != "" {
+		res.SendResponse(400, res.HttpError{Message: message}, ctx)
+		return
+	}
+
+	ballot.Nickname = userObj.Nickname
+	ballot.Poll = poll.Id
+	err = f.forumRepo.VotePollForum(ballot)
+	if pgerr, ok := err.(pgx.PgError); ok {
+		switch pgerr.Code {
+		case "23505":
+			res.SendResponse(409, res.HttpError{Message: "user has already voted in this poll"}, ctx)
+			return
+		case "00403":
+			res.SendResponse(403, res.HttpError{Message: "poll is closed"}, ctx)
+			return
+		}
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	poll, err = f.forumRepo.GetPollForum(threadID, userObj.Nickname)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(poll, ctx)
+}
@@ -0,0 +1,68 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+	"time"
+)
+
+func TestValidatePollForum(t *testing.T) {
+	options := []models.PollOption{{Text: "yes"}, {Text: "no"}}
+	tooMany := make([]models.PollOption, maxPollOptions+1)
+	for i := range tooMany {
+		tooMany[i].Text = "option"
+	}
+
+	tests := []struct {
+		name  string
+		poll  models.Poll
+		valid bool
+	}{
+		{"valid", models.Poll{Question: "Ship it?", Options: options}, true},
+		{"closes in the future", models.Poll{Question: "Ship it?", Options: options,
+			ClosesAt: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)}, true},
+		{"blank question", models.Poll{Question: " ", Options: options}, false},
+		{"single option", models.Poll{Question: "Ship it?", Options: options[:1]}, false},
+		{"too many options", models.Poll{Question: "Ship it?", Options: tooMany}, false},
+		{"blank option", models.Poll{Question: "Ship it?", Options: []models.PollOption{{Text: "yes"}, {Text: ""}}}, false},
+		{"malformed close time", models.Poll{Question: "Ship it?", Options: options, ClosesAt: "tomorrow"}, false},
+		{"closes in the past", models.Poll{Question: "Ship it?", Options: options,
+			ClosesAt: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if message := validatePollForum(&tt.poll); (message == "") != tt.valid {
+				t.Fatalf("validatePollForum = %q, want valid %t", message, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateBallotForum(t *testing.T) {
+	single := models.Poll{Options: []models.PollOption{{Id: 1}, {Id: 2}}}
+	multiple := models.Poll{Multiple: true, Options: single.Options}
+
+	tests := []struct {
+		name    string
+		poll    models.Poll
+		options []int32
+		valid   bool
+	}{
+		{"single choice", single, []int32{2}, true},
+		{"multiple choice", multiple, []int32{1, 2}, true},
+		{"empty ballot", single, nil, false},
+		{"two choices on single poll", single, []int32{1, 2}, false},
+		{"unknown option", multiple, []int32{3}, false},
+		{"duplicate option", multiple, []int32{1, 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := validateBallotForum(tt.poll, models.PollBallot{Options: tt.options})
+			if (message == "") != tt.valid {
+				t.Fatalf("validateBallotForum = %q, want valid %t", message, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/app/views"
+	"database/sql"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"strings"
+	"testing"
+)
+
+type premoderationRepositoryForum struct {
+	forum.Repository
+	forums     map[string]models.Forum
+	moderators map[string]bool
+	updated    []bool
+}
+
+func (r *premoderationRepositoryForum) GetBySlugForum(slug string) (models.Forum, error) {
+	forumObj, ok := r.forums[strings.ToLower(slug)]
+	if !ok {
+		return models.Forum{}, pgx.ErrNoRows
+	}
+	return forumObj, nil
+}
+
+func (r *premoderationRepositoryForum) IsModeratorForum(slug, nickname string) (bool, error) {
+	return r.moderators[strings.ToLower(nickname)], nil
+}
+
+func (r *premoderationRepositoryForum) SetPremoderationForum(slug string, enabled bool) (models.Forum, error) {
+	r.updated = append(r.updated, enabled)
+	forumObj := r.forums[strings.ToLower(slug)]
+	forumObj.Premoderated = enabled
+	return forumObj, nil
+}
+
+func (r *premoderationRepositoryForum) WithAuditForum(audit *models.Audit,
+	fn func(repo forum.Repository) (interface{}, error)) error {
+	_, err := fn(r)
+	return err
+}
+
+func newPremoderationRepositoryForum() *premoderationRepositoryForum {
+	return &premoderationRepositoryForum{
+		forums: map[string]models.Forum{
+			"open":   {Slug: "open", User: "owner"},
+			"closed": {Slug: "closed", User: "owner", Premoderated: true},
+		},
+		moderators: map[string]bool{"moderator": true},
+	}
+}
+
+func TestPremoderatedAuthorsForum(t *testing.T) {
+	f := &handler{forumRepo: newPremoderationRepositoryForum()}
+
+	tests := []struct {
+		forum string
+		want  map[string]bool
+	}{
+		{"open", map[string]bool{}},
+		{"missing", map[string]bool{}},
+		{"closed", map[string]bool{"alice": true, "moderator": false}},
+	}
+	for _, tt := range tests {
+		held, err := f.premoderatedAuthorsForum(tt.forum, []string{"Alice", "alice", "Moderator"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(held) != len(tt.want) {
+			t.Fatalf("%s: held = %v, want %v", tt.forum, held, tt.want)
+		}
+		for author, want := range tt.want {
+			if held[author] != want {
+				t.Fatalf("%s: held = %v, want %v", tt.forum, held, tt.want)
+			}
+		}
+	}
+}
+
+func TestCanViewForum(t *testing.T) {
+	f := &handler{forumRepo: newPremoderationRepositoryForum()}
+
+	tests := []struct {
+		actor  string
+		status string
+		want   bool
+	}{
+		{"", "", true},
+		{"", "published", true},
+		{"", "pending", false},
+		{"bob", "pending", false},
+		{"ALICE", "pending", true},
+		{"moderator", "pending", true},
+		{"alice", "rejected", false},
+		{"moderator", "rejected", true},
+	}
+	for _, tt := range tests {
+		ctx := newTestCtxForum("GET", tt.actor, "", nil)
+		visible, err := f.canViewForum(ctx, "alice", "closed", tt.status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if visible != tt.want {
+			t.Fatalf("actor %q status %q: visible = %v, want %v", tt.actor, tt.status, visible, tt.want)
+		}
+	}
+}
+
+func TestSetPremoderationForumRequiresOwner(t *testing.T) {
+	tests := []struct {
+		actor      string
+		wantStatus int
+	}{
+		{"", 403},
+		{"moderator", 403},
+		{"OWNER", 200},
+	}
+
+	for _, tt := range tests {
+		repo := newPremoderationRepositoryForum()
+		f := &handler{forumRepo: repo}
+		ctx := newTestCtxForum("POST", tt.actor, `{"enabled":true}`, map[string]string{"slug": "open"})
+
+		f.SetPremoderationForum(ctx)
+
+		if ctx.Response.StatusCode() != tt.wantStatus {
+			t.Fatalf("actor %q: status = %d, want %d", tt.actor, ctx.Response.StatusCode(), tt.wantStatus)
+		}
+		if (len(repo.updated) == 1) != (tt.wantStatus == 200) {
+			t.Fatalf("actor %q: updates = %v", tt.actor, repo.updated)
+		}
+		if tt.wantStatus == 200 {
+			var forumObj models.Forum
+			decodeResponseForum(t, ctx, &forumObj)
+			if !forumObj.Premoderated {
+				t.Fatalf("response = %+v, want premoderated", forumObj)
+			}
+		}
+	}
+}
+
+type hiddenThreadRepositoryForum struct {
+	usersRepositoryForum
+	thread models.Thread
+}
+
+func (r *hiddenThreadRepositoryForum) GetThreadIDBySlugForum(slug string) (int, error) {
+	if !strings.EqualFold(slug, r.thread.Slug.String) {
+		return 0, pgx.ErrNoRows
+	}
+	return int(r.thread.Id), nil
+}
+
+func (r *hiddenThreadRepositoryForum) GetThreadByIDForum(id int) (models.Thread, error) {
+	if id != int(r.thread.Id) {
+		return models.Thread{}, pgx.ErrNoRows
+	}
+	return r.thread, nil
+}
+
+func (r *hiddenThreadRepositoryForum) IsModeratorForum(slug, nickname string) (bool, error) {
+	return false, nil
+}
+
+func TestHiddenThreadsForumAreNotFound(t *testing.T) {
+	handlers := []struct {
+		name    string
+		handler func(f *handler, ctx *fasthttp.RequestCtx)
+		method  string
+		body    string
+		values  map[string]string
+	}{
+		{"details", (*handler).GetThreadDetailsSlugForum, "GET", "", nil},
+		{"posts", (*handler).GetPostsSlugForum, "GET", "", nil},
+		{"votes", (*handler).GetVotesForum, "GET", "", nil},
+		{"update", (*handler).UpdateThreadBySlugOrIDForum, "POST", `{"title":"new"}`, nil},
+		{"create post", (*handler).AddPostSlugForum, "POST", `[{"author":"mallory","message":"hi"}]`, nil},
+		{"vote by id", (*handler).AddVoteIDForum, "POST", `{"nickname":"mallory","voice":1}`,
+			map[string]string{"id": "5"}},
+		{"vote by slug", (*handler).AddVoteSlugForum, "POST", `{"nickname":"mallory","voice":1}`,
+			map[string]string{"slug": "hidden"}},
+		{"subscribe", (*handler).SubscribeThreadForum, "POST", "", nil},
+		{"read", (*handler).MarkThreadReadForum, "POST", "", nil},
+		{"mute", (*handler).MuteThreadForum, "POST", "", nil},
+		{"poll", (*handler).GetPollForum, "GET", "", nil},
+		{"poll vote", (*handler).VotePollForum, "POST", `{"options":[1]}`, nil},
+		{"report", (*handler).AddThreadReportForum, "POST", `{"reason":"spam"}`, nil},
+		{"stream", (*handler).StreamThreadForum, "GET", "", nil},
+	}
+
+	threads := []struct {
+		status string
+		actor  string
+	}{
+		{"pending", "mallory"},
+		{"rejected", "alice"},
+		{"draft", "mallory"},
+		{"scheduled", "mallory"},
+	}
+
+	for _, thread := range threads {
+		for _, slugOrID := range []string{"5", "hidden"} {
+			for _, tt := range handlers {
+				t.Run(thread.status+"/"+slugOrID+"/"+tt.name, func(t *testing.T) {
+					slug := models.JsonNullString{NullString: sql.NullString{String: "hidden", Valid: true}}
+					repo := &hiddenThreadRepositoryForum{
+						usersRepositoryForum: newUsersRepositoryForum("alice", "mallory"),
+						thread:               models.Thread{Id: 5, Slug: slug, Author: "alice", Forum: "closed", Status: thread.status},
+					}
+					f := &handler{forumRepo: repo, views: views.NewCounter(repo)}
+
+					values := tt.values
+					if values == nil {
+						values = map[string]string{"slug_or_id": slugOrID}
+					}
+					ctx := newTestCtxForum(tt.method, thread.actor, tt.body, values)
+					tt.handler(f, ctx)
+					if ctx.Response.StatusCode() != 404 {
+						t.Fatalf("status = %d, want 404: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+					}
+					if strings.Contains(string(ctx.Response.Body()), `"author"`) {
+						t.Fatalf("response leaked the thread: %s", ctx.Response.Body())
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestAddPostForumRejectsUnpublishedThreads(t *testing.T) {
+	repo := &hiddenThreadRepositoryForum{
+		usersRepositoryForum: newUsersRepositoryForum("alice"),
+		thread:               models.Thread{Id: 5, Author: "alice", Forum: "closed", Status: "pending"},
+	}
+	f := &handler{forumRepo: repo}
+
+	ctx := newTestCtxForum("POST", "alice", `[{"author":"alice","message":"hi"}]`,
+		map[string]string{"slug_or_id": "5"})
+	f.AddPostSlugForum(ctx)
+	if ctx.Response.StatusCode() != 403 {
+		t.Fatalf("status = %d, want 403", ctx.Response.StatusCode())
+	}
+}
@@ -0,0 +1,98 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/res"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"strings"
+	"time"
+)
+
+const maxSlowModeSeconds = 6 * 60 * 60
+
+type slowModeUpdate struct {
+	Seconds int32 `json:"seconds"`
+}
+
+func (f *handler) limitPostsForum(ctx *fasthttp.RequestCtx, threadID int, posts []models.Post) bool {
+	if f.limiter == nil {
+		return true
+	}
+
+	authors := make(map[string]int)
+	costs := map[string]float64{"ip:" + ctx.RemoteIP().String(): float64(len(posts))}
+	for _, post := range posts {
+		author := strings.ToLower(post.Author)
+		authors[author]++
+		costs["user:"+author]++
+	}
+	if !f.limiter.Allow(ctx, "posts", costs) {
+		return false
+	}
+
+	forumSlug, seconds, err := f.forumRepo.GetThreadSlowModeForum(threadID)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return false
+	}
+	return f.limiter.SlowMode(ctx, forumSlug, time.Duration(seconds)*time.Second, authors)
+}
+
+func (f *handler) SetSlowModeForum(ctx *fasthttp.RequestCtx) {
+	slug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	forumObj, err := f.forumRepo.GetBySlugForum(slug)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find forum with slug: %s", slug),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if !f.checkModeratorForum(ctx, forumObj.Slug) {
+		return
+	}
+
+	var update slowModeUpdate
+	err = json.Unmarshal(ctx.PostBody(), &update)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	if update.Seconds < 0 || update.Seconds > maxSlowModeSeconds {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("seconds must be between 0 and %d", maxSlowModeSeconds),
+		}
+		res.SendResponse(400, errHTTP, ctx)
+		return
+	}
+
+	var forumDB models.Forum
+	err = f.auditedForum(ctx, "forum.slowmode", "forum", forumObj.Slug, forumObj.Slug,
+		slowModeUpdate{Seconds: forumObj.SlowMode}, func(repo forum.Repository) (interface{}, error) {
+			var err error
+			forumDB, err = repo.SetSlowModeForum(forumObj.Slug, update.Seconds)
+			return slowModeUpdate{Seconds: forumDB.SlowMode}, err
+		})
+	if err == pgx.ErrNoRows {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find forum with slug: %s", slug),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponse(200, forumDB, ctx)
+}
@@ -0,0 +1,50 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/app/ratelimit"
+	"errors"
+	"testing"
+)
+
+type slowModeRepositoryForum struct {
+	forum.Repository
+	seconds int32
+	err     error
+}
+
+func (r *slowModeRepositoryForum) GetThreadSlowModeForum(threadID int) (string, int32, error) {
+	return "forum", r.seconds, r.err
+}
+
+func TestLimitPostsForumSlowModeError(t *testing.T) {
+	repo := &slowModeRepositoryForum{err: errors.New("connection refused")}
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.DefaultBudgets, ratelimit.DefaultRoutes)
+	f := &handler{forumRepo: repo, limiter: limiter}
+	ctx := newTestCtxForum("POST", "", "", nil)
+
+	if f.limitPostsForum(ctx, 1, []models.Post{{Author: "alice"}}) {
+		t.Fatal("posts were allowed although the slow mode lookup failed")
+	}
+	if ctx.Response.StatusCode() != 500 {
+		t.Fatalf("status = %d, want 500", ctx.Response.StatusCode())
+	}
+}
+
+func TestLimitPostsForumSlowMode(t *testing.T) {
+	repo := &slowModeRepositoryForum{seconds: 60}
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.DefaultBudgets, ratelimit.DefaultRoutes)
+	f := &handler{forumRepo: repo, limiter: limiter}
+
+	if !f.limitPostsForum(newTestCtxForum("POST", "", "", nil), 1, []models.Post{{Author: "alice"}}) {
+		t.Fatal("first post was rejected")
+	}
+	ctx := newTestCtxForum("POST", "", "", nil)
+	if f.limitPostsForum(ctx, 1, []models.Post{{Author: "Alice"}}) {
+		t.Fatal("second post inside the slow mode interval was accepted")
+	}
+	if ctx.Response.StatusCode() != 429 {
+		t.Fatalf("status = %d, want 429", ctx.Response.StatusCode())
+	}
+}
@@ -0,0 +1,135 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/res"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxEmojiLength = 16
+
+func extractPostIDForum(ctx *fasthttp.RequestCtx) (int64, bool) {
+	ValueStr, found := ctx.UserValue("id").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(ValueStr, 10, 64)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return 0, false
+	}
+	return id, true
+}
+
+func validateEmojiForum(emoji string) bool {
+	if emoji == "" || !utf8.ValidString(emoji) || utf8.RuneCountInString(emoji) > maxEmojiLength {
+		return false
+	}
+	return strings.IndexFunc(emoji, unicode.IsSpace) < 0
+}
+
+func (f *handler) sendPostForum(ctx *fasthttp.RequestCtx, id int64, err error) {
+	if pgerr, ok := err.(pgx.PgError); ok && pgerr.Code == "23503" {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find user or post with id: %d", id),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	post, err := f.forumRepo.GetPostForum(int(id), []string{})
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find post with id: %d", id),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+
+	res.SendResponseOK(post["post"], ctx)
+}
+
+func (f *handler) VotePostForum(ctx *fasthttp.RequestCtx) {
+	id, ok := extractPostIDForum(ctx)
+	if !ok {
+		return
+	}
+
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	var newVote models.PostVote
+	err := json.Unmarshal(ctx.PostBody(), &newVote)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	newVote.IdPost = id
+	newVote.Nickname = userObj.Nickname
+
+	if newVote.Voice < -1 || newVote.Voice > 1 {
+		res.SendResponse(400, res.HttpError{Message: "voice must be -1, 0 or 1"}, ctx)
+		return
+	}
+
+	f.sendPostForum(ctx, id, f.forumRepo.VotePostForum(newVote))
+}
+
+func (f *handler) reactPostForum(ctx *fasthttp.RequestCtx, add bool) {
+	id, ok := extractPostIDForum(ctx)
+	if !ok {
+		return
+	}
+
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	var reaction models.Reaction
+	err := json.Unmarshal(ctx.PostBody(), &reaction)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	reaction.IdPost = id
+	reaction.Nickname = userObj.Nickname
+
+	if !validateEmojiForum(reaction.Emoji) {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("emoji must be 1 to %d characters without spaces", maxEmojiLength),
+		}
+		res.SendResponse(400, errHTTP, ctx)
+		return
+	}
+
+	if add {
+		err = f.forumRepo.AddReactionForum(reaction)
+	} else {
+		err = f.forumRepo.DeleteReactionForum(reaction)
+	}
+	f.sendPostForum(ctx, id, err)
+}
+
+func (f *handler) AddReactionForum(ctx *fasthttp.RequestCtx) {
+	f.reactPostForum(ctx, true)
+}
+
+func (f *handler) DeleteReactionForum(ctx *fasthttp.RequestCtx) {
+	f.reactPostForum(ctx, false)
+}
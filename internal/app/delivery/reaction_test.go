@@ -0,0 +1,105 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+type reactionRepositoryForum struct {
+	usersRepositoryForum
+	votes     []models.PostVote
+	reactions []models.Reaction
+}
+
+func (r *reactionRepositoryForum) VotePostForum(vote models.PostVote) error {
+	r.votes = append(r.votes, vote)
+	return nil
+}
+
+func (r *reactionRepositoryForum) AddReactionForum(reaction models.Reaction) error {
+	r.reactions = append(r.reactions, reaction)
+	return nil
+}
+
+func (r *reactionRepositoryForum) DeleteReactionForum(reaction models.Reaction) error {
+	r.reactions = append(r.reactions, reaction)
+	return nil
+}
+
+func (r *reactionRepositoryForum) GetPostForum(id int, related []string) (map[string]interface{}, error) {
+	return map[string]interface{}{"post": models.Post{Id: int64(id)}}, nil
+}
+
+func TestPostVotesAndReactionsForumUseActor(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      string
+		wantStatus int
+		wantActor  string
+	}{
+		{"actor from header", "alice", 200, "alice"},
+		{"missing header", "", 401, ""},
+		{"unknown actor", "nobody", 404, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &reactionRepositoryForum{usersRepositoryForum: newUsersRepositoryForum("alice")}
+			f := &handler{forumRepo: repo}
+			params := map[string]string{"id": "3"}
+
+			ctx := newTestCtxForum("POST", tt.actor, `{"nickname":"mallory","voice":1}`, params)
+			f.VotePostForum(ctx)
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("vote status = %d, want %d", ctx.Response.StatusCode(), tt.wantStatus)
+			}
+
+			ctx = newTestCtxForum("POST", tt.actor, `{"nickname":"mallory","emoji":"+1"}`, params)
+			f.AddReactionForum(ctx)
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("add reaction status = %d, want %d", ctx.Response.StatusCode(), tt.wantStatus)
+			}
+
+			ctx = newTestCtxForum("DELETE", tt.actor, `{"nickname":"mallory","emoji":"+1"}`, params)
+			f.DeleteReactionForum(ctx)
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("delete reaction status = %d, want %d", ctx.Response.StatusCode(), tt.wantStatus)
+			}
+
+			if tt.wantActor == "" {
+				if len(repo.votes) != 0 || len(repo.reactions) != 0 {
+					t.Fatalf("stored votes %v and reactions %v", repo.votes, repo.reactions)
+				}
+				return
+			}
+			if len(repo.votes) != 1 || repo.votes[0].Nickname != tt.wantActor || repo.votes[0].IdPost != 3 {
+				t.Fatalf("votes = %v, want one by %s on post 3", repo.votes, tt.wantActor)
+			}
+			for _, reaction := range repo.reactions {
+				if reaction.Nickname != tt.wantActor || reaction.IdPost != 3 {
+					t.Fatalf("reaction = %v, want one by %s on post 3", reaction, tt.wantActor)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateEmojiForum(t *testing.T) {
+	tests := []struct {
+		emoji string
+		valid bool
+	}{
+		{"+1", true},
+		{"🎉", true},
+		{"", false},
+		{"two words", false},
+		{"\xff", false},
+		{"aaaaaaaaaaaaaaaaa", false},
+	}
+
+	for _, tt := range tests {
+		if got := validateEmojiForum(tt.emoji); got != tt.valid {
+			t.Errorf("validateEmojiForum(%q) = %t, want %t", tt.emoji, got, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/res"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"strconv"
+	"strings"
+)
+
+func extractActorForum(ctx *fasthttp.RequestCtx) string {
+	return string(ctx.Request.Header.Peek("X-Nickname"))
+}
+
+func (f *handler) getThreadIDForum(slugOrID string) (int, error) {
+	if id, err := strconv.Atoi(slugOrID); err == nil {
+		return id, nil
+	}
+	return f.forumRepo.GetThreadIDBySlugForum(slugOrID)
+}
+
+func (f *handler) checkModeratorForum(ctx *fasthttp.RequestCtx, slug string) bool {
+	actor := extractActorForum(ctx)
+	if actor == "" {
+		res.SendResponse(401, res.HttpError{Message: "X-Nickname header is required"}, ctx)
+		return false
+	}
+
+	isModerator, err := f.forumRepo.IsModeratorForum(slug, actor)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return false
+	}
+	if !isModerator {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("User %s is not a moderator of forum: %s", actor, slug),
+		}
+		res.SendResponse(403, errHTTP, ctx)
+		return false
+	}
+	return true
+}
+
+func checkOwnerForum(ctx *fasthttp.RequestCtx, forumObj models.Forum) bool {
+	if !strings.EqualFold(extractActorForum(ctx), forumObj.User) {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Only the owner can manage forum: %s", forumObj.Slug),
+		}
+		res.SendResponse(403, errHTTP, ctx)
+		return false
+	}
+	return true
+}
+
+func (f *handler) addReportForum(ctx *fasthttp.RequestCtx, newReport models.Report) {
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	err := json.Unmarshal(ctx.PostBody(), &newReport)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	newReport.Author = userObj.Nickname
+	if strings.TrimSpace(newReport.Reason) == "" {
+		res.SendResponse(400, res.HttpError{Message: "reason is required"}, ctx)
+		return
+	}
+
+	reportDB, err := f.forumRepo.AddReportForum(newReport)
+	if err == pgx.ErrNoRows {
+		errHTTP := res.HttpError{
+			Message: "Can't find reported content",
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponse(201, reportDB, ctx)
+}
+
+func (f *handler) AddPostReportForum(ctx *fasthttp.RequestCtx) {
+	ValueStr, found := ctx.UserValue("id").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	id, err := strconv.ParseInt(ValueStr, 10, 64)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	var newReport models.Report
+	newReport.Post.Valid = true
+	newReport.Post.Int64 = id
+	f.addReportForum(ctx, newReport)
+}
+
+func (f *handler) AddThreadReportForum(ctx *fasthttp.RequestCtx) {
+	threadObj, ok := f.getVisibleThreadForum(ctx)
+	if !ok {
+		return
+	}
+
+	f.addReportForum(ctx, models.Report{Thread: threadObj.Id})
+}
+
+func (f *handler) GetReportsForum(ctx *fasthttp.RequestCtx) {
+	slug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	forumObj, err := f.forumRepo.GetBySlugForum(slug)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find forum with slug: %s", slug),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if !f.checkModeratorForum(ctx, forumObj.Slug) {
+		return
+	}
+
+	limit, err := extractIntValueForum(ctx, "limit")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	since, err := extractIntValueForum(ctx, "since")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	desc, err := extractBoolValueForum(ctx, "desc")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	status := string(ctx.QueryArgs().Peek("status"))
+	if status == "" {
+		status = "open"
+	}
+
+	related := string(ctx.QueryArgs().Peek("related"))
+
+	reports, err := f.forumRepo.GetReportsForum(forumObj.Slug, status, limit, int64(since), desc,
+		strings.Split(related, ","))
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(reports, ctx)
+}
+
+func (f *handler) closeReportForum(ctx *fasthttp.RequestCtx, status, action string) {
+	ValueStr, found := ctx.UserValue("id").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	id, err := strconv.ParseInt(ValueStr, 10, 64)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	reportObj, err := f.forumRepo.GetReportForum(id)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find report with id: %d", id),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if !f.checkModeratorForum(ctx, reportObj.Forum) {
+		return
+	}
+
+	before := reportObj
+	err = f.auditedForum(ctx, action, "report", ValueStr, before.Forum, before,
+		func(repo forum.Repository) (interface{}, error) {
+			var err error
+			reportObj, err = repo.CloseReportForum(id, status, extractActorForum(ctx))
+			return reportObj, err
+		})
+	if err == pgx.ErrNoRows {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Report %d is already closed", id),
+		}
+		res.SendResponse(409, errHTTP, ctx)
+		return
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	res.SendResponseOK(reportObj, ctx)
+}
+
+func (f *handler) ResolveReportForum(ctx *fasthttp.RequestCtx) {
+	f.closeReportForum(ctx, "resolved", "report.resolve")
+}
+
+func (f *handler) DismissReportForum(ctx *fasthttp.RequestCtx) {
+	f.closeReportForum(ctx, "dismissed", "report.dismiss")
+}
+
+func (f *handler) AddModeratorForum(ctx *fasthttp.RequestCtx) {
+	slug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	forumObj, err := f.forumRepo.GetBySlugForum(slug)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find forum with slug: %s", slug),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if !checkOwnerForum(ctx, forumObj) {
+		return
+	}
+
+	newModerator := models.Moderator{Forum: forumObj.Slug}
+	err = json.Unmarshal(ctx.PostBody(), &newModerator)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	newModerator.Forum = forumObj.Slug
+
+	var moderatorDB models.Moderator
+	err = f.auditedForum(ctx, "moderator.add", "user", newModerator.Nickname, forumObj.Slug, nil,
+		func(repo forum.Repository) (interface{}, error) {
+			var err error
+			moderatorDB, err = repo.AddModeratorForum(newModerator)
+			return moderatorDB, err
+		})
+	if err == pgx.ErrNoRows {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find user with nickname: %s", newModerator.Nickname),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponse(201, moderatorDB, ctx)
+}
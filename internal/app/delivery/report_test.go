@@ -0,0 +1,59 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+type reportRepositoryForum struct {
+	usersRepositoryForum
+	added []models.Report
+}
+
+func (r *reportRepositoryForum) AddReportForum(report models.Report) (models.Report, error) {
+	r.added = append(r.added, report)
+	return report, nil
+}
+
+func (r *reportRepositoryForum) GetThreadByIDForum(id int) (models.Thread, error) {
+	return models.Thread{Id: int32(id), Author: "bob", Forum: "forum", Status: "published"}, nil
+}
+
+func TestAddThreadReportForumUsesActor(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      string
+		body       string
+		wantStatus int
+		wantAuthor string
+	}{
+		{"actor from header", "alice", `{"reason":"spam"}`, 201, "alice"},
+		{"body author is ignored", "alice", `{"author":"mallory","reason":"spam"}`, 201, "alice"},
+		{"missing header", "", `{"author":"alice","reason":"spam"}`, 401, ""},
+		{"unknown actor", "nobody", `{"reason":"spam"}`, 404, ""},
+		{"empty reason", "alice", `{"reason":"  "}`, 400, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &reportRepositoryForum{usersRepositoryForum: newUsersRepositoryForum("alice")}
+			f := &handler{forumRepo: repo}
+			ctx := newTestCtxForum("POST", tt.actor, tt.body, map[string]string{"slug_or_id": "7"})
+
+			f.AddThreadReportForum(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", ctx.Response.StatusCode(), tt.wantStatus)
+			}
+			if tt.wantAuthor == "" {
+				if len(repo.added) != 0 {
+					t.Fatalf("report was stored: %v", repo.added)
+				}
+				return
+			}
+			if len(repo.added) != 1 || repo.added[0].Author != tt.wantAuthor || repo.added[0].Thread != 7 {
+				t.Fatalf("stored reports = %v, want one by %s on thread 7", repo.added, tt.wantAuthor)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/res"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type publishRequest struct {
+	PublishAt string `json:"publishAt"`
+}
+
+func parsePublishAtForum(publishAt string) (string, string) {
+	if publishAt == "" {
+		return "", ""
+	}
+
+	at, err := time.Parse(time.RFC3339Nano, publishAt)
+	if err != nil {
+		return "", "publishAt must be an RFC 3339 timestamp"
+	}
+	if !at.After(time.Now()) {
+		return "", "publishAt must be in the future"
+	}
+	return at.UTC().Format(time.RFC3339Nano), ""
+}
+
+func scheduleThreadForum(thread *models.Thread) string {
+	publishAt, message := parsePublishAtForum(thread.PublishAt)
+	if message != "" {
+		return message
+	}
+	if thread.Draft && publishAt != "" {
+		return "a thread can't be both a draft and scheduled"
+	}
+
+	thread.PublishAt = publishAt
+	switch {
+	case thread.Draft:
+		thread.Status, thread.StatusReason = "draft", ""
+	case publishAt != "" && thread.Status == "":
+		thread.Status = "scheduled"
+	}
+	if publishAt != "" {
+		thread.Created = publishAt
+	}
+	return ""
+}
+
+func (f *handler) GetDraftsForum(ctx *fasthttp.RequestCtx) {
+	nickname, found := ctx.UserValue("nickname").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	actorObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(actorObj.Nickname, nickname) {
+		res.SendResponse(403, res.HttpError{Message: "users can only list their own drafts"}, ctx)
+		return
+	}
+
+	limit, err := extractIntValueForum(ctx, "limit")
+	if err != nil {
+		res.SendResponse(400, res.HttpError{Message: "limit must be an integer"}, ctx)
+		return
+	}
+
+	threads, err := f.forumRepo.GetDraftsForum(actorObj.Nickname, limit)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	res.SendResponseOK(threads, ctx)
+}
+
+func (f *handler) PublishThreadForum(ctx *fasthttp.RequestCtx) {
+	actorObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	threadObj, ok := f.getVisibleThreadForum(ctx)
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(actorObj.Nickname, threadObj.Author) {
+		res.SendResponse(403, res.HttpError{Message: "only the author can publish a draft"}, ctx)
+		return
+	}
+
+	var request publishRequest
+	if len(ctx.PostBody()) > 0 {
+		if err := json.Unmarshal(ctx.PostBody(), &request); err != nil {
+			res.SendServerError(err.Error(), ctx)
+			return
+		}
+	}
+	publishAt, message := parsePublishAtForum(request.PublishAt)
+	if message != "" {
+		res.SendResponse(400, res.HttpError{Message: message}, ctx)
+		return
+	}
+
+	status := "published"
+	if publishAt != "" {
+		status = "scheduled"
+	}
+	held, err := f.premoderatedAuthorsForum(threadObj.Forum, []string{threadObj.Author})
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	if held[strings.ToLower(threadObj.Author)] {
+		status = "pending"
+	}
+
+	var thread models.Thread
+	err = f.auditedForum(ctx, "thread.publish", "thread", strconv.Itoa(int(threadObj.Id)), threadObj.Forum, threadObj,
+		func(repo forum.Repository) (interface{}, error) {
+			var err error
+			thread, err = repo.PublishDraftForum(int(threadObj.Id), status, publishAt)
+			return thread, err
+		})
+	if err == pgx.ErrNoRows {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Thread %d is not a draft", threadObj.Id),
+		}
+		res.SendResponse(409, errHTTP, ctx)
+		return
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(thread, ctx)
+}
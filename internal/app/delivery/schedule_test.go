@@ -0,0 +1,142 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+type draftsRepositoryForum struct {
+	usersRepositoryForum
+	requested []string
+}
+
+func (r *draftsRepositoryForum) GetDraftsForum(nickname string, limit int) ([]models.Thread, error) {
+	r.requested = append(r.requested, nickname)
+	return []models.Thread{{Author: nickname, Status: "draft"}}, nil
+}
+
+func TestScheduleThreadForum(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339Nano)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+
+	tests := []struct {
+		thread      models.Thread
+		wantMessage string
+		wantStatus  string
+	}{
+		{models.Thread{}, "", ""},
+		{models.Thread{Draft: true}, "", "draft"},
+		{models.Thread{Draft: true, Status: "pending", StatusReason: "premoderation"}, "", "draft"},
+		{models.Thread{PublishAt: future}, "", "scheduled"},
+		{models.Thread{PublishAt: future, Status: "pending"}, "", "pending"},
+		{models.Thread{PublishAt: past}, "publishAt must be in the future", ""},
+		{models.Thread{PublishAt: "tomorrow"}, "publishAt must be an RFC 3339 timestamp", ""},
+		{models.Thread{PublishAt: future, Draft: true}, "a thread can't be both a draft and scheduled", ""},
+	}
+
+	for _, tt := range tests {
+		thread := tt.thread
+		message := scheduleThreadForum(&thread)
+		if message != tt.wantMessage {
+			t.Fatalf("%+v: message = %q, want %q", tt.thread, message, tt.wantMessage)
+		}
+		if message != "" {
+			continue
+		}
+		if thread.Status != tt.wantStatus {
+			t.Fatalf("%+v: status = %q, want %q", tt.thread, thread.Status, tt.wantStatus)
+		}
+		if tt.thread.PublishAt != "" && (thread.PublishAt != future || thread.Created != future) {
+			t.Fatalf("%+v: publishAt = %q, created = %q, want %q", tt.thread, thread.PublishAt, thread.Created,
+				future)
+		}
+		if thread.Draft && thread.StatusReason != "" {
+			t.Fatalf("%+v: draft kept status reason %q", tt.thread, thread.StatusReason)
+		}
+	}
+}
+
+func TestParsePublishAtForumNormalizesToUTC(t *testing.T) {
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+	publishAt, message := parsePublishAtForum(at.In(time.FixedZone("UTC+3", 3*60*60)).Format(time.RFC3339))
+	if message != "" {
+		t.Fatal(message)
+	}
+	if publishAt != at.UTC().Format(time.RFC3339Nano) {
+		t.Fatalf("publishAt = %q, want %q", publishAt, at.UTC().Format(time.RFC3339Nano))
+	}
+}
+
+func TestGetDraftsForumRequiresOwner(t *testing.T) {
+	tests := []struct {
+		actor      string
+		wantStatus int
+	}{
+		{"", 401},
+		{"ghost", 404},
+		{"bob", 403},
+		{"ALICE", 200},
+	}
+
+	for _, tt := range tests {
+		repo := &draftsRepositoryForum{usersRepositoryForum: newUsersRepositoryForum("alice", "bob")}
+		f := &handler{forumRepo: repo}
+		ctx := newTestCtxForum("GET", tt.actor, "", map[string]string{"nickname": "alice"})
+
+		f.GetDraftsForum(ctx)
+
+		if ctx.Response.StatusCode() != tt.wantStatus {
+			t.Fatalf("actor %q: status = %d, want %d", tt.actor, ctx.Response.StatusCode(), tt.wantStatus)
+		}
+		if (len(repo.requested) == 1) != (tt.wantStatus == 200) {
+			t.Fatalf("actor %q: requested drafts = %v", tt.actor, repo.requested)
+		}
+	}
+}
+
+type conflictRepositoryForum struct {
+	hiddenThreadRepositoryForum
+}
+
+func (r *conflictRepositoryForum) GetThreadBySlugForum(slug string) (models.Thread, error) {
+	return r.thread, nil
+}
+
+func TestAddThreadForumConflictHidesUnpublishedThreads(t *testing.T) {
+	tests := []struct {
+		status     string
+		actor      string
+		wantThread bool
+	}{
+		{"published", "", true},
+		{"draft", "alice", true},
+		{"draft", "mallory", false},
+		{"scheduled", "", false},
+		{"pending", "mallory", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status+"/"+tt.actor, func(t *testing.T) {
+			slug := models.JsonNullString{NullString: sql.NullString{String: "taken", Valid: true}}
+			repo := &conflictRepositoryForum{hiddenThreadRepositoryForum{
+				usersRepositoryForum: newUsersRepositoryForum("alice", "mallory"),
+				thread: models.Thread{Id: 5, Slug: slug, Author: "alice", Forum: "forum", Title: "secret",
+					Status: tt.status},
+			}}
+			f := &handler{forumRepo: repo}
+
+			ctx := newTestCtxForum("POST", tt.actor, `{"author":"mallory","slug":"taken","title":"t","message":"m"}`,
+				map[string]string{"slug": "forum"})
+			f.AddThreadForum(ctx)
+			if ctx.Response.StatusCode() != 409 {
+				t.Fatalf("status = %d, want 409", ctx.Response.StatusCode())
+			}
+			if leaked := strings.Contains(string(ctx.Response.Body()), "secret"); leaked != tt.wantThread {
+				t.Fatalf("body = %s, want thread in body: %v", ctx.Response.Body(), tt.wantThread)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/app/stream"
+	"DbGODZ/internal/pkg/res"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/valyala/fasthttp"
+	"strconv"
+	"time"
+)
+
+const streamKeepAlive = 15 * time.Second
+
+func extractLastEventIDForum(ctx *fasthttp.RequestCtx) (int64, error) {
+	ValueStr := string(ctx.Request.Header.Peek("Last-Event-ID"))
+	if ValueStr == "" {
+		ValueStr = string(ctx.QueryArgs().Peek("lastEventId"))
+	}
+	if ValueStr == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(ValueStr, 10, 64)
+}
+
+func writeEventForum(w *bufio.Writer, event stream.Event) {
+	if event.Id > 0 {
+		fmt.Fprintf(w, "id: %d\n", event.Id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Name, event.Data)
+}
+
+func (f *handler) streamForum(ctx *fasthttp.RequestCtx, sub *stream.Subscription, lastEventID int64,
+	replay []models.Post) {
+	ctx.SetContentType("text/event-stream")
+	ctx.Response.Header.Set("Cache-Control", "no-cache")
+	ctx.Response.Header.Set("X-Accel-Buffering", "no")
+
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+
+		lastID := lastEventID
+		fmt.Fprintf(w, "retry: %d\n\n", 3000)
+		for _, post := range replay {
+			data, err := json.Marshal(post)
+			if err != nil {
+				return
+			}
+			writeEventForum(w, stream.Event{Id: post.Id, Name: "post.created", Data: data})
+			lastID = post.Id
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		keepAlive := time.NewTicker(streamKeepAlive)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case event, ok := <-sub.Events:
+				if !ok {
+					return
+				}
+				if event.Id > 0 && event.Id <= lastID {
+					continue
+				}
+				writeEventForum(w, event)
+			case <-keepAlive.C:
+				w.WriteString(": ping\n\n")
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func (f *handler) StreamThreadForum(ctx *fasthttp.RequestCtx) {
+	slugOrID, found := ctx.UserValue("slug_or_id").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	lastEventID, err := extractLastEventIDForum(ctx)
+	if err != nil {
+		res.SendResponse(400, res.HttpError{Message: err.Error()}, ctx)
+		return
+	}
+
+	threadObj, ok := f.lookupVisibleThreadForum(ctx, slugOrID)
+	if !ok {
+		return
+	}
+	id := int(threadObj.Id)
+
+	sub := f.streamHub.SubscribeThread(int32(id))
+
+	var replay []models.Post
+	if lastEventID > 0 {
+		replay, err = f.forumRepo.GetPostsForum(models.Thread{Id: int32(id)}, 0, int(lastEventID), "flat", false, "")
+		if err != nil {
+			sub.Close()
+			res.SendServerError(err.Error(), ctx)
+			return
+		}
+	}
+
+	f.streamForum(ctx, sub, lastEventID, replay)
+}
+
+func (f *handler) StreamForum(ctx *fasthttp.RequestCtx) {
+	slug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	lastEventID, err := extractLastEventIDForum(ctx)
+	if err != nil {
+		res.SendResponse(400, res.HttpError{Message: err.Error()}, ctx)
+		return
+	}
+
+	forumObj, err := f.forumRepo.GetBySlugForum(slug)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find forum with slug: %s", slug),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+
+	sub := f.streamHub.SubscribeForum(forumObj.Slug)
+
+	var replay []models.Post
+	if lastEventID > 0 {
+		replay, err = f.forumRepo.GetForumPostsSinceForum(forumObj.Slug, lastEventID)
+		if err != nil {
+			sub.Close()
+			res.SendServerError(err.Error(), ctx)
+			return
+		}
+	}
+
+	f.streamForum(ctx, sub, lastEventID, replay)
+}
@@ -0,0 +1,54 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/app/stream"
+	"strings"
+	"testing"
+)
+
+func TestStreamForumSkipsEventsBeforeLastEventID(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastEventID int64
+		replay      []models.Post
+		want        []string
+		notWant     []string
+	}{
+		{"empty replay", 5, nil, []string{"id: 6\n"}, []string{"id: 3\n", "id: 5\n"}},
+		{"replay moves the cursor", 2, []models.Post{{Id: 4}, {Id: 5}}, []string{"id: 4\n", "id: 5\n", "id: 6\n"},
+			[]string{"id: 3\n"}},
+		{"fresh subscription", 0, nil, []string{"id: 3\n", "id: 5\n", "id: 6\n"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := stream.NewHub(nil, nil)
+			sub := hub.SubscribeThread(1)
+			for _, id := range []int64{3, 5, 6} {
+				hub.Publish(stream.Event{Id: id, Name: "post.created", Data: []byte("{}"), Thread: 1})
+			}
+			hub.Publish(stream.Event{Name: "vote.changed", Data: []byte("{}"), Thread: 1})
+			sub.Close()
+
+			f := &handler{streamHub: hub}
+			ctx := newTestCtxForum("GET", "", "", nil)
+			f.streamForum(ctx, sub, tt.lastEventID, tt.replay)
+			body := string(ctx.Response.Body())
+
+			for _, want := range tt.want {
+				if strings.Count(body, want) != 1 {
+					t.Errorf("body has %d %q, want exactly one:\n%s", strings.Count(body, want), want, body)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(body, notWant) {
+					t.Errorf("body contains %q:\n%s", notWant, body)
+				}
+			}
+			if !strings.Contains(body, "event: vote.changed\n") {
+				t.Errorf("events without an id were dropped:\n%s", body)
+			}
+		})
+	}
+}
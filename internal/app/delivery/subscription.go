@@ -0,0 +1,170 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/res"
+	"encoding/json"
+	"fmt"
+	"github.com/valyala/fasthttp"
+	"strings"
+)
+
+func (f *handler) requireActorForum(ctx *fasthttp.RequestCtx) (models.User, bool) {
+	actor := extractActorForum(ctx)
+	if actor == "" {
+		res.SendResponse(401, res.HttpError{Message: "X-Nickname header is required"}, ctx)
+		return models.User{}, false
+	}
+
+	userObj, err := f.forumRepo.GetByNick(actor)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find user by nickname: %s", actor),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return models.User{}, false
+	}
+	return userObj, true
+}
+
+func (f *handler) subscribeThreadForum(ctx *fasthttp.RequestCtx, subscribe bool) {
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	threadObj, ok := f.getVisibleThreadForum(ctx)
+	if !ok {
+		return
+	}
+	id := int(threadObj.Id)
+
+	var err error
+	if subscribe {
+		err = f.forumRepo.AddThreadSubscriptionForum(userObj.Nickname, id)
+	} else {
+		err = f.forumRepo.DeleteThreadSubscriptionForum(userObj.Nickname, id)
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	thread, err := f.forumRepo.GetThreadByIDForum(id)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	res.SendResponseOK(thread, ctx)
+}
+
+func (f *handler) SubscribeThreadForum(ctx *fasthttp.RequestCtx) {
+	f.subscribeThreadForum(ctx, true)
+}
+
+func (f *handler) UnsubscribeThreadForum(ctx *fasthttp.RequestCtx) {
+	f.subscribeThreadForum(ctx, false)
+}
+
+func (f *handler) subscribeForumForum(ctx *fasthttp.RequestCtx, subscribe bool) {
+	slug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	forumObj, err := f.forumRepo.GetBySlugForum(slug)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find forum with slug: %s", slug),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+
+	if subscribe {
+		err = f.forumRepo.AddForumSubscriptionForum(userObj.Nickname, forumObj.Slug)
+	} else {
+		err = f.forumRepo.DeleteForumSubscriptionForum(userObj.Nickname, forumObj.Slug)
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(forumObj, ctx)
+}
+
+func (f *handler) SubscribeForum(ctx *fasthttp.RequestCtx) {
+	f.subscribeForumForum(ctx, true)
+}
+
+func (f *handler) UnsubscribeForum(ctx *fasthttp.RequestCtx) {
+	f.subscribeForumForum(ctx, false)
+}
+
+func (f *handler) MarkThreadReadForum(ctx *fasthttp.RequestCtx) {
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+
+	threadObj, ok := f.getVisibleThreadForum(ctx)
+	if !ok {
+		return
+	}
+
+	var read struct {
+		Post int64 `json:"post"`
+	}
+	if len(ctx.PostBody()) > 0 {
+		if err := json.Unmarshal(ctx.PostBody(), &read); err != nil {
+			res.SendServerError(err.Error(), ctx)
+			return
+		}
+	}
+
+	readObj, err := f.forumRepo.MarkThreadReadForum(userObj.Nickname, int(threadObj.Id), read.Post)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(readObj, ctx)
+}
+
+func (f *handler) GetSubscriptionsForum(ctx *fasthttp.RequestCtx) {
+	nickname, found := ctx.UserValue("nickname").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	userObj, ok := f.requireActorForum(ctx)
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(userObj.Nickname, nickname) {
+		res.SendResponse(403, res.HttpError{Message: "users can only read their own subscriptions"}, ctx)
+		return
+	}
+
+	limit, err := extractIntValueForum(ctx, "limit")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	subscriptions, err := f.forumRepo.GetSubscriptionsForum(userObj.Nickname, limit)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(subscriptions, ctx)
+}
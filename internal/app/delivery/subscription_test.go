@@ -0,0 +1,76 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/app/views"
+	"testing"
+)
+
+type subscriptionRepositoryForum struct {
+	usersRepositoryForum
+	listed []string
+	read   []string
+}
+
+func (r *subscriptionRepositoryForum) GetSubscriptionsForum(nickname string, limit int) (models.Subscriptions, error) {
+	r.listed = append(r.listed, nickname)
+	return models.Subscriptions{Forums: []models.Forum{}, Threads: []models.Thread{}}, nil
+}
+
+func (r *subscriptionRepositoryForum) GetThreadByIDForum(id int) (models.Thread, error) {
+	return models.Thread{Id: int32(id), Author: "bob", Status: "published"}, nil
+}
+
+func (r *subscriptionRepositoryForum) GetPostsForum(thread models.Thread, limit, since int, sort string, desc bool, viewer string) ([]models.Post, error) {
+	return []models.Post{{Id: 7, Thread: 1, Author: "bob", Status: "published"}}, nil
+}
+
+func (r *subscriptionRepositoryForum) MarkThreadReadForum(nickname string, threadID int, postID int64) (models.ThreadRead, error) {
+	r.read = append(r.read, nickname)
+	return models.ThreadRead{}, nil
+}
+
+func TestGetSubscriptionsForumRequiresOwner(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      string
+		nickname   string
+		wantStatus int
+	}{
+		{"owner", "alice", "alice", 200},
+		{"owner with different case", "ALICE", "alice", 200},
+		{"another user", "mallory", "alice", 403},
+		{"missing header", "", "alice", 401},
+		{"unknown actor", "nobody", "alice", 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &subscriptionRepositoryForum{usersRepositoryForum: newUsersRepositoryForum("alice", "mallory")}
+			f := &handler{forumRepo: repo}
+
+			ctx := newTestCtxForum("GET", tt.actor, "", map[string]string{"nickname": tt.nickname})
+			f.GetSubscriptionsForum(ctx)
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", ctx.Response.StatusCode(), tt.wantStatus)
+			}
+			if (tt.wantStatus == 200) != (len(repo.listed) == 1) {
+				t.Fatalf("subscriptions listed for %v", repo.listed)
+			}
+		})
+	}
+}
+
+func TestGetPostsForumDoesNotMarkRead(t *testing.T) {
+	repo := &subscriptionRepositoryForum{usersRepositoryForum: newUsersRepositoryForum("alice")}
+	f := &handler{forumRepo: repo, views: views.NewCounter(repo)}
+
+	ctx := newTestCtxForum("GET", "alice", "", map[string]string{"slug_or_id": "1"})
+	f.GetPostsSlugForum(ctx)
+	if ctx.Response.StatusCode() != 200 {
+		t.Fatalf("status = %d, body = %q", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if len(repo.read) != 0 {
+		t.Fatalf("GET posts marked the thread read for %v", repo.read)
+	}
+}
@@ -0,0 +1,71 @@
+package delivery
+
+import (
+	"DbGODZ/internal/pkg/res"
+	"fmt"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"regexp"
+	"strings"
+)
+
+const maxThreadTags = 10
+
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}_-]{1,32}$`)
+
+func normalizeTagsForum(tags []string) ([]string, string) {
+	if tags == nil {
+		return nil, ""
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Sprintf("invalid tag: %q", tag)
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > maxThreadTags {
+		return nil, fmt.Sprintf("thread can have at most %d tags", maxThreadTags)
+	}
+	return normalized, ""
+}
+
+func (f *handler) GetTagsForum(ctx *fasthttp.RequestCtx) {
+	limit, err := extractIntValueForum(ctx, "limit")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	forumSlug := string(ctx.QueryArgs().Peek("forum"))
+	if forumSlug != "" {
+		_, err = f.forumRepo.GetBySlugForum(forumSlug)
+		if err == pgx.ErrNoRows {
+			errHTTP := res.HttpError{
+				Message: fmt.Sprintf("Can't find forum with slug: %s", forumSlug),
+			}
+			res.SendResponse(404, errHTTP, ctx)
+			return
+		}
+		if err != nil {
+			res.SendServerError(err.Error(), ctx)
+			return
+		}
+	}
+
+	tags, err := f.forumRepo.GetTagsForum(forumSlug, limit)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(tags, ctx)
+}
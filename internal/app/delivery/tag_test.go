@@ -0,0 +1,44 @@
+package delivery
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTagsForum(t *testing.T) {
+	tooMany := make([]string, maxThreadTags+1)
+	for i := range tooMany {
+		tooMany[i] = "tag" + string(rune('a'+i))
+	}
+
+	tests := []struct {
+		name  string
+		tags  []string
+		want  []string
+		valid bool
+	}{
+		{"nil keeps tags unchanged", nil, nil, true},
+		{"empty clears tags", []string{}, []string{}, true},
+		{"lowercased and trimmed", []string{" Go ", "PostgreSQL"}, []string{"go", "postgresql"}, true},
+		{"duplicates collapse", []string{"go", "GO", "Go"}, []string{"go"}, true},
+		{"unicode letters", []string{"мысли", "c_sharp", "x-1"}, []string{"мысли", "c_sharp", "x-1"}, true},
+		{"spaces inside", []string{"two words"}, nil, false},
+		{"punctuation", []string{"<script>"}, nil, false},
+		{"empty tag", []string{"  "}, nil, false},
+		{"too long", []string{strings.Repeat("a", 33)}, nil, false},
+		{"too many", tooMany, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, message := normalizeTagsForum(tt.tags)
+			if (message == "") != tt.valid {
+				t.Fatalf("normalizeTagsForum(%q) message = %q, want valid %t", tt.tags, message, tt.valid)
+			}
+			if tt.valid && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("normalizeTagsForum(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/app/views"
+	"database/sql"
+	"github.com/jackc/pgx"
+	"strings"
+	"testing"
+)
+
+type threadsRepositoryForum struct {
+	forum.Repository
+	filters []models.ThreadFilter
+}
+
+func (r *threadsRepositoryForum) GetThreadsForum(filter models.ThreadFilter) ([]models.Thread, error) {
+	r.filters = append(r.filters, filter)
+	return []models.Thread{{Id: 1, Forum: filter.Forum}}, nil
+}
+
+func TestGetThreadsForumSortParameters(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantWindow string
+	}{
+		{"sort=hot", 200, ""},
+		{"sort=top&window=week", 200, "7 days"},
+		{"sort=top", 200, ""},
+		{"sort=top&window=decade", 400, ""},
+		{"sort=newest", 400, ""},
+		{"since=2020-01-01T00:00:00Z", 200, ""},
+		{"sort=created&since=2020-01-01T00:00:00Z", 200, ""},
+		{"sort=hot&since=2020-01-01T00:00:00Z", 400, ""},
+		{"sort=views&since=2020-01-01T00:00:00Z", 400, ""},
+		{"sort=active&offset=20", 200, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			repo := &threadsRepositoryForum{}
+			f := &handler{forumRepo: repo}
+			ctx := newTestCtxForum("GET", "", "", map[string]string{"slug": "forum"})
+			ctx.Request.URI().SetQueryString(tt.query)
+
+			f.GetThreadsForum(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", ctx.Response.StatusCode(), tt.wantStatus, ctx.Response.Body())
+			}
+			if tt.wantStatus != 200 {
+				if len(repo.filters) != 0 {
+					t.Fatalf("repository was queried with %v", repo.filters)
+				}
+				return
+			}
+			if len(repo.filters) != 1 || repo.filters[0].Window != tt.wantWindow {
+				t.Fatalf("filters = %v, want window %q", repo.filters, tt.wantWindow)
+			}
+		})
+	}
+}
+
+type threadDetailsRepositoryForum struct {
+	forum.Repository
+	thread models.Thread
+}
+
+func (r *threadDetailsRepositoryForum) GetThreadByIDForum(id int) (models.Thread, error) {
+	return r.thread, nil
+}
+
+func TestGetThreadDetailsForumETagIgnoresViews(t *testing.T) {
+	repo := &threadDetailsRepositoryForum{thread: models.Thread{Id: 3, Author: "alice", Title: "title", Votes: 1,
+		Status: "published"}}
+	f := &handler{forumRepo: repo, views: views.NewCounter(repo)}
+
+	etags := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			repo.thread.Votes++
+		}
+		ctx := newTestCtxForum("GET", "", "", map[string]string{"slug_or_id": "3"})
+		f.GetThreadDetailsSlugForum(ctx)
+		if ctx.Response.StatusCode() != 200 {
+			t.Fatalf("status = %d, want 200", ctx.Response.StatusCode())
+		}
+		var threadObj models.Thread
+		decodeResponseForum(t, ctx, &threadObj)
+		if threadObj.Views != int64(i+1) {
+			t.Fatalf("views = %d, want %d", threadObj.Views, i+1)
+		}
+		if len(ctx.Response.Header.Peek("Last-Modified")) != 0 {
+			t.Fatalf("Last-Modified = %q, want none for threads", ctx.Response.Header.Peek("Last-Modified"))
+		}
+		etags = append(etags, string(ctx.Response.Header.Peek("ETag")))
+	}
+
+	if !strings.HasPrefix(etags[0], "W/") || etags[0] != etags[1] {
+		t.Fatalf("ETags = %q, want a weak ETag that views leave unchanged", etags)
+	}
+	if etags[2] == etags[1] {
+		t.Fatalf("ETags = %q, want a vote to change the ETag", etags)
+	}
+}
+
+type postsRepositoryForum struct {
+	forum.Repository
+	threads map[int]models.Thread
+	posts   map[int][]models.Post
+}
+
+func (r *postsRepositoryForum) GetThreadIDBySlugForum(slug string) (int, error) {
+	for id, threadObj := range r.threads {
+		if threadObj.Slug.String == slug {
+			return id, nil
+		}
+	}
+	return 0, pgx.ErrNoRows
+}
+
+func (r *postsRepositoryForum) GetThreadByIDForum(id int) (models.Thread, error) {
+	threadObj, ok := r.threads[id]
+	if !ok {
+		return models.Thread{}, pgx.ErrNoRows
+	}
+	return threadObj, nil
+}
+
+func (r *postsRepositoryForum) GetPostsForum(thread models.Thread, limit, since int, sort string, desc bool,
+	viewer string) ([]models.Post, error) {
+	return r.posts[int(thread.Id)], nil
+}
+
+func TestGetPostsForumCountsOneViewPerListing(t *testing.T) {
+	slug := models.JsonNullString{NullString: sql.NullString{String: "busy", Valid: true}}
+	repo := &postsRepositoryForum{
+		threads: map[int]models.Thread{
+			1: {Id: 1, Slug: slug, Status: "published"},
+			2: {Id: 2, Status: "published"},
+		},
+		posts: map[int][]models.Post{1: {{Id: 10, Thread: 1, Status: "published"}}},
+	}
+
+	tests := []struct {
+		slugOrID   string
+		wantStatus int
+		wantThread int32
+	}{
+		{"busy", 200, 1},
+		{"1", 200, 1},
+		{"2", 200, 2},
+		{"3", 404, 0},
+		{"missing", 404, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.slugOrID, func(t *testing.T) {
+			counter := views.NewCounter(repo)
+			f := &handler{forumRepo: repo, views: counter}
+
+			ctx := newTestCtxForum("GET", "", "", map[string]string{"slug_or_id": tt.slugOrID})
+			f.GetPostsSlugForum(ctx)
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", ctx.Response.StatusCode(), tt.wantStatus)
+			}
+			for id := int32(1); id <= 3; id++ {
+				want := int64(0)
+				if id == tt.wantThread {
+					want = 1
+				}
+				if counter.Pending(id) != want {
+					t.Fatalf("thread %d views = %d, want %d", id, counter.Pending(id), want)
+				}
+			}
+		})
+	}
+}
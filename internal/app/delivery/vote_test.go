@@ -0,0 +1,50 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+type voteRepositoryForum struct {
+	forum.Repository
+	votes []models.Vote
+}
+
+func (r *voteRepositoryForum) VoteForum(vote models.Vote, thread models.Thread) (models.Thread, error) {
+	r.votes = append(r.votes, vote)
+	thread.Votes = vote.Voice
+	return thread, nil
+}
+
+func (r *voteRepositoryForum) GetThreadByIDForum(id int) (models.Thread, error) {
+	return models.Thread{Id: int32(id), Author: "bob", Forum: "forum", Status: "published"}, nil
+}
+
+func TestAddVoteIDForumVoiceRange(t *testing.T) {
+	tests := []struct {
+		body       string
+		wantStatus int
+	}{
+		{`{"nickname":"alice","voice":1}`, 200},
+		{`{"nickname":"alice","voice":-1}`, 200},
+		{`{"nickname":"alice","voice":0}`, 200},
+		{`{"nickname":"alice","voice":2}`, 400},
+		{`{"nickname":"alice","voice":-5}`, 400},
+	}
+
+	for _, tt := range tests {
+		repo := &voteRepositoryForum{}
+		f := &handler{forumRepo: repo}
+		ctx := newTestCtxForum("POST", "", tt.body, map[string]string{"id": "4"})
+
+		f.AddVoteIDForum(ctx)
+
+		if ctx.Response.StatusCode() != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", tt.body, ctx.Response.StatusCode(), tt.wantStatus)
+		}
+		if (len(repo.votes) == 1) != (tt.wantStatus == 200) {
+			t.Fatalf("%s: stored votes = %v", tt.body, repo.votes)
+		}
+	}
+}
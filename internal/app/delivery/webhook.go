@@ -0,0 +1,249 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/app/webhook"
+	"DbGODZ/internal/pkg/res"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx"
+	"github.com/valyala/fasthttp"
+	"net/url"
+	"strconv"
+)
+
+func validateWebhookForum(newWebhook models.Webhook) error {
+	target, err := url.Parse(newWebhook.Url)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) address: %s", newWebhook.Url)
+	}
+	if err = webhook.CheckHost(target.Hostname()); err != nil {
+		return err
+	}
+	if len(newWebhook.Events) == 0 {
+		return fmt.Errorf("at least one event is required, one of %v", webhook.Events)
+	}
+	for _, event := range newWebhook.Events {
+		known := false
+		for _, name := range webhook.Events {
+			if event == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown event %s, expected one of %v", event, webhook.Events)
+		}
+	}
+	return nil
+}
+
+func (f *handler) getOwnedForum(ctx *fasthttp.RequestCtx, slug string) (models.Forum, bool) {
+	forumObj, err := f.forumRepo.GetBySlugForum(slug)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find forum with slug: %s", slug),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return forumObj, false
+	}
+	return forumObj, checkOwnerForum(ctx, forumObj)
+}
+
+func (f *handler) AddWebhookForum(ctx *fasthttp.RequestCtx) {
+	slug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	forumObj, ok := f.getOwnedForum(ctx, slug)
+	if !ok {
+		return
+	}
+
+	var newWebhook models.Webhook
+	err := json.Unmarshal(ctx.PostBody(), &newWebhook)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	newWebhook.Forum = forumObj.Slug
+
+	if err = validateWebhookForum(newWebhook); err != nil {
+		res.SendResponse(400, res.HttpError{Message: err.Error()}, ctx)
+		return
+	}
+	if newWebhook.Secret == "" {
+		buf := make([]byte, 32)
+		if _, err = rand.Read(buf); err != nil {
+			res.SendServerError(err.Error(), ctx)
+			return
+		}
+		newWebhook.Secret = hex.EncodeToString(buf)
+	}
+
+	auditObj, err := newAuditForum(ctx, "webhook.create", "webhook", "", forumObj.Slug, nil)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	var webhookDB models.Webhook
+	err = f.forumRepo.WithAuditForum(&auditObj, func(repo forum.Repository) (interface{}, error) {
+		var err error
+		webhookDB, err = repo.AddWebhookForum(newWebhook)
+		auditObj.TargetId = strconv.Itoa(int(webhookDB.Id))
+		return models.Webhook{Events: webhookDB.Events, Forum: webhookDB.Forum, Id: webhookDB.Id,
+			Url: webhookDB.Url}, err
+	})
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponse(201, webhookDB, ctx)
+}
+
+func (f *handler) GetWebhooksForum(ctx *fasthttp.RequestCtx) {
+	slug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	forumObj, ok := f.getOwnedForum(ctx, slug)
+	if !ok {
+		return
+	}
+
+	webhooks, err := f.forumRepo.GetWebhooksForum(forumObj.Slug)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	res.SendResponseOK(webhooks, ctx)
+}
+
+func (f *handler) DeleteWebhookForum(ctx *fasthttp.RequestCtx) {
+	ValueStr, found := ctx.UserValue("id").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	id, err := strconv.Atoi(ValueStr)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	webhookObj, err := f.forumRepo.GetWebhookForum(id)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find webhook with id: %d", id),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	if _, ok := f.getOwnedForum(ctx, webhookObj.Forum); !ok {
+		return
+	}
+
+	webhookObj.Secret = ""
+	err = f.auditedForum(ctx, "webhook.delete", "webhook", ValueStr, webhookObj.Forum, webhookObj,
+		func(repo forum.Repository) (interface{}, error) {
+			return nil, repo.DeleteWebhookForum(id)
+		})
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(webhookObj, ctx)
+}
+
+func (f *handler) GetDeadDeliveriesForum(ctx *fasthttp.RequestCtx) {
+	slug, found := ctx.UserValue("slug").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	forumObj, ok := f.getOwnedForum(ctx, slug)
+	if !ok {
+		return
+	}
+
+	limit, err := extractIntValueForum(ctx, "limit")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	since, err := extractIntValueForum(ctx, "since")
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	deliveries, err := f.forumRepo.GetDeadDeliveriesForum(forumObj.Slug, limit, int64(since))
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(deliveries, ctx)
+}
+
+func (f *handler) RetryDeliveryForum(ctx *fasthttp.RequestCtx) {
+	ValueStr, found := ctx.UserValue("id").(string)
+	if !found {
+		res.SendResponse(400, "bad request", ctx)
+		return
+	}
+
+	id, err := strconv.ParseInt(ValueStr, 10, 64)
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	deliveryObj, err := f.forumRepo.GetDeliveryForum(id)
+	if err != nil {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Can't find delivery with id: %d", id),
+		}
+		res.SendResponse(404, errHTTP, ctx)
+		return
+	}
+	webhookObj, err := f.forumRepo.GetWebhookForum(int(deliveryObj.Webhook))
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+	if _, ok := f.getOwnedForum(ctx, webhookObj.Forum); !ok {
+		return
+	}
+
+	deliveryObj, err = f.forumRepo.RetryDeliveryForum(id)
+	if err == pgx.ErrNoRows {
+		errHTTP := res.HttpError{
+			Message: fmt.Sprintf("Delivery %d is not dead-lettered", id),
+		}
+		res.SendResponse(409, errHTTP, ctx)
+		return
+	}
+	if err != nil {
+		res.SendServerError(err.Error(), ctx)
+		return
+	}
+
+	res.SendResponseOK(deliveryObj, ctx)
+}
@@ -0,0 +1,32 @@
+package delivery
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+func TestValidateWebhookForum(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		valid bool
+	}{
+		{"public address", "https://93.184.216.34/hook", true},
+		{"relative url", "/hook", false},
+		{"unsupported scheme", "ftp://93.184.216.34/hook", false},
+		{"localhost", "http://localhost:8080/hook", false},
+		{"loopback", "http://127.0.0.1/hook", false},
+		{"private network", "http://192.168.0.10/hook", false},
+		{"metadata service", "http://169.254.169.254/latest/meta-data", false},
+		{"ipv6 loopback", "http://[::1]:5000/hook", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWebhookForum(models.Webhook{Url: tt.url, Events: []string{"post.created"}})
+			if (err == nil) != tt.valid {
+				t.Fatalf("validateWebhookForum(%q) = %v, want valid %t", tt.url, err, tt.valid)
+			}
+		})
+	}
+}
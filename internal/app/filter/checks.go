@@ -0,0 +1,181 @@
+package filter
+
+import (
+	forum "DbGODZ/internal/app"
+	"fmt"
+	"github.com/jackc/pgx"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+type Config struct {
+	MaxLinks        int
+	LinkAccountAge  time.Duration
+	DuplicateWindow time.Duration
+}
+
+var DefaultConfig = Config{
+	MaxLinks:        2,
+	LinkAccountAge:  24 * time.Hour,
+	DuplicateWindow: 10 * time.Minute,
+}
+
+func DefaultPipeline(fr forum.Repository, config Config) *Pipeline {
+	pipeline := NewPipeline(NewBlocklistCheck(fr))
+	if config.LinkAccountAge > 0 {
+		pipeline.Use(NewLinkCheck(fr, config.MaxLinks, config.LinkAccountAge))
+	}
+	if config.DuplicateWindow > 0 {
+		pipeline.Use(NewDuplicateCheck(fr, config.DuplicateWindow))
+	}
+	return pipeline
+}
+
+func NormalizeText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+type BlocklistCheck struct {
+	forumRepo forum.Repository
+}
+
+func NewBlocklistCheck(fr forum.Repository) *BlocklistCheck {
+	return &BlocklistCheck{forumRepo: fr}
+}
+
+func (c *BlocklistCheck) Check(contents []Content) ([]Verdict, error) {
+	verdicts := make([]Verdict, len(contents))
+	blocklists := make(map[string][]string)
+	actions := make(map[string]Action)
+
+	for i, content := range contents {
+		slug := strings.ToLower(content.Forum)
+		words, ok := blocklists[slug]
+		if !ok {
+			blocked, err := c.forumRepo.GetBlockedWordsForum(content.Forum)
+			if err != nil {
+				return nil, err
+			}
+			for _, word := range blocked {
+				words = append(words, word.Word)
+				if word.Action == "hold" {
+					actions[slug+" "+word.Word] = Hold
+				} else {
+					actions[slug+" "+word.Word] = Reject
+				}
+			}
+			blocklists[slug] = words
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		text := " " + NormalizeText(content.Title+" "+content.Message) + " "
+		for _, word := range words {
+			action := actions[slug+" "+word]
+			if action > verdicts[i].Action && strings.Contains(text, " "+word+" ") {
+				verdicts[i] = Verdict{
+					Action: action,
+					Code:   "blocked_word",
+					Reason: fmt.Sprintf("%s contains a word blocked in forum %s", content.Kind, content.Forum),
+				}
+			}
+		}
+	}
+	return verdicts, nil
+}
+
+type LinkCheck struct {
+	forumRepo forum.Repository
+	maxLinks  int
+	minAge    time.Duration
+}
+
+func NewLinkCheck(fr forum.Repository, maxLinks int, minAge time.Duration) *LinkCheck {
+	return &LinkCheck{forumRepo: fr, maxLinks: maxLinks, minAge: minAge}
+}
+
+func (c *LinkCheck) Check(contents []Content) ([]Verdict, error) {
+	verdicts := make([]Verdict, len(contents))
+	ages := make(map[string]time.Duration)
+
+	for i, content := range contents {
+		links := len(linkPattern.FindAllString(content.Title+" "+content.Message, -1))
+		if links <= c.maxLinks {
+			continue
+		}
+
+		author := strings.ToLower(content.Author)
+		age, ok := ages[author]
+		if !ok {
+			var err error
+			age, err = c.forumRepo.GetAccountAgeForum(content.Author)
+			if err == pgx.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			ages[author] = age
+		}
+		if age < c.minAge {
+			verdicts[i] = Verdict{
+				Action: Hold,
+				Code:   "too_many_links",
+				Reason: fmt.Sprintf("new accounts may post at most %d links", c.maxLinks),
+			}
+		}
+	}
+	return verdicts, nil
+}
+
+type DuplicateCheck struct {
+	forumRepo forum.Repository
+	window    time.Duration
+}
+
+func NewDuplicateCheck(fr forum.Repository, window time.Duration) *DuplicateCheck {
+	return &DuplicateCheck{forumRepo: fr, window: window}
+}
+
+func (c *DuplicateCheck) Check(contents []Content) ([]Verdict, error) {
+	verdicts := make([]Verdict, len(contents))
+	duplicate := Verdict{
+		Action: Reject,
+		Code:   "duplicate_message",
+		Reason: "the same message was already posted recently",
+	}
+
+	authors := make([]string, len(contents))
+	messages := make([]string, len(contents))
+	excluded := make([]int64, len(contents))
+	seen := make(map[string]bool)
+	for i, content := range contents {
+		authors[i] = content.Author
+		messages[i] = content.Message
+		excluded[i] = content.Post
+
+		key := strings.ToLower(content.Author) + "\x00" + content.Message
+		if seen[key] {
+			verdicts[i] = duplicate
+		}
+		seen[key] = true
+	}
+
+	indexes, err := c.forumRepo.FindDuplicateMessagesForum(authors, messages, excluded, c.window)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indexes {
+		if i >= 0 && i < len(verdicts) {
+			verdicts[i] = duplicate
+		}
+	}
+	return verdicts, nil
+}
@@ -0,0 +1,173 @@
+package filter
+
+import (
+	forum "DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"github.com/jackc/pgx"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type filterRepository struct {
+	forum.Repository
+	words      map[string][]models.BlockedWord
+	ages       map[string]time.Duration
+	duplicates []int
+	window     time.Duration
+}
+
+func (r *filterRepository) GetBlockedWordsForum(slug string) ([]models.BlockedWord, error) {
+	return r.words[strings.ToLower(slug)], nil
+}
+
+func (r *filterRepository) GetAccountAgeForum(nickname string) (time.Duration, error) {
+	age, ok := r.ages[strings.ToLower(nickname)]
+	if !ok {
+		return 0, pgx.ErrNoRows
+	}
+	return age, nil
+}
+
+func (r *filterRepository) FindDuplicateMessagesForum(authors, messages []string, excluded []int64,
+	window time.Duration) ([]int, error) {
+	r.window = window
+	return r.duplicates, nil
+}
+
+func actionsOf(verdicts []Verdict) []Action {
+	actions := make([]Action, len(verdicts))
+	for i, verdict := range verdicts {
+		actions[i] = verdict.Action
+	}
+	return actions
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"Hello, World!", "hello world"},
+		{"  spaced\tout\nwords  ", "spaced out words"},
+		{"S.P.A.M", "s p a m"},
+		{"Привет, МИР", "привет мир"},
+		{"x1 — 42%", "x1 42"},
+		{"!!!", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeText(tt.text); got != tt.want {
+			t.Fatalf("NormalizeText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBlocklistCheckWordBoundaries(t *testing.T) {
+	repo := &filterRepository{words: map[string][]models.BlockedWord{
+		"forum": {{Word: "spam", Action: "reject"}, {Word: "buy now", Action: "hold"}},
+	}}
+	check := NewBlocklistCheck(repo)
+
+	tests := []struct {
+		title   string
+		message string
+		forum   string
+		want    Action
+	}{
+		{"", "this is spam", "forum", Reject},
+		{"", "SPAM!", "Forum", Reject},
+		{"Spam", "title only", "forum", Reject},
+		{"", "spammer and antispam", "forum", Allow},
+		{"", "buy now, cheap", "forum", Hold},
+		{"", "buy nowhere", "forum", Allow},
+		{"", "buy   now", "forum", Hold},
+		{"", "spam and buy now", "forum", Reject},
+		{"", "this is spam", "other", Allow},
+	}
+
+	for _, tt := range tests {
+		verdicts, err := check.Check([]Content{{Forum: tt.forum, Kind: "post", Message: tt.message, Title: tt.title}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verdicts[0].Action != tt.want {
+			t.Fatalf("%q in %s: action = %d, want %d", tt.title+" "+tt.message, tt.forum, verdicts[0].Action, tt.want)
+		}
+	}
+}
+
+func TestLinkCheckHoldsNewAccounts(t *testing.T) {
+	repo := &filterRepository{ages: map[string]time.Duration{"new": time.Hour, "old": 48 * time.Hour}}
+	check := NewLinkCheck(repo, 2, 24*time.Hour)
+	links := "https://a.example www.b.example http://c.example"
+
+	verdicts, err := check.Check([]Content{
+		{Author: "new", Message: links},
+		{Author: "new", Message: "https://a.example www.b.example"},
+		{Author: "old", Message: links},
+		{Author: "ghost", Message: links},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := actionsOf(verdicts), []Action{Hold, Allow, Allow, Allow}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("actions = %v, want %v", got, want)
+	}
+}
+
+func TestDuplicateCheckMapsIndexes(t *testing.T) {
+	repo := &filterRepository{duplicates: []int{2, -1, 7}}
+	check := NewDuplicateCheck(repo, 5*time.Minute)
+
+	verdicts, err := check.Check([]Content{
+		{Author: "alice", Message: "one"},
+		{Author: "bob", Message: "one"},
+		{Author: "alice", Message: "two"},
+		{Author: "Alice", Message: "one"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := actionsOf(verdicts), []Action{Allow, Allow, Reject, Reject}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("actions = %v, want %v", got, want)
+	}
+	if repo.window != 5*time.Minute {
+		t.Fatalf("window = %s, want 5m", repo.window)
+	}
+}
+
+func TestDefaultPipelineConfig(t *testing.T) {
+	repo := &filterRepository{
+		words:      map[string][]models.BlockedWord{"forum": {{Word: "spam", Action: "reject"}}},
+		ages:       map[string]time.Duration{"new": time.Minute},
+		duplicates: []int{0},
+	}
+	contents := []Content{{Author: "new", Forum: "forum", Message: "https://a.example https://b.example"}}
+
+	verdicts, err := DefaultPipeline(repo, Config{}).Run(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdicts[0].Action != Allow {
+		t.Fatalf("zero config action = %d, want only the blocklist to run", verdicts[0].Action)
+	}
+
+	verdicts, err = DefaultPipeline(repo, Config{MaxLinks: 1, LinkAccountAge: time.Hour}).Run(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdicts[0].Code != "too_many_links" {
+		t.Fatalf("link config verdict = %v, want too_many_links", verdicts[0])
+	}
+
+	verdicts, err = DefaultPipeline(repo, DefaultConfig).Run(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdicts[0].Code != "duplicate_message" {
+		t.Fatalf("default config verdict = %v, want duplicate_message", verdicts[0])
+	}
+}
@@ -0,0 +1,75 @@
+package filter
+
+type Action int
+
+const (
+	Allow Action = iota
+	Hold
+	Reject
+)
+
+type Content struct {
+	Author  string
+	Forum   string
+	Kind    string
+	Message string
+	Post    int64
+	Thread  int32
+	Title   string
+}
+
+type Verdict struct {
+	Action Action `json:"-"`
+	Code   string `json:"code"`
+	Reason string `json:"message"`
+}
+
+type Check interface {
+	Check(contents []Content) ([]Verdict, error)
+}
+
+type CheckFunc func(content Content) (Verdict, error)
+
+func (fn CheckFunc) Check(contents []Content) ([]Verdict, error) {
+	verdicts := make([]Verdict, len(contents))
+	for i, content := range contents {
+		verdict, err := fn(content)
+		if err != nil {
+			return nil, err
+		}
+		verdicts[i] = verdict
+	}
+	return verdicts, nil
+}
+
+type Pipeline struct {
+	checks []Check
+}
+
+func NewPipeline(checks ...Check) *Pipeline {
+	return &Pipeline{checks: checks}
+}
+
+func (p *Pipeline) Use(check Check) {
+	p.checks = append(p.checks, check)
+}
+
+func (p *Pipeline) Run(contents []Content) ([]Verdict, error) {
+	verdicts := make([]Verdict, len(contents))
+	if p == nil {
+		return verdicts, nil
+	}
+
+	for _, check := range p.checks {
+		results, err := check.Check(contents)
+		if err != nil {
+			return nil, err
+		}
+		for i, result := range results {
+			if i < len(verdicts) && result.Action > verdicts[i].Action {
+				verdicts[i] = result
+			}
+		}
+	}
+	return verdicts, nil
+}
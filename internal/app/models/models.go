@@ -7,41 +7,86 @@ import (
 )
 
 type Forum struct {
-	Posts   int64  `json:"posts"`
-	Slug    string `json:"slug"`
-	Threads int32  `json:"threads"`
-	Title   string `json:"title"`
-	User    string `json:"user"`
+	Breadcrumbs  []ForumCrumb `json:"breadcrumbs,omitempty"`
+	Children     []Forum      `json:"children,omitempty"`
+	Depth        int32        `json:"depth"`
+	Parent       string       `json:"parent,omitempty"`
+	Position     int32        `json:"position"`
+	Posts        int64        `json:"posts"`
+	Premoderated bool         `json:"premoderated"`
+	Slug         string       `json:"slug"`
+	SlowMode     int32        `json:"slowMode"`
+	Threads      int32        `json:"threads"`
+	Title        string       `json:"title"`
+	User         string       `json:"user"`
+}
+
+type ForumCrumb struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
 }
 
 type Thread struct {
-	Author  string         `json:"author"`
-	Created string         `json:"created"`
-	Forum   string         `json:"forum"`
-	Id      int32          `json:"id"`
-	Message string         `json:"message"`
-	Slug    JsonNullString `json:"slug"`
-	Title   string         `json:"title"`
-	Votes   int32          `json:"votes"`
+	Author       string         `json:"author"`
+	Created      string         `json:"created"`
+	Draft        bool           `json:"draft,omitempty"`
+	Edited       string         `json:"edited,omitempty"`
+	Forum        string         `json:"forum"`
+	Id           int32          `json:"id"`
+	LastPostAt   string         `json:"lastPostAt,omitempty"`
+	Message      string         `json:"message"`
+	MessageHtml  string         `json:"messageHtml"`
+	Poll         *Poll          `json:"poll,omitempty"`
+	Posts        int32          `json:"posts"`
+	PublishAt    string         `json:"publishAt,omitempty"`
+	Slug         JsonNullString `json:"slug"`
+	Status       string         `json:"status"`
+	StatusReason string         `json:"statusReason,omitempty"`
+	Tags         []string       `json:"tags,omitempty"`
+	Title        string         `json:"title"`
+	Unread       *int64         `json:"unread,omitempty"`
+	Views        int64          `json:"views"`
+	Votes        int32          `json:"votes"`
+}
+
+type ThreadFilter struct {
+	Desc     bool
+	Forum    string
+	Limit    int
+	Nickname string
+	Offset   int
+	Since    string
+	Sort     string
+	Tag      string
+	Window   string
 }
 
 type User struct {
 	About    string `json:"about"`
 	Email    string `json:"email"`
 	FullName string `json:"fullname"`
+	Karma    int64  `json:"karma"`
 	Nickname string `json:"nickname"`
 }
 
 type Post struct {
-	Author   string           `json:"author"`
-	Created  string           `json:"created"`
-	Forum    string           `json:"forum"`
-	Id       int64            `json:"id"`
-	IsEdited bool             `json:"isEdited"`
-	Message  string           `json:"message"`
-	Parent   JsonNullInt64    `json:"parent"`
-	Thread   int32            `json:"thread"`
-	Path     pgtype.Int8Array `json:"-"`
+	Attachments  []Attachment     `json:"attachments,omitempty"`
+	Author       string           `json:"author"`
+	Created      string           `json:"created"`
+	Edited       string           `json:"edited,omitempty"`
+	Forum        string           `json:"forum"`
+	Hidden       bool             `json:"hidden,omitempty"`
+	Id           int64            `json:"id"`
+	IsEdited     bool             `json:"isEdited"`
+	Message      string           `json:"message"`
+	MessageHtml  string           `json:"messageHtml"`
+	Parent       JsonNullInt64    `json:"parent"`
+	Reactions    map[string]int32 `json:"reactions"`
+	Score        int32            `json:"score"`
+	Status       string           `json:"status"`
+	StatusReason string           `json:"statusReason,omitempty"`
+	Thread       int32            `json:"thread"`
+	Path         pgtype.Int8Array `json:"-"`
 }
 
 type Vote struct {
@@ -101,3 +146,207 @@ func (v *JsonNullString) UnmarshalJSON(data []byte) error {
 	}
 	return nil
 }
+
+type Report struct {
+	Author  string                 `json:"author"`
+	Closed  JsonNullString         `json:"closedBy"`
+	Content map[string]interface{} `json:"content,omitempty"`
+	Created string                 `json:"created"`
+	Forum   string                 `json:"forum"`
+	Id      int64                  `json:"id"`
+	Post    JsonNullInt64          `json:"post"`
+	Reason  string                 `json:"reason"`
+	Status  string                 `json:"status"`
+	Thread  int32                  `json:"thread"`
+}
+
+type Moderator struct {
+	Forum    string `json:"forum"`
+	Nickname string `json:"nickname"`
+}
+
+type Audit struct {
+	Action     string          `json:"action"`
+	Actor      JsonNullString  `json:"actor"`
+	After      json.RawMessage `json:"after"`
+	Anonymous  bool            `json:"anonymous"`
+	Before     json.RawMessage `json:"before"`
+	Created    string          `json:"created"`
+	Forum      JsonNullString  `json:"forum"`
+	Id         int64           `json:"id"`
+	RequestId  string          `json:"requestId"`
+	TargetId   string          `json:"targetId"`
+	TargetType string          `json:"targetType"`
+}
+
+type AuditFilter struct {
+	Action     string
+	Actor      string
+	Forum      string
+	From       string
+	TargetId   string
+	TargetType string
+	To         string
+	Limit      int
+	Since      int64
+	Desc       bool
+}
+
+type Webhook struct {
+	Created string   `json:"created"`
+	Events  []string `json:"events"`
+	Forum   string   `json:"forum"`
+	Id      int32    `json:"id"`
+	Secret  string   `json:"secret,omitempty"`
+	Url     string   `json:"url"`
+}
+
+type WebhookDelivery struct {
+	Attempts    int32           `json:"attempts"`
+	Created     string          `json:"created"`
+	Event       string          `json:"event"`
+	Id          int64           `json:"id"`
+	LastError   JsonNullString  `json:"lastError"`
+	NextAttempt string          `json:"nextAttempt"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Webhook     int32           `json:"webhook"`
+	Secret      string          `json:"-"`
+	Url         string          `json:"-"`
+}
+
+type Notification struct {
+	Author   string `json:"author"`
+	Created  string `json:"created"`
+	Forum    string `json:"forum"`
+	Id       int64  `json:"id"`
+	IsRead   bool   `json:"isRead"`
+	Kind     string `json:"kind"`
+	Nickname string `json:"nickname"`
+	Post     int64  `json:"post"`
+	Thread   int32  `json:"thread"`
+}
+
+type NotificationInbox struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        int64          `json:"unread"`
+}
+
+type NotificationRead struct {
+	Ids []int64 `json:"ids"`
+}
+
+type Subscriptions struct {
+	Forums  []Forum  `json:"forums"`
+	Threads []Thread `json:"threads"`
+}
+
+type ThreadRead struct {
+	LastRead int64  `json:"lastRead"`
+	Nickname string `json:"nickname"`
+	Thread   int32  `json:"thread"`
+}
+
+type PostVote struct {
+	Nickname string `json:"nickname"`
+	Voice    int32  `json:"voice"`
+	IdPost   int64  `json:"-"`
+}
+
+type Reaction struct {
+	Emoji    string `json:"emoji"`
+	Nickname string `json:"nickname"`
+	IdPost   int64  `json:"-"`
+}
+
+type KarmaRank struct {
+	Karma    int64  `json:"karma"`
+	Nickname string `json:"nickname"`
+	Rank     int64  `json:"rank"`
+}
+
+type Poll struct {
+	Closed   bool         `json:"closed"`
+	ClosesAt string       `json:"closesAt,omitempty"`
+	Id       int32        `json:"id"`
+	Multiple bool         `json:"multiple"`
+	Options  []PollOption `json:"options"`
+	Question string       `json:"question"`
+	Thread   int32        `json:"thread"`
+	Voted    []int32      `json:"voted,omitempty"`
+	Voters   int32        `json:"voters"`
+}
+
+type PollOption struct {
+	Id    int32  `json:"id"`
+	Text  string `json:"text"`
+	Votes int32  `json:"votes"`
+}
+
+type PollBallot struct {
+	Nickname string  `json:"-"`
+	Options  []int32 `json:"options"`
+	Poll     int32   `json:"-"`
+}
+
+type TagCount struct {
+	Tag     string `json:"tag"`
+	Threads int64  `json:"threads"`
+}
+
+type Attachment struct {
+	Author      string `json:"author"`
+	ContentType string `json:"contentType"`
+	Created     string `json:"created"`
+	Filename    string `json:"filename"`
+	Id          int64  `json:"id"`
+	Post        int64  `json:"post"`
+	Size        int64  `json:"size"`
+	StorageKey  string `json:"-"`
+	Url         string `json:"url"`
+}
+
+type Conversation struct {
+	Created       string         `json:"created"`
+	Id            int32          `json:"id"`
+	LastMessage   *DirectMessage `json:"lastMessage,omitempty"`
+	LastMessageAt string         `json:"lastMessageAt,omitempty"`
+	Participants  []string       `json:"participants"`
+	Unread        int64          `json:"unread"`
+}
+
+type ConversationCreate struct {
+	Message      string   `json:"message"`
+	Participants []string `json:"participants"`
+}
+
+type DirectMessage struct {
+	Author       string `json:"author"`
+	Conversation int32  `json:"conversation"`
+	Created      string `json:"created"`
+	Id           int64  `json:"id"`
+	Message      string `json:"message"`
+}
+
+type MessagePage struct {
+	Messages   []DirectMessage `json:"messages"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
+
+type Block struct {
+	Blocked  string `json:"blocked"`
+	Created  string `json:"created"`
+	Nickname string `json:"nickname"`
+}
+
+type BlockedWord struct {
+	Action  string `json:"action"`
+	Created string `json:"created"`
+	Forum   string `json:"forum"`
+	Word    string `json:"word"`
+}
+
+type ModerationQueue struct {
+	Posts   []Post   `json:"posts"`
+	Threads []Thread `json:"threads"`
+}
@@ -0,0 +1,191 @@
+package ratelimit
+
+import (
+	"DbGODZ/internal/pkg/res"
+	"fmt"
+	"github.com/fasthttp/router"
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sweepInterval = time.Minute
+	idleTimeout   = 10 * time.Minute
+)
+
+var DefaultBudgets = map[string]Budget{
+	"read":   {Rate: 100, Burst: 200},
+	"write":  {Rate: 20, Burst: 40},
+	"create": {Rate: 5, Burst: 20},
+	"posts":  {Rate: 20, Burst: 200},
+}
+
+var DefaultRoutes = map[string]string{
+	"POST /api/user/{nickname}/create":     "create",
+	"POST /api/forum/create":               "create",
+	"POST /api/forum/{slug}/create":        "create",
+	"POST /api/conversation/create":        "create",
+	"POST /api/thread/{slug_or_id}/create": "",
+}
+
+type Limiter struct {
+	store   Store
+	budgets map[string]Budget
+	routes  map[string]string
+}
+
+func NewLimiter(store Store, budgets map[string]Budget, routes map[string]string) *Limiter {
+	return &Limiter{
+		store:   store,
+		budgets: budgets,
+		routes:  routes,
+	}
+}
+
+func ParseBudgets(value string, defaults map[string]Budget) (map[string]Budget, error) {
+	budgets := make(map[string]Budget, len(defaults))
+	for name, budget := range defaults {
+		budgets[name] = budget
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("budget %q must look like name=rate/burst", item)
+		}
+		limits := strings.SplitN(parts[1], "/", 2)
+		if len(limits) != 2 {
+			return nil, fmt.Errorf("budget %q must look like name=rate/burst", item)
+		}
+		rate, err := strconv.ParseFloat(limits[0], 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("budget %q has an invalid rate", item)
+		}
+		burst, err := strconv.ParseFloat(limits[1], 64)
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("budget %q has an invalid burst", item)
+		}
+		budgets[strings.TrimSpace(parts[0])] = Budget{Rate: rate, Burst: burst}
+	}
+	return budgets, nil
+}
+
+func (l *Limiter) RouteBudget(ctx *fasthttp.RequestCtx) string {
+	route, _ := ctx.UserValue(router.MatchedRoutePathParam).(string)
+	if budget, ok := l.routes[string(ctx.Method())+" "+route]; ok {
+		return budget
+	}
+	if ctx.IsGet() || ctx.IsHead() {
+		return "read"
+	}
+	return "write"
+}
+
+func ClientKeys(ctx *fasthttp.RequestCtx) []string {
+	keys := []string{"ip:" + ctx.RemoteIP().String()}
+	if nickname := ctx.Request.Header.Peek("X-Nickname"); len(nickname) > 0 {
+		keys = append(keys, "user:"+strings.ToLower(string(nickname)))
+	}
+	return keys
+}
+
+func (l *Limiter) Run() {
+	for range time.Tick(sweepInterval) {
+		if err := l.store.Sweep(idleTimeout); err != nil {
+			log.Error().Msgf("ratelimit: %s", err.Error())
+		}
+	}
+}
+
+func (l *Limiter) Handler(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if l == nil {
+		return next
+	}
+
+	return func(ctx *fasthttp.RequestCtx) {
+		costs := make(map[string]float64)
+		for _, key := range ClientKeys(ctx) {
+			costs[key] = 1
+		}
+		if l.Allow(ctx, l.RouteBudget(ctx), costs) {
+			next(ctx)
+		}
+	}
+}
+
+func (l *Limiter) Allow(ctx *fasthttp.RequestCtx, budgetName string, costs map[string]float64) bool {
+	if l == nil {
+		return true
+	}
+
+	budget, ok := l.budgets[budgetName]
+	if !ok {
+		return true
+	}
+
+	keys := make(map[string]float64, len(costs))
+	for key, cost := range costs {
+		keys[budgetName+":"+key] = cost
+	}
+	return l.take(ctx, budget, keys, fmt.Sprintf("rate limit exceeded for %s requests", budgetName))
+}
+
+func (l *Limiter) SlowMode(ctx *fasthttp.RequestCtx, forum string, interval time.Duration, authors map[string]int) bool {
+	if l == nil || interval <= 0 {
+		return true
+	}
+
+	message := fmt.Sprintf("forum %s is in slow mode: one post per %s", forum, interval)
+	keys := make(map[string]float64, len(authors))
+	for author, count := range authors {
+		if count > 1 {
+			reject(ctx, interval, message)
+			return false
+		}
+		keys["slow:"+strings.ToLower(forum)+":"+author] = 1
+	}
+	return l.take(ctx, Budget{Rate: 1 / interval.Seconds(), Burst: 1}, keys, message)
+}
+
+func (l *Limiter) take(ctx *fasthttp.RequestCtx, budget Budget, keys map[string]float64, message string) bool {
+	taken := make(map[string]float64, len(keys))
+	for key, cost := range keys {
+		wait, err := l.store.Take(key, budget, cost)
+		if err == nil && wait <= 0 {
+			taken[key] = cost
+			continue
+		}
+
+		l.refund(budget, taken)
+		if err != nil {
+			log.Error().Msgf("ratelimit: %s", err.Error())
+			res.SendResponse(fasthttp.StatusServiceUnavailable, res.HttpError{Message: "rate limiter is unavailable"}, ctx)
+			return false
+		}
+		reject(ctx, wait, message)
+		return false
+	}
+	return true
+}
+
+func (l *Limiter) refund(budget Budget, taken map[string]float64) {
+	for key, cost := range taken {
+		if err := l.store.Refund(key, budget, cost); err != nil {
+			log.Error().Msgf("ratelimit: %s", err.Error())
+		}
+	}
+}
+
+func reject(ctx *fasthttp.RequestCtx, wait time.Duration, message string) {
+	ctx.Response.Header.Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	res.SendResponse(fasthttp.StatusTooManyRequests, res.HttpError{Message: message}, ctx)
+}
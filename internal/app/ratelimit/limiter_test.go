@@ -0,0 +1,221 @@
+package ratelimit
+
+import (
+	"errors"
+	"github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+	"testing"
+	"time"
+)
+
+func newTestCtx(method, route, nickname string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	if nickname != "" {
+		ctx.Request.Header.Set("X-Nickname", nickname)
+	}
+	if route != "" {
+		ctx.SetUserValue(router.MatchedRoutePathParam, route)
+	}
+	return ctx
+}
+
+func TestRouteBudget(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), DefaultBudgets, DefaultRoutes)
+	tests := []struct {
+		method string
+		route  string
+		want   string
+	}{
+		{"GET", "/api/forum/{slug}/details", "read"},
+		{"HEAD", "/api/forum/{slug}/details", "read"},
+		{"POST", "/api/forum/create", "create"},
+		{"POST", "/api/forum/{slug}/create", "create"},
+		{"POST", "/api/user/{nickname}/create", "create"},
+		{"POST", "/api/conversation/create", "create"},
+		{"POST", "/api/thread/{slug_or_id}/create", ""},
+		{"POST", "/api/thread/{slug_or_id}/details", "write"},
+		{"DELETE", "/api/webhook/{id:[0-9]+}", "write"},
+		{"POST", "", "write"},
+	}
+
+	for _, tt := range tests {
+		if got := limiter.RouteBudget(newTestCtx(tt.method, tt.route, "")); got != tt.want {
+			t.Fatalf("%s %s: budget = %q, want %q", tt.method, tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestHandlerRejectsWithRetryAfter(t *testing.T) {
+	setClock(t, time.Unix(1000, 0))
+	limiter := NewLimiter(NewMemoryStore(), map[string]Budget{"create": {Rate: 0.25, Burst: 2}}, DefaultRoutes)
+	calls := 0
+	handler := limiter.Handler(func(ctx *fasthttp.RequestCtx) { calls++ })
+
+	for i := 0; i < 2; i++ {
+		ctx := newTestCtx("POST", "/api/forum/create", "alice")
+		handler(ctx)
+		if ctx.Response.StatusCode() != fasthttp.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, ctx.Response.StatusCode())
+		}
+	}
+
+	ctx := newTestCtx("POST", "/api/forum/create", "alice")
+	handler(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", ctx.Response.StatusCode())
+	}
+	if retry := string(ctx.Response.Header.Peek("Retry-After")); retry != "4" {
+		t.Fatalf("Retry-After = %q, want 4", retry)
+	}
+	if calls != 2 {
+		t.Fatalf("handler calls = %d, want 2", calls)
+	}
+}
+
+func TestHandlerSkipsPostBatches(t *testing.T) {
+	setClock(t, time.Unix(1000, 0))
+	limiter := NewLimiter(NewMemoryStore(), map[string]Budget{"create": {Rate: 1, Burst: 1}}, DefaultRoutes)
+	calls := 0
+	handler := limiter.Handler(func(ctx *fasthttp.RequestCtx) { calls++ })
+
+	for i := 0; i < 5; i++ {
+		handler(newTestCtx("POST", "/api/thread/{slug_or_id}/create", "alice"))
+	}
+	if calls != 5 {
+		t.Fatalf("post batch requests reached the handler %d times, want 5", calls)
+	}
+}
+
+func TestNilLimiterHandler(t *testing.T) {
+	var limiter *Limiter
+	calls := 0
+	limiter.Handler(func(ctx *fasthttp.RequestCtx) { calls++ })(newTestCtx("POST", "/api/forum/create", ""))
+	if calls != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls)
+	}
+}
+
+func TestSlowMode(t *testing.T) {
+	current := setClock(t, time.Unix(1000, 0))
+	limiter := NewLimiter(NewMemoryStore(), DefaultBudgets, DefaultRoutes)
+	authors := map[string]int{"alice": 1}
+
+	if !limiter.SlowMode(newTestCtx("POST", "", ""), "Forum", 10*time.Second, authors) {
+		t.Fatal("first post was rejected")
+	}
+	ctx := newTestCtx("POST", "", "")
+	*current = current.Add(3 * time.Second)
+	if limiter.SlowMode(ctx, "forum", 10*time.Second, authors) {
+		t.Fatal("second post inside the interval was accepted")
+	}
+	if retry := string(ctx.Response.Header.Peek("Retry-After")); retry != "7" {
+		t.Fatalf("Retry-After = %q, want 7", retry)
+	}
+
+	ctx = newTestCtx("POST", "", "")
+	if limiter.SlowMode(ctx, "forum", 10*time.Second, map[string]int{"bob": 2}) {
+		t.Fatal("batch with two posts by one author was accepted")
+	}
+	*current = current.Add(7 * time.Second)
+	if !limiter.SlowMode(newTestCtx("POST", "", ""), "forum", 10*time.Second, authors) {
+		t.Fatal("post after the interval was rejected")
+	}
+}
+
+func TestParseBudgets(t *testing.T) {
+	budgets, err := ParseBudgets("create=1/5, posts=0.5/10", DefaultBudgets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if budgets["create"] != (Budget{Rate: 1, Burst: 5}) || budgets["posts"] != (Budget{Rate: 0.5, Burst: 10}) {
+		t.Fatalf("budgets = %v", budgets)
+	}
+	if budgets["read"] != DefaultBudgets["read"] {
+		t.Fatalf("read budget = %v, want default", budgets["read"])
+	}
+	if DefaultBudgets["create"] == budgets["create"] {
+		t.Fatal("ParseBudgets modified the defaults")
+	}
+
+	for _, value := range []string{"create", "create=1", "create=x/5", "create=0/5", "create=1/0"} {
+		if _, err := ParseBudgets(value, DefaultBudgets); err == nil {
+			t.Fatalf("%q: expected an error", value)
+		}
+	}
+}
+
+type failingStore struct {
+	*MemoryStore
+	fail string
+}
+
+func (s *failingStore) Take(key string, budget Budget, cost float64) (time.Duration, error) {
+	if key == s.fail {
+		return 0, errors.New("store is down")
+	}
+	return s.MemoryStore.Take(key, budget, cost)
+}
+
+func TestHandlerFailsClosedOnStoreErrors(t *testing.T) {
+	setClock(t, time.Unix(1000, 0))
+	store := &failingStore{MemoryStore: NewMemoryStore(), fail: "create:user:alice"}
+	limiter := NewLimiter(store, map[string]Budget{"create": {Rate: 1, Burst: 1}}, DefaultRoutes)
+	calls := 0
+	handler := limiter.Handler(func(ctx *fasthttp.RequestCtx) { calls++ })
+
+	ctx := newTestCtx("POST", "/api/forum/create", "alice")
+	handler(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", ctx.Response.StatusCode())
+	}
+	if calls != 0 {
+		t.Fatalf("handler calls = %d, want 0", calls)
+	}
+
+	ctx = newTestCtx("POST", "/api/forum/create", "")
+	handler(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK || calls != 1 {
+		t.Fatalf("status = %d, calls = %d: the failed request charged the IP bucket",
+			ctx.Response.StatusCode(), calls)
+	}
+}
+
+func TestAllowRefundsOnRejection(t *testing.T) {
+	setClock(t, time.Unix(1000, 0))
+	limiter := NewLimiter(NewMemoryStore(), map[string]Budget{"create": {Rate: 1, Burst: 1}}, DefaultRoutes)
+
+	if !limiter.Allow(newTestCtx("POST", "", ""), "create", map[string]float64{"user:alice": 1}) {
+		t.Fatal("first request was rejected")
+	}
+	for i := 0; i < 3; i++ {
+		ctx := newTestCtx("POST", "", "")
+		if limiter.Allow(ctx, "create", map[string]float64{"ip:10.0.0.1": 1, "user:alice": 1}) {
+			t.Fatalf("request %d over the user budget was accepted", i)
+		}
+		if ctx.Response.StatusCode() != fasthttp.StatusTooManyRequests {
+			t.Fatalf("request %d: status = %d, want 429", i, ctx.Response.StatusCode())
+		}
+	}
+
+	if !limiter.Allow(newTestCtx("POST", "", ""), "create", map[string]float64{"ip:10.0.0.1": 1, "user:bob": 1}) {
+		t.Fatal("rejected requests drained the IP bucket")
+	}
+}
+
+func TestSlowModeRefundsOtherAuthors(t *testing.T) {
+	setClock(t, time.Unix(1000, 0))
+	limiter := NewLimiter(NewMemoryStore(), DefaultBudgets, DefaultRoutes)
+
+	if !limiter.SlowMode(newTestCtx("POST", "", ""), "forum", 10*time.Second, map[string]int{"alice": 1}) {
+		t.Fatal("first post was rejected")
+	}
+	for i := 0; i < 3; i++ {
+		if limiter.SlowMode(newTestCtx("POST", "", ""), "forum", 10*time.Second, map[string]int{"alice": 1, "bob": 1}) {
+			t.Fatal("batch with a throttled author was accepted")
+		}
+	}
+	if !limiter.SlowMode(newTestCtx("POST", "", ""), "forum", 10*time.Second, map[string]int{"bob": 1}) {
+		t.Fatal("rejected batches used up bob's slot")
+	}
+}
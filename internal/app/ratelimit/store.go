@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	forum "DbGODZ/internal/app"
+	"math"
+	"sync"
+	"time"
+)
+
+var clock = time.Now
+
+type Budget struct {
+	Rate  float64
+	Burst float64
+}
+
+type Store interface {
+	Take(key string, budget Budget, cost float64) (time.Duration, error)
+	Refund(key string, budget Budget, cost float64) error
+	Sweep(idle time.Duration) error
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *MemoryStore) Take(key string, budget Budget, cost float64) (time.Duration, error) {
+	cost = math.Min(cost, budget.Burst)
+	now := clock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: budget.Burst, updated: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(budget.Burst, b.tokens+now.Sub(b.updated).Seconds()*budget.Rate)
+	b.updated = now
+	if b.tokens >= cost {
+		b.tokens -= cost
+		return 0, nil
+	}
+	return time.Duration((cost - b.tokens) / budget.Rate * float64(time.Second)), nil
+}
+
+func (s *MemoryStore) Refund(key string, budget Budget, cost float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b, ok := s.buckets[key]; ok {
+		b.tokens = math.Min(budget.Burst, b.tokens+cost)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Sweep(idle time.Duration) error {
+	deadline := clock().Add(-idle)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, b := range s.buckets {
+		if b.updated.Before(deadline) {
+			delete(s.buckets, key)
+		}
+	}
+	return nil
+}
+
+type PostgresStore struct {
+	forumRepo forum.Repository
+}
+
+func NewPostgresStore(fr forum.Repository) *PostgresStore {
+	return &PostgresStore{forumRepo: fr}
+}
+
+func (s *PostgresStore) Take(key string, budget Budget, cost float64) (time.Duration, error) {
+	wait, err := s.forumRepo.TakeRateTokensForum(key, budget.Rate, budget.Burst, math.Min(cost, budget.Burst))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(wait * float64(time.Second)), nil
+}
+
+func (s *PostgresStore) Refund(key string, budget Budget, cost float64) error {
+	return s.forumRepo.RefundRateTokensForum(key, budget.Burst, math.Min(cost, budget.Burst))
+}
+
+func (s *PostgresStore) Sweep(idle time.Duration) error {
+	return s.forumRepo.PruneRateBucketsForum(idle)
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func setClock(t *testing.T, start time.Time) *time.Time {
+	current := start
+	clock = func() time.Time { return current }
+	t.Cleanup(func() { clock = time.Now })
+	return &current
+}
+
+func TestMemoryStoreBurst(t *testing.T) {
+	setClock(t, time.Unix(1000, 0))
+	store := NewMemoryStore()
+	budget := Budget{Rate: 1, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		if wait, err := store.Take("k", budget, 1); err != nil || wait != 0 {
+			t.Fatalf("take %d: wait = %s, err = %v, want immediate", i, wait, err)
+		}
+	}
+	wait, err := store.Take("k", budget, 1)
+	if err != nil || wait != time.Second {
+		t.Fatalf("take past burst: wait = %s, err = %v, want 1s", wait, err)
+	}
+	if wait, _ := store.Take("other", budget, 1); wait != 0 {
+		t.Fatalf("separate key waited %s", wait)
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	current := setClock(t, time.Unix(1000, 0))
+	store := NewMemoryStore()
+	budget := Budget{Rate: 2, Burst: 4}
+
+	if wait, _ := store.Take("k", budget, 4); wait != 0 {
+		t.Fatalf("full burst waited %s", wait)
+	}
+	if wait, _ := store.Take("k", budget, 1); wait != 500*time.Millisecond {
+		t.Fatalf("empty bucket wait = %s, want 500ms", wait)
+	}
+
+	*current = current.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if wait, _ := store.Take("k", budget, 1); wait != 0 {
+			t.Fatalf("refilled take %d waited %s", i, wait)
+		}
+	}
+	if wait, _ := store.Take("k", budget, 1); wait == 0 {
+		t.Fatal("bucket refilled more than rate * elapsed")
+	}
+
+	*current = current.Add(time.Hour)
+	if wait, _ := store.Take("k", budget, 4); wait != 0 {
+		t.Fatalf("refill capped at burst: wait = %s", wait)
+	}
+	if wait, _ := store.Take("k", budget, 1); wait == 0 {
+		t.Fatal("refill exceeded burst")
+	}
+}
+
+func TestMemoryStoreCostAboveBurst(t *testing.T) {
+	setClock(t, time.Unix(1000, 0))
+	store := NewMemoryStore()
+
+	if wait, _ := store.Take("k", Budget{Rate: 1, Burst: 5}, 50); wait != 0 {
+		t.Fatalf("oversized cost waited %s, want it capped at burst", wait)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	current := setClock(t, time.Unix(1000, 0))
+	store := NewMemoryStore()
+	budget := Budget{Rate: 1, Burst: 1}
+	store.Take("old", budget, 1)
+	*current = current.Add(time.Minute)
+	store.Take("new", budget, 1)
+
+	if err := store.Sweep(30 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.buckets["old"]; ok {
+		t.Fatal("idle bucket was not swept")
+	}
+	if _, ok := store.buckets["new"]; !ok {
+		t.Fatal("active bucket was swept")
+	}
+}
+
+func TestMemoryStoreRefund(t *testing.T) {
+	setClock(t, time.Unix(1000, 0))
+	store := NewMemoryStore()
+	budget := Budget{Rate: 1, Burst: 2}
+
+	if wait, _ := store.Take("k", budget, 2); wait != 0 {
+		t.Fatalf("full burst waited %s", wait)
+	}
+	if err := store.Refund("k", budget, 5); err != nil {
+		t.Fatal(err)
+	}
+	if wait, _ := store.Take("k", budget, 2); wait != 0 {
+		t.Fatalf("refunded tokens waited %s", wait)
+	}
+	if wait, _ := store.Take("k", budget, 1); wait != time.Second {
+		t.Fatalf("refund exceeded the burst: wait = %s, want 1s", wait)
+	}
+	if err := store.Refund("missing", budget, 1); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,6 +2,7 @@ package forum
 
 import (
 	"DbGODZ/internal/app/models"
+	"time"
 )
 
 type Repository interface {
@@ -14,18 +15,92 @@ type Repository interface {
 	GetBySlugForum(slug string) (models.Forum, error)
 	AddThreadForum(thread models.Thread) (models.Thread, error)
 	UpdateThreadForum(newThread models.Thread) (models.Thread, error)
-	GetThreadsForum(slug string, limit int, since string, desc bool) ([]models.Thread, error)
+	GetThreadsForum(filter models.ThreadFilter) ([]models.Thread, error)
 	CheckThreadExistsForum(slug string) (bool, error)
 	GetThreadBySlugForum(slug string) (models.Thread, error)
 	GetThreadByIDForum(id int) (models.Thread, error)
 	GetThreadIDBySlugForum(slug string) (int, error)
 	GetThreadSlugByIDForum(id int) (string, error)
 	AddPostsForum(posts []models.Post, threadID int) ([]models.Post, error)
-	GetPostsForum(postSlugOrId models.Thread, limit, since int, sort string, desc bool) ([]models.Post, error)
+	GetPostsForum(postSlugOrId models.Thread, limit, since int, sort string, desc bool,
+		viewer string) ([]models.Post, error)
+	GetForumPostsSinceForum(slug string, since int64) ([]models.Post, error)
 	GetPostForum(id int, related []string) (map[string]interface{}, error)
 	UpdatePostForum(newPost models.Post) (models.Post, error)
-	AddVoteForum(vote models.Vote) error
-	UpdateVoteForum(vote models.Vote) error
+	VoteForum(vote models.Vote, thread models.Thread) (models.Thread, error)
+	GetVotesForum(threadID, limit int, since string, desc bool) ([]models.Vote, error)
+	AddReportForum(report models.Report) (models.Report, error)
+	GetReportForum(id int64) (models.Report, error)
+	GetReportsForum(slug, status string, limit int, since int64, desc bool, related []string) ([]models.Report, error)
+	CloseReportForum(id int64, status, moderator string) (models.Report, error)
+	IsModeratorForum(slug, nickname string) (bool, error)
+	AddModeratorForum(moderator models.Moderator) (models.Moderator, error)
+	AddAuditForum(audit models.Audit) (models.Audit, error)
+	WithAuditForum(audit *models.Audit, change func(repo Repository) (interface{}, error)) error
+	GetAuditForum(filter models.AuditFilter) ([]models.Audit, error)
+	AddWebhookForum(webhook models.Webhook) (models.Webhook, error)
+	GetWebhookForum(id int) (models.Webhook, error)
+	GetWebhooksForum(slug string) ([]models.Webhook, error)
+	DeleteWebhookForum(id int) error
+	ClaimDeliveriesForum(limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	CompleteDeliveryForum(id int64) error
+	FailDeliveryForum(id int64, lastError string, retryIn time.Duration, dead bool) error
+	GetDeadDeliveriesForum(slug string, limit int, since int64) ([]models.WebhookDelivery, error)
+	GetDeliveryForum(id int64) (models.WebhookDelivery, error)
+	RetryDeliveryForum(id int64) (models.WebhookDelivery, error)
+	GetNotificationsForum(nickname string, limit int, since int64, desc, unreadOnly bool) ([]models.Notification, error)
+	CountUnreadNotificationsForum(nickname string) (int64, error)
+	MarkNotificationsReadForum(nickname string, ids []int64) (int64, error)
+	AddThreadSubscriptionForum(nickname string, threadID int) error
+	DeleteThreadSubscriptionForum(nickname string, threadID int) error
+	AddForumSubscriptionForum(nickname, slug string) error
+	DeleteForumSubscriptionForum(nickname, slug string) error
+	MarkThreadReadForum(nickname string, threadID int, postID int64) (models.ThreadRead, error)
+	GetSubscriptionsForum(nickname string, limit int) (models.Subscriptions, error)
+	VotePostForum(vote models.PostVote) error
+	AddReactionForum(reaction models.Reaction) error
+	DeleteReactionForum(reaction models.Reaction) error
+	GetLeaderboardForum(slug string, limit int) ([]models.KarmaRank, error)
+	AddThreadViewsForum(views map[int32]int64) error
+	GetPollForum(threadID int, nickname string) (models.Poll, error)
+	GetForumChildrenForum(slug string) ([]models.Forum, error)
+	GetForumBreadcrumbsForum(slug string) ([]models.ForumCrumb, error)
+	GetTagsForum(forumSlug string, limit int) ([]models.TagCount, error)
+	AddAttachmentForum(attachment models.Attachment, limit int) (models.Attachment, error)
+	GetAttachmentForum(id int64) (models.Attachment, error)
+	GetOrphanAttachmentsForum(limit int) ([]models.Attachment, error)
+	DeleteAttachmentForum(id int64) error
+	AddConversationForum(participants []string) (models.Conversation, error)
+	FindDirectConversationForum(nickname, other string) (int, error)
+	GetConversationForum(id int, nickname string) (models.Conversation, error)
+	GetConversationsForum(nickname string, limit int) ([]models.Conversation, error)
+	AddDirectMessageForum(message models.DirectMessage) (models.DirectMessage, error)
+	GetDirectMessagesForum(conversationID int, cursor int64, limit int, desc bool) ([]models.DirectMessage, error)
+	MarkConversationReadForum(conversationID int, nickname string, lastRead int64) error
+	AddBlockForum(nickname, blocked string) error
+	DeleteBlockForum(nickname, blocked string) error
+	GetBlocksForum(nickname string) ([]models.Block, error)
+	HasBlockBetweenForum(nickname string, others []string) (bool, error)
+	AddThreadMuteForum(nickname string, threadID int) error
+	DeleteThreadMuteForum(nickname string, threadID int) error
+	TakeRateTokensForum(key string, rate, burst, cost float64) (float64, error)
+	RefundRateTokensForum(key string, burst, cost float64) error
+	PruneRateBucketsForum(idle time.Duration) error
+	GetThreadSlowModeForum(threadID int) (string, int32, error)
+	SetSlowModeForum(slug string, seconds int32) (models.Forum, error)
+	VotePollForum(ballot models.PollBallot) error
+	GetBlockedWordsForum(slug string) ([]models.BlockedWord, error)
+	AddBlockedWordForum(word models.BlockedWord) (models.BlockedWord, error)
+	DeleteBlockedWordForum(slug, word string) error
+	GetAccountAgeForum(nickname string) (time.Duration, error)
+	FindDuplicateMessagesForum(authors, messages []string, excluded []int64, window time.Duration) ([]int, error)
+	GetModerationQueueForum(slug string, limit int) (models.ModerationQueue, error)
+	SetPostStatusForum(id int64, status string) (models.Post, error)
+	SetThreadStatusForum(id int, status string) (models.Thread, error)
+	SetPremoderationForum(slug string, enabled bool) (models.Forum, error)
+	GetDraftsForum(nickname string, limit int) ([]models.Thread, error)
+	PublishDraftForum(id int, status, publishAt string) (models.Thread, error)
+	PublishDueThreadsForum(limit int) ([]models.Thread, error)
 	GetServiceStatusForum() (map[string]int, error)
 	ClearDatabaseForum() error
 }
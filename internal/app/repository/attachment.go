@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"fmt"
+	"github.com/go-openapi/strfmt"
+	"time"
+)
+
+const attachmentColumns = `id, COALESCE(post, 0), author, filename, contentType, size, storageKey, created`
+
+func scanAttachmentForum(row rowScanner) (models.Attachment, error) {
+	var attachment models.Attachment
+	var created time.Time
+
+	err := row.Scan(&attachment.Id, &attachment.Post, &attachment.Author, &attachment.Filename,
+		&attachment.ContentType, &attachment.Size, &attachment.StorageKey, &created)
+	attachment.Created = strfmt.DateTime(created.UTC()).String()
+	attachment.Url = fmt.Sprintf("/api/attachment/%d", attachment.Id)
+	return attachment, err
+}
+
+func (p *postgresForumRepository) AddAttachmentForum(attachment models.Attachment, limit int) (models.Attachment, error) {
+	query := `WITH target AS (
+		SELECT id FROM post WHERE id = $1 AND jsonb_array_length(attachments) < $7 FOR UPDATE)
+	INSERT INTO attachment(
+    post,
+    author,
+    filename,
+    contentType,
+    size,
+    storageKey)
+	SELECT id, $2, $3, $4, $5, $6 FROM target
+	RETURNING ` + attachmentColumns
+
+	return scanAttachmentForum(p.conn.QueryRow(query, attachment.Post, attachment.Author, attachment.Filename,
+		attachment.ContentType, attachment.Size, attachment.StorageKey, limit))
+}
+
+func (p *postgresForumRepository) GetAttachmentForum(id int64) (models.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachment WHERE id = $1 AND post IS NOT NULL`
+
+	return scanAttachmentForum(p.conn.QueryRow(query, id))
+}
+
+func (p *postgresForumRepository) GetOrphanAttachmentsForum(limit int) ([]models.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachment WHERE post IS NULL ORDER BY id LIMIT $1`
+
+	data := make([]models.Attachment, 0, 0)
+	row, err := p.conn.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		attachment, err := scanAttachmentForum(row)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, attachment)
+	}
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) DeleteAttachmentForum(id int64) error {
+	query := `DELETE FROM attachment WHERE id = $1`
+
+	_, err := p.conn.Exec(query, id)
+	return err
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"fmt"
+	"github.com/jackc/pgx"
+	"sync"
+	"testing"
+)
+
+func TestAddAttachmentForumLimitUnderConcurrency(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestForumForum(t, repo, "files", "author")
+	threadObj := addTestThreadForum(t, repo, "files", "author")
+	postObj := addTestPostsForum(t, repo, threadObj, models.Post{Author: "author", Message: "files"})[0]
+
+	const limit = 3
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	added, rejected := 0, 0
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := repo.AddAttachmentForum(models.Attachment{Author: "author", ContentType: "text/plain",
+				Filename: "a.txt", Post: postObj.Id, Size: 1, StorageKey: fmt.Sprintf("posts/%d/%d", postObj.Id, i)},
+				limit)
+			mu.Lock()
+			defer mu.Unlock()
+			switch err {
+			case nil:
+				added++
+			case pgx.ErrNoRows:
+				rejected++
+			default:
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if added != limit || rejected != 12-limit {
+		t.Fatalf("added %d and rejected %d attachments, want %d and %d", added, rejected, limit, 12-limit)
+	}
+	if attachments := getTestPostForum(t, repo, postObj.Id).Attachments; len(attachments) != limit {
+		t.Fatalf("post lists %d attachments, want %d", len(attachments), limit)
+	}
+}
+
+func TestAttachmentsForumOrphanedWithPost(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestForumForum(t, repo, "orphans", "author")
+	threadObj := addTestThreadForum(t, repo, "orphans", "author")
+	postObj := addTestPostsForum(t, repo, threadObj, models.Post{Author: "author", Message: "files"})[0]
+
+	attachment, err := repo.AddAttachmentForum(models.Attachment{Author: "author", ContentType: "image/png",
+		Filename: "a.png", Post: postObj.Id, Size: 10, StorageKey: "posts/orphan"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.GetAttachmentForum(attachment.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = repo.conn.Exec(`DELETE FROM post WHERE id = $1`, postObj.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.GetAttachmentForum(attachment.Id); err != pgx.ErrNoRows {
+		t.Fatalf("orphaned attachment lookup error = %v, want ErrNoRows", err)
+	}
+
+	orphans, err := repo.GetOrphanAttachmentsForum(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 1 || orphans[0].StorageKey != "posts/orphan" {
+		t.Fatalf("orphans = %v, want the attachment of the deleted post", orphans)
+	}
+	if err = repo.DeleteAttachmentForum(orphans[0].Id); err != nil {
+		t.Fatal(err)
+	}
+	if orphans, err = repo.GetOrphanAttachmentsForum(10); err != nil || len(orphans) != 0 {
+		t.Fatalf("orphans after delete = %v, %v; want none", orphans, err)
+	}
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	forum "DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/strfmt"
+	"strings"
+	"time"
+)
+
+func (p *postgresForumRepository) AddAuditForum(audit models.Audit) (models.Audit, error) {
+	query := `INSERT INTO audit(
+    action,
+    actor,
+    anonymous,
+    after,
+    before,
+    forum,
+    requestId,
+    targetId,
+    targetType)
+	VALUES ($1, NULLIF($2, ''), $2 = '', NULLIF($3::text, '')::jsonb, NULLIF($4::text, '')::jsonb, NULLIF($5, ''), $6,
+	$7, $8)
+	RETURNING action, actor, anonymous, after::text, before::text, created, forum, id, requestId, targetId, targetType`
+
+	return p.scanAuditForum(p.conn.QueryRow(query, audit.Action, audit.Actor.String, string(audit.After),
+		string(audit.Before), audit.Forum.String, audit.RequestId, audit.TargetId, audit.TargetType))
+}
+
+func (p *postgresForumRepository) WithAuditForum(audit *models.Audit,
+	change func(repo forum.Repository) (interface{}, error)) error {
+	if p.pool == nil {
+		return p.applyAuditedForum(audit, change)
+	}
+
+	tx, err := p.pool.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = (&postgresForumRepository{conn: tx}).applyAuditedForum(audit, change); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *postgresForumRepository) applyAuditedForum(audit *models.Audit,
+	change func(repo forum.Repository) (interface{}, error)) error {
+	after, err := change(p)
+	if err != nil {
+		return err
+	}
+	if after != nil {
+		if audit.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+
+	*audit, err = p.AddAuditForum(*audit)
+	return err
+}
+
+func (p *postgresForumRepository) GetAuditForum(filter models.AuditFilter) ([]models.Audit, error) {
+	var conditions []string
+	var values []interface{}
+
+	addCondition := func(expression string, value interface{}) {
+		values = append(values, value)
+		conditions = append(conditions, fmt.Sprintf(expression, len(values)))
+	}
+
+	if filter.Action != "" {
+		addCondition("action = $%d", filter.Action)
+	}
+	if filter.Actor != "" {
+		addCondition("actor = $%d", filter.Actor)
+	}
+	if filter.Forum != "" {
+		addCondition("forum = $%d", filter.Forum)
+	}
+	if filter.TargetType != "" {
+		addCondition("targetType = $%d", filter.TargetType)
+	}
+	if filter.TargetId != "" {
+		addCondition("targetId = $%d", filter.TargetId)
+	}
+	if filter.From != "" {
+		addCondition("created >= $%d::timestamptz", filter.From)
+	}
+	if filter.To != "" {
+		addCondition("created <= $%d::timestamptz", filter.To)
+	}
+	if filter.Since > 0 {
+		if filter.Desc {
+			addCondition("id < $%d", filter.Since)
+		} else {
+			addCondition("id > $%d", filter.Since)
+		}
+	}
+
+	query := `SELECT action, actor, anonymous, after::text, before::text, created, forum, id, requestId, targetId,
+	targetType FROM audit `
+	if len(conditions) > 0 {
+		query += "WHERE " + strings.Join(conditions, " AND ") + " "
+	}
+	if filter.Desc {
+		query += `ORDER BY id DESC `
+	} else {
+		query += `ORDER BY id `
+	}
+	values = append(values, filter.Limit)
+	query += fmt.Sprintf(`LIMIT NULLIF($%d, 0)`, len(values))
+
+	data := make([]models.Audit, 0, 0)
+	row, err := p.conn.Query(query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if row != nil {
+			row.Close()
+		}
+	}()
+
+	for row.Next() {
+		auditObj, err := p.scanAuditForum(row)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, auditObj)
+	}
+
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) scanAuditForum(row rowScanner) (models.Audit, error) {
+	var auditObj models.Audit
+	var after, before sql.NullString
+	var created time.Time
+
+	err := row.Scan(&auditObj.Action, &auditObj.Actor, &auditObj.Anonymous, &after, &before, &created, &auditObj.Forum,
+		&auditObj.Id, &auditObj.RequestId, &auditObj.TargetId, &auditObj.TargetType)
+	if err != nil {
+		return models.Audit{}, err
+	}
+	if after.Valid {
+		auditObj.After = json.RawMessage(after.String)
+	}
+	if before.Valid {
+		auditObj.Before = json.RawMessage(before.String)
+	}
+	auditObj.Created = strfmt.DateTime(created.UTC()).String()
+	return auditObj, nil
+}
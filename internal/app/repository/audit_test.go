@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"errors"
+	"testing"
+)
+
+func TestWithAuditForumCommitsChangeAndRecord(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "owner")
+	addTestForumForum(t, repo, "audited", "owner")
+
+	auditObj := models.Audit{Action: "forum.slowmode", RequestId: "req-1", TargetId: "audited", TargetType: "forum"}
+	auditObj.Actor.String = "owner"
+	auditObj.Forum.String = "audited"
+	err := repo.WithAuditForum(&auditObj, func(txRepo forum.Repository) (interface{}, error) {
+		return txRepo.SetSlowModeForum("audited", 30)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forumObj, err := repo.GetBySlugForum("audited")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forumObj.SlowMode != 30 {
+		t.Errorf("slow mode = %d, want 30", forumObj.SlowMode)
+	}
+	records, err := repo.GetAuditForum(models.AuditFilter{Forum: "audited"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Actor.String != "owner" || records[0].Anonymous || len(records[0].After) == 0 {
+		t.Fatalf("audit records = %+v, want one record by owner with an after snapshot", records)
+	}
+}
+
+func TestWithAuditForumRollsBackOnFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(txRepo forum.Repository) (interface{}, error)
+	}{
+		{"change fails", func(txRepo forum.Repository) (interface{}, error) {
+			if _, err := txRepo.SetSlowModeForum("rollback", 30); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("change failed")
+		}},
+		{"audit snapshot fails", func(txRepo forum.Repository) (interface{}, error) {
+			if _, err := txRepo.SetSlowModeForum("rollback", 30); err != nil {
+				return nil, err
+			}
+			return make(chan int), nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepositoryForum(t)
+			addTestUserForum(t, repo, "owner")
+			addTestForumForum(t, repo, "rollback", "owner")
+
+			auditObj := models.Audit{Action: "forum.slowmode", RequestId: tt.name, TargetId: "rollback",
+				TargetType: "forum"}
+			if err := repo.WithAuditForum(&auditObj, tt.change); err == nil {
+				t.Fatal("WithAuditForum succeeded, want an error")
+			}
+
+			forumObj, err := repo.GetBySlugForum("rollback")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if forumObj.SlowMode != 0 {
+				t.Errorf("slow mode = %d, want the change rolled back", forumObj.SlowMode)
+			}
+			records, err := repo.GetAuditForum(models.AuditFilter{TargetType: "forum", TargetId: "rollback",
+				Action: "forum.slowmode"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, record := range records {
+				if record.RequestId == tt.name {
+					t.Fatalf("audit record %d was written for a rolled back change", record.Id)
+				}
+			}
+		})
+	}
+}
+
+func TestWithAuditForumRecordsAnonymousActor(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+
+	auditObj := models.Audit{Action: "service.clear", RequestId: "anonymous", TargetId: "database",
+		TargetType: "service"}
+	err := repo.WithAuditForum(&auditObj, func(txRepo forum.Repository) (interface{}, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !auditObj.Anonymous || auditObj.Actor.Valid {
+		t.Fatalf("audit = %+v, want an explicitly anonymous record", auditObj)
+	}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"github.com/go-openapi/strfmt"
+	"strings"
+	"time"
+)
+
+func collapsePostForum(post *models.Post) {
+	post.Hidden = true
+	post.Message = ""
+	post.MessageHtml = ""
+	post.Attachments = nil
+}
+
+func (p *postgresForumRepository) getBlockedSetForum(nickname string) (map[string]bool, error) {
+	query := `SELECT blocked FROM user_block WHERE nickname = $1`
+
+	row, err := p.conn.Query(query, nickname)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	blocked := make(map[string]bool)
+	for row.Next() {
+		var author string
+		err = row.Scan(&author)
+		if err != nil {
+			return nil, err
+		}
+		blocked[strings.ToLower(author)] = true
+	}
+	return blocked, row.Err()
+}
+
+func (p *postgresForumRepository) AddBlockForum(nickname, blocked string) error {
+	query := `INSERT INTO user_block(
+    nickname,
+    blocked)
+	VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	_, err := p.conn.Exec(query, nickname, blocked)
+	return err
+}
+
+func (p *postgresForumRepository) DeleteBlockForum(nickname, blocked string) error {
+	query := `DELETE FROM user_block WHERE nickname = $1 AND blocked = $2`
+
+	_, err := p.conn.Exec(query, nickname, blocked)
+	return err
+}
+
+func (p *postgresForumRepository) GetBlocksForum(nickname string) ([]models.Block, error) {
+	query := `SELECT blocked, created, nickname FROM user_block WHERE nickname = $1 ORDER BY created DESC`
+
+	data := make([]models.Block, 0, 0)
+	row, err := p.conn.Query(query, nickname)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var block models.Block
+		var created time.Time
+		err = row.Scan(&block.Blocked, &created, &block.Nickname)
+		if err != nil {
+			return nil, err
+		}
+		block.Created = strfmt.DateTime(created.UTC()).String()
+		data = append(data, block)
+	}
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) HasBlockBetweenForum(nickname string, others []string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM user_block
+	WHERE (nickname = $1 AND blocked = ANY ($2::citext[])) OR (blocked = $1 AND nickname = ANY ($2::citext[])))`
+
+	var exists bool
+	err := p.conn.QueryRow(query, nickname, others).Scan(&exists)
+	return exists, err
+}
+
+func (p *postgresForumRepository) AddThreadMuteForum(nickname string, threadID int) error {
+	query := `INSERT INTO thread_mute(
+    nickname,
+    thread)
+	VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	_, err := p.conn.Exec(query, nickname, threadID)
+	return err
+}
+
+func (p *postgresForumRepository) DeleteThreadMuteForum(nickname string, threadID int) error {
+	query := `DELETE FROM thread_mute WHERE nickname = $1 AND thread = $2`
+
+	_, err := p.conn.Exec(query, nickname, threadID)
+	return err
+}
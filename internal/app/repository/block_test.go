@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+func TestThreadMuteForumOnlySuppressesNotifications(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestUserForum(t, repo, "reader")
+	addTestForumForum(t, repo, "muted", "author")
+	threadObj := addTestThreadForum(t, repo, "muted", "author")
+
+	if err := repo.AddThreadMuteForum("reader", int(threadObj.Id)); err != nil {
+		t.Fatal(err)
+	}
+	addTestPostsForum(t, repo, threadObj, models.Post{Author: "author", Message: "hello @reader"})
+
+	threads, err := repo.GetThreadsForum(models.ThreadFilter{Forum: "muted", Nickname: "reader"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(threads) != 1 || threads[0].Id != threadObj.Id {
+		t.Fatalf("listing for a muting user = %v, want the muted thread", threads)
+	}
+
+	notifications, err := repo.GetNotificationsForum("reader", 0, 0, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 0 {
+		t.Fatalf("notifications from a muted thread = %v", notifications)
+	}
+
+	if err = repo.DeleteThreadMuteForum("reader", int(threadObj.Id)); err != nil {
+		t.Fatal(err)
+	}
+	addTestPostsForum(t, repo, threadObj, models.Post{Author: "author", Message: "again @reader"})
+	if notifications, err = repo.GetNotificationsForum("reader", 0, 0, false, false); err != nil || len(notifications) != 1 {
+		t.Fatalf("notifications after unmuting = %v, %v; want one", notifications, err)
+	}
+}
+
+func TestGetPostsForumHidesBlockedAuthors(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestUserForum(t, repo, "troll")
+	addTestUserForum(t, repo, "reader")
+	addTestForumForum(t, repo, "blocks", "author")
+	threadObj := addTestThreadForum(t, repo, "blocks", "author")
+
+	root := addTestPostsForum(t, repo, threadObj, models.Post{Author: "troll", Message: "bait"})[0]
+	reply := models.Post{Author: "author", Message: "answer"}
+	reply.Parent.Valid = true
+	reply.Parent.Int64 = root.Id
+	addTestPostsForum(t, repo, threadObj, reply, models.Post{Author: "author", Message: "second root"})
+
+	if err := repo.AddBlockForum("reader", "TROLL"); err != nil {
+		t.Fatal(err)
+	}
+	blocked, err := repo.HasBlockBetweenForum("troll", []string{"reader"})
+	if err != nil || !blocked {
+		t.Fatalf("HasBlockBetweenForum(troll, reader) = %t, %v; want true", blocked, err)
+	}
+
+	flat, err := repo.GetPostsForum(threadObj, 0, 0, "flat", false, "reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flat) != 2 {
+		t.Fatalf("flat listing = %v, want the two posts by author", flat)
+	}
+	if limited, err := repo.GetPostsForum(threadObj, 1, 0, "flat", false, "reader"); err != nil ||
+		len(limited) != 1 || limited[0].Author != "author" {
+		t.Fatalf("flat listing with limit 1 = %v, %v; want one post by author", limited, err)
+	}
+
+	for _, sort := range []string{"tree", "parent_tree"} {
+		posts, err := repo.GetPostsForum(threadObj, 0, 0, sort, false, "reader")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(posts) != 3 || posts[0].Id != root.Id || !posts[0].Hidden || posts[0].Message != "" {
+			t.Fatalf("%s listing = %v, want the blocked root collapsed in place", sort, posts)
+		}
+		if posts[1].Hidden || posts[1].Message != "answer" {
+			t.Fatalf("%s listing hides the reply to a blocked post: %v", sort, posts[1])
+		}
+	}
+
+	if posts, err := repo.GetPostsForum(threadObj, 0, 0, "flat", false, ""); err != nil || len(posts) != 3 {
+		t.Fatalf("anonymous listing = %v, %v; want all three posts", posts, err)
+	}
+}
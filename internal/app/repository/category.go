@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+)
+
+func (p *postgresForumRepository) GetForumChildrenForum(slug string) ([]models.Forum, error) {
+	query := `SELECT ` + forumColumns + ` FROM forum WHERE parent = $1 ORDER BY position, slug`
+
+	data := make([]models.Forum, 0, 0)
+	row, err := p.conn.Query(query, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		forumObj, err := scanForumForum(row)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, forumObj)
+	}
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) GetForumBreadcrumbsForum(slug string) ([]models.ForumCrumb, error) {
+	query := `SELECT ancestor.slug, ancestor.title FROM forum
+	JOIN forum ancestor ON ancestor.slug = ANY (forum.path::citext[])
+	WHERE forum.slug = $1 AND ancestor.slug <> forum.slug
+	ORDER BY ancestor.depth`
+
+	data := make([]models.ForumCrumb, 0, 0)
+	row, err := p.conn.Query(query, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var crumb models.ForumCrumb
+		err = row.Scan(&crumb.Slug, &crumb.Title)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, crumb)
+	}
+	return data, row.Err()
+}
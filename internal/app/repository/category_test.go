@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"github.com/jackc/pgx"
+	"testing"
+)
+
+func addTestSubforumsForum(repo *postgresForumRepository, owner string, slugs ...string) error {
+	parent := ""
+	for _, slug := range slugs {
+		if _, err := repo.AddForum(models.Forum{Slug: slug, Title: slug, User: owner, Parent: parent}); err != nil {
+			return err
+		}
+		parent = slug
+	}
+	return nil
+}
+
+func TestAddForumDepthLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   map[string]string
+		depth    int
+		wantFail bool
+	}{
+		{"default limit allows three levels", nil, 3, false},
+		{"default limit rejects a fourth level", nil, 4, true},
+		{"configured limit allows four levels", map[string]string{"forum.max_depth": "4"}, 4, false},
+		{"configured limit rejects a second level", map[string]string{"forum.max_depth": "1"}, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepositoryParamsForum(t, tt.params)
+			addTestUserForum(t, repo, "owner")
+			slugs := []string{"level0", "level1", "level2", "level3"}[:tt.depth]
+
+			err := addTestSubforumsForum(repo, "owner", slugs...)
+			if !tt.wantFail {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if pgerr, ok := err.(pgx.PgError); !ok || pgerr.Code != "00400" {
+				t.Fatalf("error = %v, want forum depth limit exceeded", err)
+			}
+		})
+	}
+}
+
+func TestForumChildrenAndBreadcrumbsForum(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "owner")
+	if err := addTestSubforumsForum(repo, "owner", "root", "child", "leaf"); err != nil {
+		t.Fatal(err)
+	}
+	threadObj := addTestThreadForum(t, repo, "leaf", "owner")
+	addTestPostsForum(t, repo, threadObj, models.Post{Author: "owner", Message: "rolled up"})
+
+	crumbs, err := repo.GetForumBreadcrumbsForum("leaf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(crumbs) != 2 || crumbs[0].Slug != "root" || crumbs[1].Slug != "child" {
+		t.Fatalf("breadcrumbs = %v, want root then child", crumbs)
+	}
+
+	children, err := repo.GetForumChildrenForum("root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 1 || children[0].Slug != "child" {
+		t.Fatalf("children = %v, want child", children)
+	}
+
+	for _, slug := range []string{"root", "child", "leaf"} {
+		forumObj, err := repo.GetBySlugForum(slug)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if forumObj.Threads != 1 || forumObj.Posts != 1 {
+			t.Errorf("%s counters = %d threads, %d posts; want 1 and 1", slug, forumObj.Threads, forumObj.Posts)
+		}
+	}
+}
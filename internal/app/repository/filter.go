@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"github.com/go-openapi/strfmt"
+	"time"
+)
+
+func (p *postgresForumRepository) GetBlockedWordsForum(slug string) ([]models.BlockedWord, error) {
+	query := `SELECT action, created, forum, word FROM forum_blocklist
+	WHERE forum = ANY ((SELECT path FROM forum WHERE slug = $1)::citext[]) ORDER BY forum, word`
+
+	data := make([]models.BlockedWord, 0, 0)
+	row, err := p.conn.Query(query, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var word models.BlockedWord
+		var created time.Time
+		err = row.Scan(&word.Action, &created, &word.Forum, &word.Word)
+		if err != nil {
+			return nil, err
+		}
+		word.Created = strfmt.DateTime(created.UTC()).String()
+		data = append(data, word)
+	}
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) AddBlockedWordForum(word models.BlockedWord) (models.BlockedWord, error) {
+	query := `INSERT INTO forum_blocklist(
+    forum,
+    word,
+    action)
+	VALUES ($1, $2, $3) ON CONFLICT (forum, word) DO UPDATE SET action = EXCLUDED.action
+	RETURNING action, created, forum, word`
+
+	var wordObj models.BlockedWord
+	var created time.Time
+	err := p.conn.QueryRow(query, word.Forum, word.Word, word.Action).Scan(&wordObj.Action, &created,
+		&wordObj.Forum, &wordObj.Word)
+	wordObj.Created = strfmt.DateTime(created.UTC()).String()
+	return wordObj, err
+}
+
+func (p *postgresForumRepository) DeleteBlockedWordForum(slug, word string) error {
+	query := `DELETE FROM forum_blocklist WHERE forum = $1 AND word = $2`
+
+	_, err := p.conn.Exec(query, slug, word)
+	return err
+}
+
+func (p *postgresForumRepository) GetAccountAgeForum(nickname string) (time.Duration, error) {
+	query := `SELECT extract(EPOCH FROM now() - created)::float8 FROM users WHERE nickname = $1`
+
+	var seconds float64
+	err := p.conn.QueryRow(query, nickname).Scan(&seconds)
+	return time.Duration(seconds * float64(time.Second)), err
+}
+
+func (p *postgresForumRepository) FindDuplicateMessagesForum(authors, messages []string, excluded []int64,
+	window time.Duration) ([]int, error) {
+	query := `SELECT (c.i - 1)::int FROM unnest($1::citext[], $2::text[], $3::bigint[])
+		WITH ORDINALITY AS c(author, message, excluded, i)
+	WHERE EXISTS(SELECT 1 FROM post WHERE post.author = c.author
+		AND post.created > now() - $4::bigint * interval '1 millisecond'
+		AND post.message = c.message AND post.id <> c.excluded AND post.status <> 'rejected')
+	OR EXISTS(SELECT 1 FROM thread WHERE thread.author = c.author
+		AND thread.created > now() - $4::bigint * interval '1 millisecond'
+		AND thread.message = c.message AND thread.status <> 'rejected')`
+
+	data := make([]int, 0, 0)
+	row, err := p.conn.Query(query, authors, messages, excluded, window.Nanoseconds()/int64(time.Millisecond))
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var i int32
+		err = row.Scan(&i)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, int(i))
+	}
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) GetModerationQueueForum(slug string, limit int) (models.ModerationQueue, error) {
+	threadsQuery := `SELECT * FROM thread WHERE forum = $1 AND status = 'pending' ORDER BY id LIMIT NULLIF($2, 0)`
+	postsQuery := `SELECT * FROM post WHERE forum = $1 AND status = 'pending' ORDER BY id LIMIT NULLIF($2, 0)`
+
+	queue := models.ModerationQueue{
+		Posts:   make([]models.Post, 0, 0),
+		Threads: make([]models.Thread, 0, 0),
+	}
+
+	row, err := p.conn.Query(threadsQuery, slug, limit)
+	if err != nil {
+		return queue, err
+	}
+	for row.Next() {
+		threadObj, err := scanThreadForum(row)
+		if err != nil {
+			row.Close()
+			return queue, err
+		}
+		queue.Threads = append(queue.Threads, threadObj)
+	}
+	row.Close()
+	if err = row.Err(); err != nil {
+		return queue, err
+	}
+
+	row, err = p.conn.Query(postsQuery, slug, limit)
+	if err != nil {
+		return queue, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		post, err := scanPostForum(row)
+		if err != nil {
+			return queue, err
+		}
+		queue.Posts = append(queue.Posts, post)
+	}
+	return queue, row.Err()
+}
+
+func (p *postgresForumRepository) SetPostStatusForum(id int64, status string) (models.Post, error) {
+	query := `UPDATE post SET status = $2, statusReason = CASE WHEN $2 = 'published' THEN NULL ELSE statusReason END
+	WHERE id = $1 AND status = 'pending' RETURNING *`
+
+	return scanPostForum(p.conn.QueryRow(query, id, status))
+}
+
+func (p *postgresForumRepository) SetThreadStatusForum(id int, status string) (models.Thread, error) {
+	query := `UPDATE thread SET status = CASE WHEN $2 = 'published' AND publishAt > now() THEN 'scheduled' ELSE $2 END,
+	statusReason = CASE WHEN $2 = 'published' THEN NULL ELSE statusReason END
+	WHERE id = $1 AND status = 'pending' RETURNING *`
+
+	return scanThreadForum(p.conn.QueryRow(query, id, status))
+}
+
+func (p *postgresForumRepository) SetPremoderationForum(slug string, enabled bool) (models.Forum, error) {
+	query := `UPDATE forum SET premoderated = $2 WHERE slug = $1 RETURNING ` + forumColumns
+
+	return scanForumForum(p.conn.QueryRow(query, slug, enabled))
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFindDuplicateMessagesForumIndexes(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "alice")
+	addTestUserForum(t, repo, "bob")
+	addTestForumForum(t, repo, "forum", "alice")
+	threadObj := addTestThreadForum(t, repo, "forum", "alice")
+	posts := addTestPostsForum(t, repo, threadObj,
+		models.Post{Author: "alice", Message: "hello"},
+		models.Post{Author: "bob", Message: "rejected", Status: "rejected"})
+
+	authors := []string{"bob", "ALICE", "alice", "alice", "bob", "alice"}
+	messages := []string{"hello", "hello", "other", "hello", "rejected", "message"}
+	excluded := []int64{0, 0, 0, posts[0].Id, 0, 0}
+
+	indexes, err := repo.FindDuplicateMessagesForum(authors, messages, excluded, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1}; !reflect.DeepEqual(indexes, want) {
+		t.Fatalf("duplicate indexes = %v, want %v", indexes, want)
+	}
+
+	indexes, err = repo.FindDuplicateMessagesForum(authors[:2], messages[:2], excluded[:2], time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indexes) != 0 {
+		t.Fatalf("duplicate indexes outside the window = %v, want none", indexes)
+	}
+}
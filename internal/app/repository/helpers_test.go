@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"github.com/jackc/pgx"
+	"os"
+	"testing"
+)
+
+func newTestRepositoryForum(t *testing.T) *postgresForumRepository {
+	t.Helper()
+	return newTestRepositoryParamsForum(t, nil)
+}
+
+func newTestRepositoryParamsForum(t *testing.T, params map[string]string) *postgresForumRepository {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set; point it at a database initialised with db/db.sql")
+	}
+
+	connConfig, err := pgx.ParseConnectionString(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range params {
+		connConfig.RuntimeParams[key] = value
+	}
+	pool, err := pgx.NewConnPool(pgx.ConnPoolConfig{ConnConfig: connConfig, MaxConnections: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	repo := &postgresForumRepository{conn: pool}
+	if err = repo.ClearDatabaseForum(); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func addTestUserForum(t *testing.T, repo *postgresForumRepository, nickname string) models.User {
+	t.Helper()
+	userObj := models.User{Email: nickname + "@example.com", FullName: nickname, Nickname: nickname}
+	if err := repo.Add(userObj); err != nil {
+		t.Fatal(err)
+	}
+	return userObj
+}
+
+func addTestForumForum(t *testing.T, repo *postgresForumRepository, slug, owner string) models.Forum {
+	t.Helper()
+	forumObj, err := repo.AddForum(models.Forum{Slug: slug, Title: slug, User: owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return forumObj
+}
+
+func addTestThreadForum(t *testing.T, repo *postgresForumRepository, forumSlug, author string) models.Thread {
+	t.Helper()
+	threadObj, err := repo.AddThreadForum(models.Thread{Author: author, Forum: forumSlug, Message: "message",
+		Title: "title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return threadObj
+}
+
+func addTestPostsForum(t *testing.T, repo *postgresForumRepository, threadObj models.Thread,
+	posts ...models.Post) []models.Post {
+	t.Helper()
+	for i := range posts {
+		posts[i].Forum = threadObj.Forum
+		posts[i].Thread = threadObj.Id
+	}
+	created, err := repo.AddPostsForum(posts, int(threadObj.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return created
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+)
+
+func (p *postgresForumRepository) GetLeaderboardForum(slug string, limit int) ([]models.KarmaRank, error) {
+	var query string
+	var args []interface{}
+
+	if slug != "" {
+		query = `SELECT karma, nickname, RANK() OVER (ORDER BY karma DESC) FROM user_karma
+		WHERE forum = $1 ORDER BY karma DESC, nickname LIMIT NULLIF($2, 0)`
+		args = append(args, slug, limit)
+	} else {
+		query = `SELECT karma, nickname, RANK() OVER (ORDER BY karma DESC) FROM users
+		ORDER BY karma DESC, nickname LIMIT NULLIF($1, 0)`
+		args = append(args, limit)
+	}
+
+	data := make([]models.KarmaRank, 0, 0)
+	row, err := p.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if row != nil {
+			row.Close()
+		}
+	}()
+
+	for row.Next() {
+		var rank models.KarmaRank
+
+		err = row.Scan(&rank.Karma, &rank.Nickname, &rank.Rank)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, rank)
+	}
+
+	return data, row.Err()
+}
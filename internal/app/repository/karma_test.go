@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+func TestGetLeaderboardForumKarmaFromVotes(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "alice")
+	addTestUserForum(t, repo, "bob")
+	addTestUserForum(t, repo, "carol")
+	addTestForumForum(t, repo, "first", "alice")
+	addTestForumForum(t, repo, "second", "alice")
+	aliceThread := addTestThreadForum(t, repo, "first", "alice")
+	bobThread := addTestThreadForum(t, repo, "second", "bob")
+	bobPost := addTestPostsForum(t, repo, aliceThread, models.Post{Author: "bob", Message: "reply"})[0]
+
+	for _, vote := range []struct {
+		nickname string
+		thread   models.Thread
+		voice    int32
+	}{
+		{"bob", aliceThread, 1},
+		{"carol", aliceThread, 1},
+		{"alice", aliceThread, 1},
+		{"carol", bobThread, -1},
+	} {
+		if _, err := repo.VoteForum(models.Vote{Nickname: vote.nickname, Voice: vote.voice},
+			models.Thread{Id: vote.thread.Id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.VotePostForum(models.PostVote{Nickname: "alice", Voice: 1, IdPost: bobPost.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.VotePostForum(models.PostVote{Nickname: "carol", Voice: 1, IdPost: bobPost.Id}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		forum string
+		want  map[string]int64
+	}{
+		{"", map[string]int64{"alice": 2, "bob": 1, "carol": 0}},
+		{"first", map[string]int64{"alice": 2, "bob": 2}},
+		{"second", map[string]int64{"bob": -1}},
+	}
+	for _, tt := range tests {
+		leaders, err := repo.GetLeaderboardForum(tt.forum, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(leaders) != len(tt.want) {
+			t.Fatalf("forum %q leaderboard = %v, want %v", tt.forum, leaders, tt.want)
+		}
+		for i, rank := range leaders {
+			if tt.want[rank.Nickname] != rank.Karma {
+				t.Errorf("forum %q: %s karma = %d, want %d", tt.forum, rank.Nickname, rank.Karma, tt.want[rank.Nickname])
+			}
+			if i > 0 && leaders[i-1].Karma < rank.Karma {
+				t.Errorf("forum %q leaderboard is not sorted: %v", tt.forum, leaders)
+			}
+		}
+	}
+
+	userObj, err := repo.GetByNick("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userObj.Karma != 2 {
+		t.Errorf("alice karma = %d, want 2", userObj.Karma)
+	}
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"github.com/go-openapi/strfmt"
+	"github.com/jackc/pgtype"
+	"time"
+)
+
+const conversationColumns = `conversation.id, conversation.created, conversation.lastMessageAt,
+	(SELECT array_agg(cm.nickname::text ORDER BY cm.nickname) FROM conversation_member cm
+		WHERE cm.conversation = conversation.id),
+	(SELECT COUNT(*) FROM direct_message dm WHERE dm.conversation = conversation.id AND dm.id > me.lastRead)`
+
+func scanConversationForum(row rowScanner) (models.Conversation, error) {
+	var conversation models.Conversation
+	var created time.Time
+	var lastMessageAt pgtype.Timestamptz
+
+	err := row.Scan(&conversation.Id, &created, &lastMessageAt, &conversation.Participants, &conversation.Unread)
+	conversation.Created = strfmt.DateTime(created.UTC()).String()
+	if lastMessageAt.Status == pgtype.Present {
+		conversation.LastMessageAt = strfmt.DateTime(lastMessageAt.Time.UTC()).String()
+	}
+	return conversation, err
+}
+
+func scanDirectMessageForum(row rowScanner) (models.DirectMessage, error) {
+	var message models.DirectMessage
+	var created time.Time
+
+	err := row.Scan(&message.Author, &message.Conversation, &created, &message.Id, &message.Message)
+	message.Created = strfmt.DateTime(created.UTC()).String()
+	return message, err
+}
+
+func (p *postgresForumRepository) AddConversationForum(participants []string) (models.Conversation, error) {
+	query := `WITH new_conversation AS (
+		INSERT INTO conversation DEFAULT VALUES RETURNING id)
+	INSERT INTO conversation_member(
+	conversation,
+	nickname)
+	SELECT new_conversation.id, unnest($1::text[]) FROM new_conversation
+	RETURNING conversation`
+
+	var id int
+	err := p.conn.QueryRow(query, participants).Scan(&id)
+	if err != nil {
+		return models.Conversation{}, err
+	}
+
+	return p.GetConversationForum(id, participants[0])
+}
+
+func (p *postgresForumRepository) FindDirectConversationForum(nickname, other string) (int, error) {
+	query := `SELECT conversation FROM conversation_member
+	WHERE nickname = $1 AND conversation IN (SELECT conversation FROM conversation_member WHERE nickname = $2)
+	AND (SELECT COUNT(*) FROM conversation_member cm WHERE cm.conversation = conversation_member.conversation) = 2
+	ORDER BY conversation LIMIT 1`
+
+	var id int
+	err := p.conn.QueryRow(query, nickname, other).Scan(&id)
+	return id, err
+}
+
+func (p *postgresForumRepository) GetConversationForum(id int, nickname string) (models.Conversation, error) {
+	query := `SELECT ` + conversationColumns + ` FROM conversation
+	JOIN conversation_member me ON me.conversation = conversation.id
+	WHERE conversation.id = $1 AND me.nickname = $2`
+
+	return scanConversationForum(p.conn.QueryRow(query, id, nickname))
+}
+
+func (p *postgresForumRepository) GetConversationsForum(nickname string, limit int) ([]models.Conversation, error) {
+	query := `SELECT ` + conversationColumns + `,
+	COALESCE(last.author, ''), COALESCE(last.created, conversation.created), COALESCE(last.id, 0),
+	COALESCE(last.message, '') FROM conversation
+	JOIN conversation_member me ON me.conversation = conversation.id
+	LEFT JOIN LATERAL (SELECT * FROM direct_message dm WHERE dm.conversation = conversation.id
+		ORDER BY dm.id DESC LIMIT 1) last ON true
+	WHERE me.nickname = $1
+	ORDER BY COALESCE(conversation.lastMessageAt, conversation.created) DESC, conversation.id DESC
+	LIMIT NULLIF($2, 0)`
+
+	data := make([]models.Conversation, 0, 0)
+	row, err := p.conn.Query(query, nickname, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var conversation models.Conversation
+		var created time.Time
+		var lastMessageAt pgtype.Timestamptz
+		var lastMessage models.DirectMessage
+		var lastCreated time.Time
+
+		err = row.Scan(&conversation.Id, &created, &lastMessageAt, &conversation.Participants, &conversation.Unread,
+			&lastMessage.Author, &lastCreated, &lastMessage.Id, &lastMessage.Message)
+		if err != nil {
+			return nil, err
+		}
+		conversation.Created = strfmt.DateTime(created.UTC()).String()
+		if lastMessageAt.Status == pgtype.Present {
+			conversation.LastMessageAt = strfmt.DateTime(lastMessageAt.Time.UTC()).String()
+		}
+		if lastMessage.Id != 0 {
+			lastMessage.Conversation = conversation.Id
+			lastMessage.Created = strfmt.DateTime(lastCreated.UTC()).String()
+			conversation.LastMessage = &lastMessage
+		}
+		data = append(data, conversation)
+	}
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) AddDirectMessageForum(message models.DirectMessage) (models.DirectMessage, error) {
+	query := `INSERT INTO direct_message(
+    conversation,
+    author,
+    message)
+	VALUES ($1, $2, $3)
+	RETURNING author, conversation, created, id, message`
+
+	return scanDirectMessageForum(p.conn.QueryRow(query, message.Conversation, message.Author, message.Message))
+}
+
+func (p *postgresForumRepository) GetDirectMessagesForum(conversationID int, cursor int64, limit int,
+	desc bool) ([]models.DirectMessage, error) {
+	query := `SELECT author, conversation, created, id, message FROM direct_message WHERE conversation = $1 `
+	if desc {
+		query += `AND ($2 = 0 OR id < $2) ORDER BY id DESC `
+	} else {
+		query += `AND id > $2 ORDER BY id `
+	}
+	query += `LIMIT $3`
+
+	data := make([]models.DirectMessage, 0, 0)
+	row, err := p.conn.Query(query, conversationID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		message, err := scanDirectMessageForum(row)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, message)
+	}
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) MarkConversationReadForum(conversationID int, nickname string,
+	lastRead int64) error {
+	query := `UPDATE conversation_member SET lastRead = GREATEST(lastRead, $3)
+	WHERE conversation = $1 AND nickname = $2`
+
+	_, err := p.conn.Exec(query, conversationID, nickname, lastRead)
+	return err
+}
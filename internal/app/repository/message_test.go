@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+func TestDirectMessagesForumUnreadAndCursor(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "Alice")
+	addTestUserForum(t, repo, "bob")
+
+	conversation, err := repo.AddConversationForum([]string{"Alice", "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := repo.FindDirectConversationForum("ALICE", "Bob")
+	if err != nil || id != int(conversation.Id) {
+		t.Fatalf("FindDirectConversationForum = %d, %v; want %d", id, err, conversation.Id)
+	}
+
+	var sent []models.DirectMessage
+	for i, author := range []string{"Alice", "Alice", "bob", "Alice"} {
+		message, err := repo.AddDirectMessageForum(models.DirectMessage{Author: author, Conversation: conversation.Id,
+			Message: string(rune('a' + i))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, message)
+	}
+
+	conversations, err := repo.GetConversationsForum("bob", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conversations) != 1 || conversations[0].Unread != 1 || conversations[0].LastMessage == nil ||
+		conversations[0].LastMessage.Id != sent[3].Id {
+		t.Fatalf("bob conversations = %+v, want one with 1 unread and the last message", conversations)
+	}
+	if aliceView, err := repo.GetConversationForum(int(conversation.Id), "alice"); err != nil || aliceView.Unread != 0 {
+		t.Fatalf("alice unread = %d, %v; want 0 after writing the last message", aliceView.Unread, err)
+	}
+
+	page, err := repo.GetDirectMessagesForum(int(conversation.Id), 0, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].Id != sent[0].Id || page[1].Id != sent[1].Id {
+		t.Fatalf("first page = %v, want the two oldest messages", page)
+	}
+	page, err = repo.GetDirectMessagesForum(int(conversation.Id), page[1].Id, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].Id != sent[2].Id || page[1].Id != sent[3].Id {
+		t.Fatalf("second page = %v, want the two newest messages", page)
+	}
+	page, err = repo.GetDirectMessagesForum(int(conversation.Id), sent[2].Id, 10, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].Id != sent[1].Id || page[1].Id != sent[0].Id {
+		t.Fatalf("descending page = %v, want messages older than the cursor", page)
+	}
+
+	if err = repo.MarkConversationReadForum(int(conversation.Id), "bob", sent[3].Id); err != nil {
+		t.Fatal(err)
+	}
+	if bobView, err := repo.GetConversationForum(int(conversation.Id), "bob"); err != nil || bobView.Unread != 0 {
+		t.Fatalf("bob unread after reading = %d, %v; want 0", bobView.Unread, err)
+	}
+
+	addTestUserForum(t, repo, "carol")
+	if _, err = repo.GetConversationForum(int(conversation.Id), "carol"); err == nil {
+		t.Fatal("non-participant can read the conversation")
+	}
+}
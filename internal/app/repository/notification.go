@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"fmt"
+	"github.com/go-openapi/strfmt"
+	"time"
+)
+
+func (p *postgresForumRepository) GetNotificationsForum(nickname string, limit int, since int64,
+	desc, unreadOnly bool) ([]models.Notification, error) {
+	query := `SELECT author, created, forum, id, isRead, kind, nickname, post, thread FROM notification
+	WHERE nickname = $1 `
+
+	if unreadOnly {
+		query += `AND NOT isRead `
+	}
+	if desc {
+		if since > 0 {
+			query += fmt.Sprintf("AND id < %d ", since)
+		}
+		query += `ORDER BY id DESC `
+	} else {
+		if since > 0 {
+			query += fmt.Sprintf("AND id > %d ", since)
+		}
+		query += `ORDER BY id `
+	}
+	query += `LIMIT NULLIF($2, 0)`
+
+	data := make([]models.Notification, 0, 0)
+	row, err := p.conn.Query(query, nickname, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if row != nil {
+			row.Close()
+		}
+	}()
+
+	for row.Next() {
+		var notificationObj models.Notification
+		var created time.Time
+
+		err = row.Scan(&notificationObj.Author, &created, &notificationObj.Forum, &notificationObj.Id,
+			&notificationObj.IsRead, &notificationObj.Kind, &notificationObj.Nickname, &notificationObj.Post,
+			&notificationObj.Thread)
+		if err != nil {
+			return nil, err
+		}
+		notificationObj.Created = strfmt.DateTime(created.UTC()).String()
+		data = append(data, notificationObj)
+	}
+
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) CountUnreadNotificationsForum(nickname string) (int64, error) {
+	query := `SELECT COUNT(*) FROM notification WHERE nickname = $1 AND NOT isRead`
+
+	var unread int64
+	err := p.conn.QueryRow(query, nickname).Scan(&unread)
+	return unread, err
+}
+
+func (p *postgresForumRepository) MarkNotificationsReadForum(nickname string, ids []int64) (int64, error) {
+	query := `UPDATE notification SET isRead = true WHERE nickname = $1 AND NOT isRead`
+
+	var err error
+	if len(ids) > 0 {
+		_, err = p.conn.Exec(query+` AND id = ANY($2)`, nickname, ids)
+	} else {
+		_, err = p.conn.Exec(query, nickname)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return p.CountUnreadNotificationsForum(nickname)
+}
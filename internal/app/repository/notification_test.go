@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+func TestNotificationsForumRepliesAndMentions(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "alice")
+	addTestUserForum(t, repo, "bob")
+	addTestUserForum(t, repo, "carol")
+	addTestForumForum(t, repo, "inbox", "alice")
+	threadObj := addTestThreadForum(t, repo, "inbox", "alice")
+
+	root := addTestPostsForum(t, repo, threadObj, models.Post{Author: "alice", Message: "root"})
+	reply := models.Post{Author: "bob", Message: "thanks @carol and @alice"}
+	reply.Parent.Valid = true
+	reply.Parent.Int64 = root[0].Id
+	addTestPostsForum(t, repo, threadObj, reply)
+
+	tests := []struct {
+		nickname string
+		wantKind string
+	}{
+		{"alice", "reply"},
+		{"carol", "mention"},
+	}
+	for _, tt := range tests {
+		notifications, err := repo.GetNotificationsForum(tt.nickname, 0, 0, false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(notifications) != 1 || notifications[0].Kind != tt.wantKind || notifications[0].Author != "bob" {
+			t.Fatalf("%s notifications = %v, want one %s from bob", tt.nickname, notifications, tt.wantKind)
+		}
+	}
+
+	bobNotifications, err := repo.GetNotificationsForum("bob", 0, 0, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bobNotifications) != 0 {
+		t.Fatalf("author was notified about their own post: %v", bobNotifications)
+	}
+
+	unread, err := repo.MarkNotificationsReadForum("alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unread != 0 {
+		t.Fatalf("unread after marking all read = %d, want 0", unread)
+	}
+	if unread, err = repo.CountUnreadNotificationsForum("carol"); err != nil || unread != 1 {
+		t.Fatalf("carol unread = %d, %v; want 1", unread, err)
+	}
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/markdown"
+	"github.com/go-openapi/strfmt"
+	"github.com/jackc/pgtype"
+	"time"
+)
+
+func (p *postgresForumRepository) addThreadWithPollForum(thread models.Thread, created interface{},
+	forumSlug string) (models.Thread, error) {
+	options := make([]string, 0, len(thread.Poll.Options))
+	for _, option := range thread.Poll.Options {
+		options = append(options, option.Text)
+	}
+
+	query := `WITH new_thread AS (
+		INSERT INTO thread(
+		slug,
+		author,
+		created,
+		message,
+		title,
+		forum,
+		tags,
+		messageHtml,
+		status,
+		statusReason,
+		publishAt)
+		VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6, COALESCE($11::text[], array []::text[]), $12,
+		COALESCE(NULLIF($13, ''), 'published'), NULLIF($14, ''), NULLIF($15, '')::timestamptz) RETURNING *),
+	new_poll AS (
+		INSERT INTO poll(
+		thread,
+		question,
+		multiple,
+		closesAt)
+		SELECT id, $7, $8, NULLIF($9, '')::timestamptz FROM new_thread RETURNING id),
+	new_options AS (
+		INSERT INTO poll_option(
+		poll,
+		position,
+		text)
+		SELECT new_poll.id, o.position, o.text FROM new_poll, unnest($10::text[]) WITH ORDINALITY AS o(text, position))
+	SELECT * FROM new_thread`
+
+	threadObj, err := scanThreadForum(p.conn.QueryRow(query, thread.Slug, thread.Author, created, thread.Message,
+		thread.Title, forumSlug, thread.Poll.Question, thread.Poll.Multiple, thread.Poll.ClosesAt, options,
+		tagsArgForum(thread.Tags), markdown.Render(thread.Message), thread.Status, thread.StatusReason,
+		thread.PublishAt))
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	poll, err := p.GetPollForum(int(threadObj.Id), "")
+	if err != nil {
+		return models.Thread{}, err
+	}
+	threadObj.Poll = &poll
+	return threadObj, nil
+}
+
+func (p *postgresForumRepository) GetPollForum(threadID int, nickname string) (models.Poll, error) {
+	query := `SELECT id, thread, question, multiple, closesAt, voters FROM poll WHERE thread = $1`
+
+	var poll models.Poll
+	var closesAt pgtype.Timestamptz
+	err := p.conn.QueryRow(query, threadID).Scan(&poll.Id, &poll.Thread, &poll.Question, &poll.Multiple,
+		&closesAt, &poll.Voters)
+	if err != nil {
+		return models.Poll{}, err
+	}
+	if closesAt.Status == pgtype.Present {
+		poll.ClosesAt = strfmt.DateTime(closesAt.Time.UTC()).String()
+		poll.Closed = !closesAt.Time.After(time.Now())
+	}
+
+	query = `SELECT id, text, votes FROM poll_option WHERE poll = $1 ORDER BY position`
+
+	rows, err := p.conn.Query(query, poll.Id)
+	if err != nil {
+		return models.Poll{}, err
+	}
+	defer rows.Close()
+
+	poll.Options = make([]models.PollOption, 0)
+	for rows.Next() {
+		var option models.PollOption
+		err = rows.Scan(&option.Id, &option.Text, &option.Votes)
+		if err != nil {
+			return models.Poll{}, err
+		}
+		poll.Options = append(poll.Options, option)
+	}
+	if err = rows.Err(); err != nil {
+		return models.Poll{}, err
+	}
+
+	if nickname == "" {
+		return poll, nil
+	}
+
+	query = `SELECT option FROM poll_vote WHERE poll = $1 AND nickname = $2 ORDER BY option`
+
+	rows, err = p.conn.Query(query, poll.Id, nickname)
+	if err != nil {
+		return models.Poll{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var option int32
+		err = rows.Scan(&option)
+		if err != nil {
+			return models.Poll{}, err
+		}
+		poll.Voted = append(poll.Voted, option)
+	}
+	return poll, rows.Err()
+}
+
+func (p *postgresForumRepository) VotePollForum(ballot models.PollBallot) error {
+	query := `WITH voter AS (
+		INSERT INTO poll_voter(
+		poll,
+		nickname)
+		VALUES ($1, $2) RETURNING poll, nickname)
+	INSERT INTO poll_vote(
+	poll,
+	option,
+	nickname)
+	SELECT voter.poll, o.option, voter.nickname FROM voter, unnest($3::int[]) AS o(option)`
+
+	_, err := p.conn.Exec(query, ballot.Poll, ballot.Nickname, ballot.Options)
+	return err
+}
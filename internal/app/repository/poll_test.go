@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"github.com/jackc/pgx"
+	"testing"
+)
+
+func TestPollForumBallots(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestUserForum(t, repo, "alice")
+	addTestUserForum(t, repo, "bob")
+	addTestForumForum(t, repo, "polls", "author")
+
+	threadObj, err := repo.AddThreadForum(models.Thread{Author: "author", Forum: "polls", Message: "vote",
+		Title: "poll", Poll: &models.Poll{Question: "Which?", Multiple: true,
+			Options: []models.PollOption{{Text: "red"}, {Text: "green"}, {Text: "blue"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	poll := threadObj.Poll
+	if poll == nil || len(poll.Options) != 3 || poll.Options[0].Text != "red" || poll.Options[2].Text != "blue" {
+		t.Fatalf("created poll = %v, want three options in order", poll)
+	}
+
+	red, blue := poll.Options[0].Id, poll.Options[2].Id
+	if err = repo.VotePollForum(models.PollBallot{Nickname: "alice", Options: []int32{red, blue}, Poll: poll.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.VotePollForum(models.PollBallot{Nickname: "bob", Options: []int32{red}, Poll: poll.Id}); err != nil {
+		t.Fatal(err)
+	}
+	err = repo.VotePollForum(models.PollBallot{Nickname: "alice", Options: []int32{red}, Poll: poll.Id})
+	if pgerr, ok := err.(pgx.PgError); !ok || pgerr.Code != "23505" {
+		t.Fatalf("second ballot error = %v, want unique violation", err)
+	}
+
+	result, err := repo.GetPollForum(int(threadObj.Id), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Voters != 2 || result.Options[0].Votes != 2 || result.Options[1].Votes != 0 || result.Options[2].Votes != 1 {
+		t.Fatalf("poll result = %v, want 2 voters and votes 2/0/1", result)
+	}
+	if len(result.Voted) != 2 || result.Voted[0] != red || result.Voted[1] != blue {
+		t.Fatalf("alice voted = %v, want %d and %d", result.Voted, red, blue)
+	}
+
+	if _, err = repo.conn.Exec(`UPDATE poll SET closesAt = now() - interval '1 minute' WHERE id = $1`, poll.Id); err != nil {
+		t.Fatal(err)
+	}
+	addTestUserForum(t, repo, "carol")
+	err = repo.VotePollForum(models.PollBallot{Nickname: "carol", Options: []int32{red}, Poll: poll.Id})
+	if pgerr, ok := err.(pgx.PgError); !ok || pgerr.Code != "00403" {
+		t.Fatalf("ballot on closed poll error = %v, want poll is closed", err)
+	}
+	if result, err = repo.GetPollForum(int(threadObj.Id), ""); err != nil || !result.Closed {
+		t.Fatalf("poll closed = %t, %v; want closed", result.Closed, err)
+	}
+}
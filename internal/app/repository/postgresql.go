@@ -3,21 +3,93 @@ package repository
 import (
 	forum "DbGODZ/internal/app"
 	"DbGODZ/internal/app/models"
+	"DbGODZ/internal/pkg/markdown"
+	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/go-openapi/strfmt"
+	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx"
 	"strings"
 	"time"
 )
 
+type queryer interface {
+	Exec(sql string, arguments ...interface{}) (pgx.CommandTag, error)
+	Query(sql string, args ...interface{}) (*pgx.Rows, error)
+	QueryRow(sql string, args ...interface{}) *pgx.Row
+}
+
 type postgresForumRepository struct {
-	conn *pgx.ConnPool
+	conn queryer
+	pool *pgx.ConnPool
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+const forumColumns = `forum."user", forum.Posts, forum.Slug, forum.Threads, forum.title, forum.parent,
+	forum.position, forum.depth, forum.slowMode, forum.premoderated`
+
+func scanForumForum(row rowScanner) (models.Forum, error) {
+	var forumObj models.Forum
+	var parent sql.NullString
+
+	err := row.Scan(&forumObj.User, &forumObj.Posts, &forumObj.Slug, &forumObj.Threads, &forumObj.Title, &parent,
+		&forumObj.Position, &forumObj.Depth, &forumObj.SlowMode, &forumObj.Premoderated)
+	forumObj.Parent = parent.String
+	return forumObj, err
+}
+
+func scanThreadForum(row rowScanner, extra ...interface{}) (models.Thread, error) {
+	var threadObj models.Thread
+	var created time.Time
+	var lastPostAt pgtype.Timestamptz
+	var statusReason sql.NullString
+	var publishAt pgtype.Timestamptz
+	var edited pgtype.Timestamptz
+
+	dest := append([]interface{}{&threadObj.Author, &created, &threadObj.Forum, &threadObj.Id,
+		&threadObj.Message, &threadObj.Slug, &threadObj.Title, &threadObj.Votes, &threadObj.Posts, &lastPostAt,
+		&threadObj.Views, &threadObj.Tags, &threadObj.MessageHtml, &threadObj.Status, &statusReason, &publishAt,
+		&edited}, extra...)
+	err := row.Scan(dest...)
+	threadObj.Created = strfmt.DateTime(created.UTC()).String()
+	threadObj.StatusReason = statusReason.String
+	if publishAt.Status == pgtype.Present {
+		threadObj.PublishAt = strfmt.DateTime(publishAt.Time.UTC()).String()
+	}
+	if lastPostAt.Status == pgtype.Present {
+		threadObj.LastPostAt = strfmt.DateTime(lastPostAt.Time.UTC()).String()
+	}
+	if edited.Status == pgtype.Present {
+		threadObj.Edited = strfmt.DateTime(edited.Time.UTC()).String()
+	}
+	return threadObj, err
+}
+
+func scanPostForum(row rowScanner) (models.Post, error) {
+	var post models.Post
+	var created time.Time
+	var statusReason sql.NullString
+	var edited pgtype.Timestamptz
+
+	err := row.Scan(&post.Author, &created, &post.Forum, &post.Id, &post.IsEdited, &post.Message,
+		&post.Parent, &post.Thread, &post.Path, &post.Score, &post.Reactions, &post.MessageHtml,
+		&post.Attachments, &post.Status, &statusReason, &edited)
+	post.Created = strfmt.DateTime(created.UTC()).String()
+	post.StatusReason = statusReason.String
+	if edited.Status == pgtype.Present {
+		post.Edited = strfmt.DateTime(edited.Time.UTC()).String()
+	}
+	return post, err
 }
 
 func NewPostgresForumRepository(conn *pgx.ConnPool) forum.Repository {
 	return &postgresForumRepository{
 		conn: conn,
+		pool: conn,
 	}
 }
 
@@ -25,26 +97,25 @@ func (p *postgresForumRepository) AddForum(forum models.Forum) (models.Forum, er
 	query := `INSERT INTO forum(
     "user",
     slug,
-    title)
-	VALUES ($1, $2, $3) RETURNING *`
+    title,
+    parent,
+    position,
+    premoderated)
+	VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6) RETURNING ` + forumColumns
 
 	userObj, err := p.GetByNick(forum.User)
 	if err != nil {
 		return models.Forum{}, err
 	}
 
-	var forumObj models.Forum
-	err = p.conn.QueryRow(query, userObj.Nickname, forum.Slug, forum.Title).Scan(&forumObj.User, &forumObj.Posts, &forumObj.Slug, &forumObj.Threads, &forumObj.Title)
-	return forumObj, err
+	return scanForumForum(p.conn.QueryRow(query, userObj.Nickname, forum.Slug, forum.Title, forum.Parent,
+		forum.Position, forum.Premoderated))
 }
 
 func (p *postgresForumRepository) GetBySlugForum(slug string) (models.Forum, error) {
-	query := `SELECT * FROM forum WHERE LOWER(slug)=LOWER($1)`
+	query := `SELECT ` + forumColumns + ` FROM forum WHERE LOWER(slug)=LOWER($1)`
 
-	var forumObj models.Forum
-	err := p.conn.QueryRow(query, slug).Scan(&forumObj.User, &forumObj.Posts, &forumObj.Slug, &forumObj.Threads, &forumObj.Title)
-
-	return forumObj, err
+	return scanForumForum(p.conn.QueryRow(query, slug))
 }
 
 func (p *postgresForumRepository) AddThreadForum(thread models.Thread) (models.Thread, error) {
@@ -54,55 +125,93 @@ func (p *postgresForumRepository) AddThreadForum(thread models.Thread) (models.T
     created,
     message,
     title,
-	forum)
-	VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6) RETURNING *`
+	forum,
+	tags,
+	messageHtml,
+	status,
+	statusReason,
+	publishAt)
+	VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6, COALESCE($7::text[], array []::text[]), $8,
+	COALESCE(NULLIF($9, ''), 'published'), NULLIF($10, ''), NULLIF($11, '')::timestamptz) RETURNING *`
 
 	forumObj, err := p.GetBySlugForum(thread.Forum)
 	if err != nil {
 		return models.Thread{}, err
 	}
 
-	var threadObj models.Thread
-	var created time.Time
-
+	var created interface{} = time.Time{}
 	if thread.Created != "" {
-		err = p.conn.QueryRow(query, thread.Slug, thread.Author,
-			thread.Created, thread.Message, thread.Title, forumObj.Slug).Scan(&threadObj.Author,
-			&created, &threadObj.Forum, &threadObj.Id, &threadObj.Message, &threadObj.Slug,
-			&threadObj.Title, &threadObj.Votes)
-
-	} else {
-		err = p.conn.QueryRow(query, thread.Slug, thread.Author,
-			time.Time{}, thread.Message, thread.Title, forumObj.Slug).Scan(&threadObj.Author,
-			&created, &threadObj.Forum, &threadObj.Id, &threadObj.Message, &threadObj.Slug,
-			&threadObj.Title, &threadObj.Votes)
+		created = thread.Created
 	}
-	threadObj.Created = strfmt.DateTime(created.UTC()).String()
-	return threadObj, err
+	if thread.Poll != nil {
+		return p.addThreadWithPollForum(thread, created, forumObj.Slug)
+	}
+	return scanThreadForum(p.conn.QueryRow(query, thread.Slug, thread.Author,
+		created, thread.Message, thread.Title, forumObj.Slug, tagsArgForum(thread.Tags), markdown.Render(thread.Message),
+		thread.Status, thread.StatusReason, thread.PublishAt))
 }
 
-func (p *postgresForumRepository) GetThreadsForum(slug string, limit int, since string, desc bool) ([]models.Thread, error) {
-	var whereExpression string
-	var orderExpression string
+func (p *postgresForumRepository) GetThreadsForum(filter models.ThreadFilter) ([]models.Thread, error) {
+	var conditions []string
+	var args []interface{}
 
-	if since != "" && desc {
-		whereExpression = fmt.Sprintf(`LOWER(forum)=LOWER('%s') AND created <= '%s'`, slug, since)
-	} else if since != "" && !desc {
-		whereExpression = fmt.Sprintf(`LOWER(forum)=LOWER('%s') AND created >= '%s'`, slug, since)
-	} else {
-		whereExpression = fmt.Sprintf(`LOWER(forum)=LOWER('%s')`, slug)
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
 	}
-	if desc {
-		orderExpression = `DESC`
-	} else {
-		orderExpression = `ASC`
+
+	conditions = append(conditions, `forum = `+addArg(filter.Forum), `(status = 'published' OR (status = 'pending'
+	AND author = `+addArg(filter.Nickname)+`::citext))`)
+	if filter.Tag != "" {
+		conditions = append(conditions, `tags @> ARRAY[`+addArg(filter.Tag)+`::text]`)
+	}
+
+	selectExpression := `SELECT thread.*`
+	if filter.Nickname != "" {
+		nickname := addArg(filter.Nickname)
+		selectExpression += `, (SELECT COUNT(*) FROM post WHERE post.thread = thread.id AND post.status = 'published' AND post.id >
+		COALESCE((SELECT lastRead FROM thread_read WHERE thread_read.thread = thread.id AND nickname = ` + nickname + `),
+		(SELECT lastRead FROM forum_subscription WHERE forum_subscription.forum = thread.forum
+		AND nickname = ` + nickname + `)))`
+	}
+
+	var orderExpression string
+	switch filter.Sort {
+	case "hot":
+		orderExpression = `(votes + 2 * postCount) /
+		power(extract(EPOCH FROM now() - COALESCE(lastPostAt, created)) / 3600 + 2, 1.5) DESC, id DESC`
+	case "top":
+		if filter.Window != "" {
+			orderExpression = `(SELECT COALESCE(SUM(voice), 0) FROM vote WHERE vote.idThread = thread.id
+			AND vote.voted >= now() - ` + addArg(filter.Window) + `::interval) DESC, votes DESC, id DESC`
+		} else {
+			orderExpression = `votes DESC, id DESC`
+		}
+	case "active":
+		orderExpression = `COALESCE(lastPostAt, created) DESC, id DESC`
+	case "views":
+		orderExpression = `views DESC, id DESC`
+	default:
+		if filter.Since != "" {
+			if filter.Desc {
+				conditions = append(conditions, `created <= `+addArg(filter.Since)+`::timestamptz`)
+			} else {
+				conditions = append(conditions, `created >= `+addArg(filter.Since)+`::timestamptz`)
+			}
+		}
+		if filter.Desc {
+			orderExpression = `created DESC`
+		} else {
+			orderExpression = `created ASC`
+		}
 	}
 
-	query := fmt.Sprintf("SELECT * FROM thread WHERE %s ORDER BY created %s LIMIT NULLIF(%d, 0)",
-		whereExpression, orderExpression, limit)
+	query := fmt.Sprintf("%s FROM thread WHERE %s ORDER BY %s LIMIT NULLIF(%s, 0) OFFSET %s",
+		selectExpression, strings.Join(conditions, " AND "), orderExpression, addArg(filter.Limit),
+		addArg(filter.Offset))
 
 	data := make([]models.Thread, 0, 0)
-	row, err := p.conn.Query(query)
+	row, err := p.conn.Query(query, args...)
 
 	if err != nil {
 		return nil, err
@@ -115,21 +224,40 @@ func (p *postgresForumRepository) GetThreadsForum(slug string, limit int, since
 	}()
 
 	for row.Next() {
-		var threadObj models.Thread
-		var created time.Time
-
-		err = row.Scan(&threadObj.Author, &created, &threadObj.Forum, &threadObj.Id, &threadObj.Message, &threadObj.Slug, &threadObj.Title, &threadObj.Votes)
+		var extra []interface{}
+		var unread int64
+		if filter.Nickname != "" {
+			extra = append(extra, &unread)
+		}
 
+		threadObj, err := scanThreadForum(row, extra...)
 		if err != nil {
 			return nil, err
 		}
-
-		threadObj.Created = strfmt.DateTime(created.UTC()).String()
+		if filter.Nickname != "" {
+			threadObj.Unread = &unread
+		}
 
 		data = append(data, threadObj)
 	}
 
-	return data, err
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) AddThreadViewsForum(views map[int32]int64) error {
+	ids := make([]int32, 0, len(views))
+	counts := make([]int64, 0, len(views))
+	for id, count := range views {
+		ids = append(ids, id)
+		counts = append(counts, count)
+	}
+
+	query := `UPDATE thread SET views = thread.views + v.count
+	FROM unnest($1::int[], $2::bigint[]) AS v(id, count)
+	WHERE thread.id = v.id`
+
+	_, err := p.conn.Exec(query, ids, counts)
+	return err
 }
 
 func (p *postgresForumRepository) CheckThreadExistsForum(slug string) (bool, error) {
@@ -144,27 +272,13 @@ func (p *postgresForumRepository) CheckThreadExistsForum(slug string) (bool, err
 func (p *postgresForumRepository) GetThreadBySlugForum(slug string) (models.Thread, error) {
 	query := `SELECT * FROM thread WHERE LOWER(slug)=LOWER($1)`
 
-	var threadObj models.Thread
-	var created time.Time
-
-	err := p.conn.QueryRow(query, slug).Scan(&threadObj.Author, &created, &threadObj.Forum,
-		&threadObj.Id, &threadObj.Message, &threadObj.Slug, &threadObj.Title, &threadObj.Votes)
-
-	threadObj.Created = strfmt.DateTime(created.UTC()).String()
-	return threadObj, err
+	return scanThreadForum(p.conn.QueryRow(query, slug))
 }
 
 func (p *postgresForumRepository) GetThreadByIDForum(id int) (models.Thread, error) {
 	query := `SELECT * FROM thread WHERE id=$1`
 
-	var threadObj models.Thread
-	var created time.Time
-
-	err := p.conn.QueryRow(query, id).Scan(&threadObj.Author, &created, &threadObj.Forum,
-		&threadObj.Id, &threadObj.Message, &threadObj.Slug, &threadObj.Title, &threadObj.Votes)
-	threadObj.Created = strfmt.DateTime(created.UTC()).String()
-
-	return threadObj, err
+	return scanThreadForum(p.conn.QueryRow(query, id))
 }
 
 func (p *postgresForumRepository) GetThreadIDBySlugForum(slug string) (int, error) {
@@ -198,7 +312,10 @@ func (p *postgresForumRepository) AddPostsForum(posts []models.Post, threadID in
                  message,
                  parent,
 				 thread,
-				 forum) VALUES `
+				 forum,
+				 messageHtml,
+				 status,
+				 statusReason) VALUES `
 	data := make([]models.Post, 0, 0)
 	if len(posts) == 0 {
 		return data, nil
@@ -215,10 +332,11 @@ func (p *postgresForumRepository) AddPostsForum(posts []models.Post, threadID in
 	i := 1
 	for _, element := range posts {
 		valuesNames = append(valuesNames, fmt.Sprintf(
-			"($%d, $%d, $%d, nullif($%d, 0), $%d, $%d)",
-			i, i+1, i+2, i+3, i+4, i+5))
-		i += 6
-		values = append(values, element.Author, timeCreated, element.Message, element.Parent, threadID, slug)
+			"($%d, $%d, $%d, nullif($%d, 0), $%d, $%d, $%d, COALESCE(NULLIF($%d, ''), 'published'), NULLIF($%d, ''))",
+			i, i+1, i+2, i+3, i+4, i+5, i+6, i+7, i+8))
+		i += 9
+		values = append(values, element.Author, timeCreated, element.Message, element.Parent, threadID, slug,
+			markdown.Render(element.Message), element.Status, element.StatusReason)
 	}
 
 	query += strings.Join(valuesNames[:], ",")
@@ -236,16 +354,10 @@ func (p *postgresForumRepository) AddPostsForum(posts []models.Post, threadID in
 
 	for row.Next() {
 
-		var post models.Post
-		var created time.Time
-
-		err = row.Scan(&post.Author, &created, &post.Forum, &post.Id, &post.IsEdited,
-			&post.Message, &post.Parent, &post.Thread, &post.Path)
-
+		post, err := scanPostForum(row)
 		if err != nil {
 			return data, err
 		}
-		post.Created = strfmt.DateTime(created.UTC()).String()
 		data = append(data, post)
 
 	}
@@ -253,27 +365,99 @@ func (p *postgresForumRepository) AddPostsForum(posts []models.Post, threadID in
 	return data, err
 }
 
-func (p *postgresForumRepository) AddVoteForum(vote models.Vote) error {
-	query := `INSERT INTO vote(
-				nickname,  
-				voice,     
-				idThread)
-				VALUES ($1, $2, NULLIF($3, 0))`
+func (p *postgresForumRepository) VoteForum(vote models.Vote, thread models.Thread) (models.Thread, error) {
+	var threadExpression string
+	var threadKey interface{}
 
-	_, err := p.conn.Exec(query, vote.Nickname, vote.Voice, vote.IdThread)
-	return err
+	if thread.Id > 0 {
+		threadExpression = `id = $2`
+		threadKey = thread.Id
+	} else {
+		threadExpression = `LOWER(slug) = LOWER($2)`
+		threadKey = thread.Slug.String
+	}
+
+	var threadID int
+	var err error
+	if vote.Voice == 0 {
+		query := fmt.Sprintf(`WITH target AS (SELECT id FROM thread WHERE %s),
+		removed AS (DELETE FROM vote WHERE nickname = $1 AND idThread = (SELECT id FROM target))
+		SELECT id FROM target`, threadExpression)
+		err = p.conn.QueryRow(query, vote.Nickname, threadKey).Scan(&threadID)
+	} else {
+		query := fmt.Sprintf(`INSERT INTO vote(
+				nickname,
+				idThread,
+				voice)
+				SELECT $1, id, $3 FROM thread WHERE %s
+				ON CONFLICT (nickname, idThread) DO UPDATE SET voice = EXCLUDED.voice, voted = now()
+				RETURNING idThread`, threadExpression)
+		err = p.conn.QueryRow(query, vote.Nickname, threadKey, vote.Voice).Scan(&threadID)
+	}
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	return p.GetThreadByIDForum(threadID)
 }
 
-func (p *postgresForumRepository) UpdateVoteForum(vote models.Vote) error {
-	query := `UPDATE vote SET voice=$1 WHERE LOWER(nickname) = LOWER($2) AND idThread = $3`
-	_, err := p.conn.Exec(query, vote.Voice, vote.Nickname, vote.IdThread)
-	return err
+func (p *postgresForumRepository) GetVotesForum(threadID, limit int, since string, desc bool) ([]models.Vote, error) {
+	query := `SELECT nickname, voice, idThread FROM vote WHERE idThread = $1 `
+
+	if desc {
+		if since != "" {
+			query += `AND nickname < $3 `
+		}
+		query += `ORDER BY nickname DESC `
+	} else {
+		if since != "" {
+			query += `AND nickname > $3 `
+		}
+		query += `ORDER BY nickname `
+	}
+	query += `LIMIT NULLIF($2, 0)`
+
+	args := []interface{}{threadID, limit}
+	if since != "" {
+		args = append(args, since)
+	}
+
+	data := make([]models.Vote, 0, 0)
+	row, err := p.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if row != nil {
+			row.Close()
+		}
+	}()
+
+	for row.Next() {
+		var voteObj models.Vote
+
+		err = row.Scan(&voteObj.Nickname, &voteObj.Voice, &voteObj.IdThread)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, voteObj)
+	}
+
+	return data, row.Err()
+}
+
+func visiblePostsForum(viewer string) string {
+	return fmt.Sprintf(`(post.status = 'published' OR (post.status = 'pending' AND post.author = %s::citext))`, viewer)
 }
 
 func (p *postgresForumRepository) getPostsFlatForum(threadID, limit, since int,
-	desc bool) ([]models.Post, error) {
+	desc bool, viewer string) ([]models.Post, error) {
 
-	query := `SELECT * FROM post WHERE thread=$1 `
+	query := `SELECT * FROM post WHERE thread=$1 AND ` + visiblePostsForum("$3") + ` `
+	args := []interface{}{threadID, limit, viewer}
+	if viewer != "" {
+		query += `AND NOT EXISTS (SELECT 1 FROM user_block WHERE nickname = $3 AND blocked = post.author) `
+	}
 
 	if desc {
 		if since > 0 {
@@ -289,7 +473,7 @@ func (p *postgresForumRepository) getPostsFlatForum(threadID, limit, since int,
 	query += `LIMIT NULLIF($2, 0)`
 	var posts []models.Post
 
-	row, err := p.conn.Query(query, threadID, limit)
+	row, err := p.conn.Query(query, args...)
 
 	if err != nil {
 		return posts, err
@@ -301,16 +485,36 @@ func (p *postgresForumRepository) getPostsFlatForum(threadID, limit, since int,
 	}()
 
 	for row.Next() {
-		var post models.Post
-		var created time.Time
+		post, err := scanPostForum(row)
+		if err != nil {
+			return posts, err
+		}
+		posts = append(posts, post)
+
+	}
+	return posts, err
+}
+
+func (p *postgresForumRepository) GetForumPostsSinceForum(slug string, since int64) ([]models.Post, error) {
+	query := `SELECT * FROM post WHERE forum=$1 AND id > $2 AND status = 'published' ORDER BY id`
 
-		err = row.Scan(&post.Author, &created, &post.Forum, &post.Id, &post.IsEdited, &post.Message,
-			&post.Parent, &post.Thread, &post.Path)
+	var posts []models.Post
+	row, err := p.conn.Query(query, slug, since)
+
+	if err != nil {
+		return posts, err
+	}
+	defer func() {
+		if row != nil {
+			row.Close()
+		}
+	}()
 
+	for row.Next() {
+		post, err := scanPostForum(row)
 		if err != nil {
 			return posts, err
 		}
-		post.Created = strfmt.DateTime(created.UTC()).String()
 		posts = append(posts, post)
 
 	}
@@ -318,7 +522,7 @@ func (p *postgresForumRepository) getPostsFlatForum(threadID, limit, since int,
 }
 
 func (p *postgresForumRepository) getPostsTreeForum(threadID, limit, since int,
-	desc bool) ([]models.Post, error) {
+	desc bool, viewer string) ([]models.Post, error) {
 	var query string
 	sinceQuery := ""
 	if since != 0 {
@@ -331,13 +535,15 @@ func (p *postgresForumRepository) getPostsTreeForum(threadID, limit, since int,
 	}
 	if desc {
 		query = fmt.Sprintf(
-			`SELECT * FROM post WHERE thread=$1 %s ORDER BY path DESC, id DESC LIMIT NULLIF($2, 0);`, sinceQuery)
+			`SELECT * FROM post WHERE thread=$1 AND %s %s ORDER BY path DESC, id DESC LIMIT NULLIF($2, 0);`,
+			visiblePostsForum("$3"), sinceQuery)
 	} else {
 		query = fmt.Sprintf(
-			`SELECT * FROM post WHERE thread=$1 %s ORDER BY path, id LIMIT NULLIF($2, 0);`, sinceQuery)
+			`SELECT * FROM post WHERE thread=$1 AND %s %s ORDER BY path, id LIMIT NULLIF($2, 0);`,
+			visiblePostsForum("$3"), sinceQuery)
 	}
 	var posts []models.Post
-	row, err := p.conn.Query(query, threadID, limit)
+	row, err := p.conn.Query(query, threadID, limit, viewer)
 
 	if err != nil {
 		return posts, err
@@ -349,16 +555,10 @@ func (p *postgresForumRepository) getPostsTreeForum(threadID, limit, since int,
 	}()
 
 	for row.Next() {
-		var post models.Post
-		var created time.Time
-
-		err = row.Scan(&post.Author, &created, &post.Forum, &post.Id, &post.IsEdited, &post.Message,
-			&post.Parent, &post.Thread, &post.Path)
-
+		post, err := scanPostForum(row)
 		if err != nil {
 			return posts, err
 		}
-		post.Created = strfmt.DateTime(created.UTC()).String()
 		posts = append(posts, post)
 
 	}
@@ -366,7 +566,7 @@ func (p *postgresForumRepository) getPostsTreeForum(threadID, limit, since int,
 }
 
 func (p *postgresForumRepository) getPostsParentTreeForum(threadID, limit, since int,
-	desc bool) ([]models.Post, error) {
+	desc bool, viewer string) ([]models.Post, error) {
 	var query string
 	sinceQuery := ""
 	if since != 0 {
@@ -379,7 +579,7 @@ func (p *postgresForumRepository) getPostsParentTreeForum(threadID, limit, since
 	}
 
 	parentsQuery := fmt.Sprintf(
-		`SELECT id FROM post WHERE thread = $1 AND parent IS NULL %s`, sinceQuery)
+		`SELECT id FROM post WHERE thread = $1 AND parent IS NULL AND %s %s`, visiblePostsForum("$2"), sinceQuery)
 
 	if desc {
 		parentsQuery += `ORDER BY id DESC`
@@ -387,17 +587,19 @@ func (p *postgresForumRepository) getPostsParentTreeForum(threadID, limit, since
 			parentsQuery += fmt.Sprintf(` LIMIT %d`, limit)
 		}
 		query = fmt.Sprintf(
-			`SELECT * FROM post WHERE path[1] IN (%s) ORDER BY path[1] DESC, path, id;`, parentsQuery)
+			`SELECT * FROM post WHERE path[1] IN (%s) AND %s ORDER BY path[1] DESC, path, id;`,
+			parentsQuery, visiblePostsForum("$2"))
 	} else {
 		parentsQuery += `ORDER BY id`
 		if limit > 0 {
 			parentsQuery += fmt.Sprintf(` LIMIT %d`, limit)
 		}
 		query = fmt.Sprintf(
-			`SELECT * FROM post WHERE path[1] IN (%s) ORDER BY path,id;`, parentsQuery)
+			`SELECT * FROM post WHERE path[1] IN (%s) AND %s ORDER BY path,id;`, parentsQuery,
+			visiblePostsForum("$2"))
 	}
 	var posts []models.Post
-	row, err := p.conn.Query(query, threadID)
+	row, err := p.conn.Query(query, threadID, viewer)
 
 	if err != nil {
 		return posts, err
@@ -410,16 +612,10 @@ func (p *postgresForumRepository) getPostsParentTreeForum(threadID, limit, since
 	}()
 
 	for row.Next() {
-		var post models.Post
-		var created time.Time
-
-		err = row.Scan(&post.Author, &created, &post.Forum, &post.Id, &post.IsEdited, &post.Message,
-			&post.Parent, &post.Thread, &post.Path)
-
+		post, err := scanPostForum(row)
 		if err != nil {
 			return posts, err
 		}
-		post.Created = strfmt.DateTime(created.UTC()).String()
 		posts = append(posts, post)
 
 	}
@@ -427,7 +623,7 @@ func (p *postgresForumRepository) getPostsParentTreeForum(threadID, limit, since
 }
 
 func (p *postgresForumRepository) GetPostsForum(postSlugOrId models.Thread, limit, since int,
-	sort string, desc bool) ([]models.Post, error) {
+	sort string, desc bool, viewer string) ([]models.Post, error) {
 	var err error
 	threadId := 0
 	if postSlugOrId.Id <= 0 {
@@ -439,26 +635,37 @@ func (p *postgresForumRepository) GetPostsForum(postSlugOrId models.Thread, limi
 		threadId = int(postSlugOrId.Id)
 	}
 
+	var posts []models.Post
 	switch sort {
 	case "flat":
-		return p.getPostsFlatForum(threadId, limit, since, desc)
+		return p.getPostsFlatForum(threadId, limit, since, desc, viewer)
 	case "tree":
-		return p.getPostsTreeForum(threadId, limit, since, desc)
+		posts, err = p.getPostsTreeForum(threadId, limit, since, desc, viewer)
 	case "parent_tree":
-		return p.getPostsParentTreeForum(threadId, limit, since, desc)
+		posts, err = p.getPostsParentTreeForum(threadId, limit, since, desc, viewer)
 	default:
 		return nil, errors.New("THERE IS NO SORT WITH THIS NAME")
 	}
+	if err != nil || viewer == "" || len(posts) == 0 {
+		return posts, err
+	}
+
+	blocked, err := p.getBlockedSetForum(viewer)
+	if err != nil {
+		return nil, err
+	}
+	for i := range posts {
+		if blocked[strings.ToLower(posts[i].Author)] {
+			collapsePostForum(&posts[i])
+		}
+	}
+	return posts, nil
 }
 
 func (p *postgresForumRepository) GetPostForum(id int, related []string) (map[string]interface{}, error) {
 	query := `SELECT * FROM post WHERE id = $1;`
-	var post models.Post
-	var created time.Time
 
-	err := p.conn.QueryRow(query, id).Scan(&post.Author, &created, &post.Forum,
-		&post.Id, &post.IsEdited, &post.Message, &post.Parent, &post.Thread, &post.Path)
-	post.Created = strfmt.DateTime(created.UTC()).String()
+	post, err := scanPostForum(p.conn.QueryRow(query, id))
 
 	returnMap := map[string]interface{}{
 		"post": post,
@@ -491,7 +698,8 @@ func (p *postgresForumRepository) GetPostForum(id int, related []string) (map[st
 }
 
 func (p *postgresForumRepository) UpdatePostForum(newPost models.Post) (models.Post, error) {
-	query := `UPDATE post SET message = $1, isEdited = true WHERE id = $2 RETURNING *;`
+	query := `UPDATE post SET message = $1, messageHtml = $3, isEdited = true, edited = now(), status = COALESCE(NULLIF($4, ''), status),
+	statusReason = CASE WHEN $4 = '' THEN statusReason ELSE NULLIF($5, '') END WHERE id = $2 RETURNING *;`
 
 	oldPost, err := p.GetPostForum(int(newPost.Id), []string{})
 	if err != nil {
@@ -503,47 +711,28 @@ func (p *postgresForumRepository) UpdatePostForum(newPost models.Post) (models.P
 
 	if newPost.Message == "" {
 		query := `SELECT * FROM post WHERE id = $1`
-		var post models.Post
-		var created time.Time
 
-		err := p.conn.QueryRow(query, newPost.Id).Scan(&post.Author, &created,
-			&post.Forum, &post.Id, &post.IsEdited, &post.Message, &post.Parent, &post.Thread, &post.Path)
-
-		post.Created = strfmt.DateTime(created.UTC()).String()
-		return post, err
+		return scanPostForum(p.conn.QueryRow(query, newPost.Id))
 	}
 
-	var post models.Post
-	var created time.Time
-
-	err = p.conn.QueryRow(query, newPost.Message, newPost.Id).Scan(&post.Author, &created,
-		&post.Forum, &post.Id, &post.IsEdited, &post.Message, &post.Parent, &post.Thread, &post.Path)
-	post.Created = strfmt.DateTime(created.UTC()).String()
-
-	return post, err
+	return scanPostForum(p.conn.QueryRow(query, newPost.Message, newPost.Id, markdown.Render(newPost.Message),
+		newPost.Status, newPost.StatusReason))
 }
 
 func (p *postgresForumRepository) UpdateThreadForum(newThread models.Thread) (models.Thread, error) {
-	query := `UPDATE thread SET message=COALESCE(NULLIF($1, ''), message), title=COALESCE(NULLIF($2, ''), title) WHERE `
+	query := `UPDATE thread SET message=COALESCE(NULLIF($1, ''), message), title=COALESCE(NULLIF($2, ''), title),
+	tags=COALESCE($3::text[], tags), messageHtml=CASE WHEN $1 = '' THEN messageHtml ELSE $4 END,
+	edited=CASE WHEN $1 = '' AND $2 = '' AND $3::text[] IS NULL THEN edited ELSE now() END WHERE `
 
+	messageHtml := markdown.Render(newThread.Message)
 	if newThread.Id > 0 {
-		query += `id = $3 RETURNING *`
-		var threadObj models.Thread
-		var created time.Time
-		err := p.conn.QueryRow(query, newThread.Message, newThread.Title, newThread.Id).Scan(
-			&threadObj.Author, &created, &threadObj.Forum, &threadObj.Id, &threadObj.Message, &threadObj.Slug,
-			&threadObj.Title, &threadObj.Votes)
-		threadObj.Created = strfmt.DateTime(created.UTC()).String()
-		return threadObj, err
+		query += `id = $5 RETURNING *`
+		return scanThreadForum(p.conn.QueryRow(query, newThread.Message, newThread.Title, tagsArgForum(newThread.Tags),
+			messageHtml, newThread.Id))
 	} else {
-		query += `LOWER(slug) = LOWER($3) RETURNING *`
-		var threadObj models.Thread
-		var created time.Time
-		err := p.conn.QueryRow(query, newThread.Message, newThread.Title, newThread.Slug).Scan(
-			&threadObj.Author, &created, &threadObj.Forum, &threadObj.Id, &threadObj.Message, &threadObj.Slug,
-			&threadObj.Title, &threadObj.Votes)
-		threadObj.Created = strfmt.DateTime(created.UTC()).String()
-		return threadObj, err
+		query += `LOWER(slug) = LOWER($5) RETURNING *`
+		return scanThreadForum(p.conn.QueryRow(query, newThread.Message, newThread.Title, tagsArgForum(newThread.Tags),
+			messageHtml, newThread.Slug))
 	}
 }
 
@@ -573,9 +762,16 @@ func (p *postgresForumRepository) GetServiceStatusForum() (map[string]int, error
 }
 
 func (p *postgresForumRepository) ClearDatabaseForum() error {
-	query := `TRUNCATE users, forum, thread, post, vote, users_forum;`
+	_, err := p.conn.Exec(`UPDATE attachment SET post = NULL WHERE post IS NOT NULL`)
+	if err != nil {
+		return err
+	}
+
+	query := `TRUNCATE users, forum, thread, post, vote, users_forum, moderator, report, webhook, outbox, notification,
+		thread_subscription, forum_subscription, thread_read, post_vote, post_reaction, user_karma, poll, poll_option, poll_voter, poll_vote,
+		conversation, conversation_member, direct_message, user_block, thread_mute, rate_bucket, forum_blocklist;`
 
-	_, err := p.conn.Exec(query)
+	_, err = p.conn.Exec(query)
 	return err
 }
 
@@ -592,7 +788,7 @@ func (p *postgresForumRepository) Add(user models.User) error {
 }
 
 func (p *postgresForumRepository) GetByNickAndEmail(nickname, email string) ([]models.User, error) {
-	query := `SELECT * FROM users WHERE LOWER(Nickname)=LOWER($1) OR Email=$2`
+	query := `SELECT About, Email, FullName, Nickname, Karma FROM users WHERE LOWER(Nickname)=LOWER($1) OR Email=$2`
 
 	var data []models.User
 
@@ -612,7 +808,7 @@ func (p *postgresForumRepository) GetByNickAndEmail(nickname, email string) ([]m
 
 		var u models.User
 
-		err = row.Scan(&u.About, &u.Email, &u.FullName, &u.Nickname)
+		err = row.Scan(&u.About, &u.Email, &u.FullName, &u.Nickname, &u.Karma)
 
 		if err != nil {
 			return nil, err
@@ -625,10 +821,10 @@ func (p *postgresForumRepository) GetByNickAndEmail(nickname, email string) ([]m
 }
 
 func (p *postgresForumRepository) GetByNick(nickname string) (models.User, error) {
-	query := `SELECT * FROM users WHERE LOWER(Nickname)=LOWER($1)`
+	query := `SELECT About, Email, FullName, Nickname, Karma FROM users WHERE LOWER(Nickname)=LOWER($1)`
 
 	var userObj models.User
-	err := p.conn.QueryRow(query, nickname).Scan(&userObj.About, &userObj.Email, &userObj.FullName, &userObj.Nickname)
+	err := p.conn.QueryRow(query, nickname).Scan(&userObj.About, &userObj.Email, &userObj.FullName, &userObj.Nickname, &userObj.Karma)
 	return userObj, err
 }
 
@@ -637,10 +833,10 @@ func (p *postgresForumRepository) Update(user models.User) (models.User, error)
                  about=COALESCE(NULLIF($1, ''), about),
                  email=COALESCE(NULLIF($2, ''), email),
                  fullname=COALESCE(NULLIF($3, ''), fullname) 
-	WHERE LOWER(nickname) = LOWER($4) RETURNING *`
+	WHERE LOWER(nickname) = LOWER($4) RETURNING About, Email, FullName, Nickname, Karma`
 
 	var userObj models.User
-	err := p.conn.QueryRow(query, user.About, user.Email, user.FullName, user.Nickname).Scan(&userObj.About, &userObj.Email, &userObj.FullName, &userObj.Nickname)
+	err := p.conn.QueryRow(query, user.About, user.Email, user.FullName, user.Nickname).Scan(&userObj.About, &userObj.Email, &userObj.FullName, &userObj.Nickname, &userObj.Karma)
 	return userObj, err
 }
 
@@ -648,18 +844,18 @@ func (p *postgresForumRepository) GetUsersByForum(slug string, limit int, since
 	var query string
 	if desc {
 		if since != "" {
-			query = fmt.Sprintf(`SELECT users.about, users.Email, users.FullName, users.Nickname FROM users
+			query = fmt.Sprintf(`SELECT users.about, users.Email, users.FullName, users.Nickname, users.Karma FROM users
     	inner join users_forum uf on users.Nickname = uf.nickname
         WHERE uf.slug =$1 AND uf.nickname < '%s'
         ORDER BY lower(users.Nickname) DESC LIMIT NULLIF($2, 0)`, since)
 		} else {
-			query = `SELECT users.about, users.Email, users.FullName, users.Nickname FROM users
+			query = `SELECT users.about, users.Email, users.FullName, users.Nickname, users.Karma FROM users
     	inner join users_forum uf on users.Nickname = uf.nickname
         WHERE uf.slug =$1
         ORDER BY lower(users.Nickname) DESC LIMIT NULLIF($2, 0)`
 		}
 	} else {
-		query = fmt.Sprintf(`SELECT users.about, users.Email, users.FullName, users.Nickname FROM users
+		query = fmt.Sprintf(`SELECT users.about, users.Email, users.FullName, users.Nickname, users.Karma FROM users
     	inner join users_forum uf on users.Nickname = uf.nickname
         WHERE uf.slug =$1 AND uf.nickname > '%s'
         ORDER BY lower(users.Nickname) LIMIT NULLIF($2, 0)`, since)
@@ -681,7 +877,7 @@ func (p *postgresForumRepository) GetUsersByForum(slug string, limit int, since
 
 		var u models.User
 
-		err = row.Scan(&u.About, &u.Email, &u.FullName, &u.Nickname)
+		err = row.Scan(&u.About, &u.Email, &u.FullName, &u.Nickname, &u.Karma)
 
 		if err != nil {
 			return data, err
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+func TestPendingPostsForumVisibility(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "alice")
+	addTestUserForum(t, repo, "bob")
+	addTestForumForum(t, repo, "forum", "alice")
+	threadObj := addTestThreadForum(t, repo, "forum", "alice")
+	posts := addTestPostsForum(t, repo, threadObj,
+		models.Post{Author: "alice", Message: "published"},
+		models.Post{Author: "bob", Message: "pending", Status: "pending", StatusReason: "premoderation"})
+
+	forumObj, err := repo.GetBySlugForum("forum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forumObj.Posts != 1 {
+		t.Fatalf("forum posts = %d, want pending post excluded", forumObj.Posts)
+	}
+
+	tests := []struct {
+		viewer string
+		want   int
+	}{
+		{"", 1},
+		{"alice", 1},
+		{"BOB", 2},
+	}
+	for _, sort := range []string{"flat", "tree", "parent_tree"} {
+		for _, tt := range tests {
+			list, err := repo.GetPostsForum(models.Thread{Id: threadObj.Id}, 0, 0, sort, false, tt.viewer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list) != tt.want {
+				t.Fatalf("%s as %q: posts = %v, want %d", sort, tt.viewer, list, tt.want)
+			}
+		}
+	}
+
+	approved, err := repo.SetPostStatusForum(posts[1].Id, "published")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approved.Status != "published" || approved.StatusReason != "" {
+		t.Fatalf("approved post = %+v", approved)
+	}
+	if forumObj, err = repo.GetBySlugForum("forum"); err != nil {
+		t.Fatal(err)
+	}
+	if forumObj.Posts != 2 {
+		t.Fatalf("forum posts after approval = %d, want 2", forumObj.Posts)
+	}
+	if _, err = repo.SetPostStatusForum(posts[1].Id, "rejected"); err == nil {
+		t.Fatal("status change of a published post succeeded")
+	}
+}
+
+func TestPendingThreadsForumVisibility(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "alice")
+	addTestUserForum(t, repo, "bob")
+	addTestForumForum(t, repo, "forum", "alice")
+	addTestThreadForum(t, repo, "forum", "alice")
+	pending, err := repo.AddThreadForum(models.Thread{Author: "bob", Forum: "forum", Message: "message",
+		Title: "pending", Status: "pending", StatusReason: "premoderation"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forumObj, err := repo.GetBySlugForum("forum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forumObj.Threads != 1 {
+		t.Fatalf("forum threads = %d, want pending thread excluded", forumObj.Threads)
+	}
+
+	for viewer, want := range map[string]int{"": 1, "alice": 1, "Bob": 2} {
+		threads, err := repo.GetThreadsForum(models.ThreadFilter{Forum: "forum", Nickname: viewer})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(threads) != want {
+			t.Fatalf("threads as %q = %v, want %d", viewer, threads, want)
+		}
+	}
+
+	if _, err = repo.SetThreadStatusForum(int(pending.Id), "published"); err != nil {
+		t.Fatal(err)
+	}
+	if forumObj, err = repo.GetBySlugForum("forum"); err != nil {
+		t.Fatal(err)
+	}
+	if forumObj.Threads != 2 {
+		t.Fatalf("forum threads after approval = %d, want 2", forumObj.Threads)
+	}
+}
+
+func TestGetSubscriptionsForumHidesOthersPendingThreads(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "alice")
+	addTestUserForum(t, repo, "bob")
+	addTestForumForum(t, repo, "forum", "alice")
+	published := addTestThreadForum(t, repo, "forum", "alice")
+	ids := []int32{published.Id}
+	for _, status := range []string{"pending", "rejected"} {
+		threadObj, err := repo.AddThreadForum(models.Thread{Author: "bob", Forum: "forum", Message: "message",
+			Title: status, Status: status, StatusReason: "premoderation"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, threadObj.Id)
+	}
+
+	for _, nickname := range []string{"alice", "bob"} {
+		for _, id := range ids {
+			if err := repo.AddThreadSubscriptionForum(nickname, int(id)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for nickname, want := range map[string]int{"alice": 1, "bob": 2} {
+		subscriptions, err := repo.GetSubscriptionsForum(nickname, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(subscriptions.Threads) != want {
+			t.Fatalf("%s subscriptions = %v, want %d threads", nickname, subscriptions.Threads, want)
+		}
+		for _, threadObj := range subscriptions.Threads {
+			if threadObj.Status == "rejected" {
+				t.Fatalf("%s subscriptions include a rejected thread", nickname)
+			}
+		}
+	}
+}
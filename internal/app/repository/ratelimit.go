@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"time"
+)
+
+func (p *postgresForumRepository) TakeRateTokensForum(key string, rate, burst, cost float64) (float64, error) {
+	query := `WITH taken AS (
+		INSERT INTO rate_bucket AS b (
+		key,
+		tokens)
+		VALUES ($1, $3::float8 - $4::float8)
+		ON CONFLICT (key) DO UPDATE
+		SET tokens = LEAST($3::float8, b.tokens + extract(EPOCH FROM now() - b.updated) * $2::float8) - $4::float8,
+			updated = now()
+		WHERE LEAST($3::float8, b.tokens + extract(EPOCH FROM now() - b.updated) * $2::float8) >= $4::float8
+		RETURNING 0::float8 AS wait)
+	SELECT wait FROM taken
+	UNION ALL
+	SELECT ($4::float8 - LEAST($3::float8, tokens + extract(EPOCH FROM now() - updated) * $2::float8)) / $2::float8
+	FROM rate_bucket WHERE key = $1 AND NOT EXISTS(SELECT 1 FROM taken)`
+
+	var wait float64
+	err := p.conn.QueryRow(query, key, rate, burst, cost).Scan(&wait)
+	return wait, err
+}
+
+func (p *postgresForumRepository) RefundRateTokensForum(key string, burst, cost float64) error {
+	query := `UPDATE rate_bucket SET tokens = LEAST($2::float8, tokens + $3::float8) WHERE key = $1`
+
+	_, err := p.conn.Exec(query, key, burst, cost)
+	return err
+}
+
+func (p *postgresForumRepository) PruneRateBucketsForum(idle time.Duration) error {
+	query := `DELETE FROM rate_bucket WHERE updated < now() - $1::bigint * interval '1 millisecond'`
+
+	_, err := p.conn.Exec(query, idle.Nanoseconds()/int64(time.Millisecond))
+	return err
+}
+
+func (p *postgresForumRepository) GetThreadSlowModeForum(threadID int) (string, int32, error) {
+	query := `SELECT forum.slug, forum.slowMode FROM thread JOIN forum ON forum.slug = thread.forum
+	WHERE thread.id = $1`
+
+	var slug string
+	var seconds int32
+	err := p.conn.QueryRow(query, threadID).Scan(&slug, &seconds)
+	return slug, seconds, err
+}
+
+func (p *postgresForumRepository) SetSlowModeForum(slug string, seconds int32) (models.Forum, error) {
+	query := `UPDATE forum SET slowMode = $2 WHERE slug = $1 RETURNING ` + forumColumns
+
+	return scanForumForum(p.conn.QueryRow(query, slug, seconds))
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+)
+
+func (p *postgresForumRepository) VotePostForum(vote models.PostVote) error {
+	var err error
+	if vote.Voice == 0 {
+		query := `DELETE FROM post_vote WHERE post = $1 AND nickname = $2`
+		_, err = p.conn.Exec(query, vote.IdPost, vote.Nickname)
+		return err
+	}
+
+	query := `INSERT INTO post_vote(
+    post,
+    nickname,
+    voice)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (post, nickname) DO UPDATE SET voice = EXCLUDED.voice WHERE post_vote.voice <> EXCLUDED.voice`
+
+	_, err = p.conn.Exec(query, vote.IdPost, vote.Nickname, vote.Voice)
+	return err
+}
+
+func (p *postgresForumRepository) AddReactionForum(reaction models.Reaction) error {
+	query := `INSERT INTO post_reaction(
+    post,
+    nickname,
+    emoji)
+	VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+
+	_, err := p.conn.Exec(query, reaction.IdPost, reaction.Nickname, reaction.Emoji)
+	return err
+}
+
+func (p *postgresForumRepository) DeleteReactionForum(reaction models.Reaction) error {
+	query := `DELETE FROM post_reaction WHERE post = $1 AND nickname = $2 AND emoji = $3`
+
+	_, err := p.conn.Exec(query, reaction.IdPost, reaction.Nickname, reaction.Emoji)
+	return err
+}
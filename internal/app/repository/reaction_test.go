@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+func getTestPostForum(t *testing.T, repo *postgresForumRepository, id int64) models.Post {
+	t.Helper()
+	related, err := repo.GetPostForum(int(id), []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return related["post"].(models.Post)
+}
+
+func TestVotePostForumScore(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestUserForum(t, repo, "alice")
+	addTestUserForum(t, repo, "bob")
+	addTestForumForum(t, repo, "scores", "author")
+	threadObj := addTestThreadForum(t, repo, "scores", "author")
+	postObj := addTestPostsForum(t, repo, threadObj, models.Post{Author: "author", Message: "vote"})[0]
+
+	steps := []struct {
+		vote      models.PostVote
+		wantScore int32
+	}{
+		{models.PostVote{Nickname: "alice", Voice: 1}, 1},
+		{models.PostVote{Nickname: "alice", Voice: 1}, 1},
+		{models.PostVote{Nickname: "bob", Voice: -1}, 0},
+		{models.PostVote{Nickname: "alice", Voice: -1}, -2},
+		{models.PostVote{Nickname: "bob", Voice: 0}, -1},
+		{models.PostVote{Nickname: "alice", Voice: 0}, 0},
+	}
+	for _, step := range steps {
+		step.vote.IdPost = postObj.Id
+		if err := repo.VotePostForum(step.vote); err != nil {
+			t.Fatal(err)
+		}
+		if score := getTestPostForum(t, repo, postObj.Id).Score; score != step.wantScore {
+			t.Fatalf("after %s votes %d: score = %d, want %d", step.vote.Nickname, step.vote.Voice, score,
+				step.wantScore)
+		}
+	}
+}
+
+func TestReactionForumCounts(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestUserForum(t, repo, "alice")
+	addTestUserForum(t, repo, "bob")
+	addTestForumForum(t, repo, "reactions", "author")
+	threadObj := addTestThreadForum(t, repo, "reactions", "author")
+	postObj := addTestPostsForum(t, repo, threadObj, models.Post{Author: "author", Message: "react"})[0]
+
+	for _, reaction := range []models.Reaction{
+		{Emoji: "+1", Nickname: "alice"},
+		{Emoji: "+1", Nickname: "alice"},
+		{Emoji: "+1", Nickname: "bob"},
+		{Emoji: "🎉", Nickname: "bob"},
+	} {
+		reaction.IdPost = postObj.Id
+		if err := repo.AddReactionForum(reaction); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.DeleteReactionForum(models.Reaction{Emoji: "🎉", Nickname: "bob", IdPost: postObj.Id}); err != nil {
+		t.Fatal(err)
+	}
+
+	reactions := getTestPostForum(t, repo, postObj.Id).Reactions
+	if len(reactions) != 1 || reactions["+1"] != 2 {
+		t.Fatalf("reactions = %v, want +1 twice", reactions)
+	}
+}
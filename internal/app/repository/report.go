@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"fmt"
+	"github.com/go-openapi/strfmt"
+	"strings"
+	"time"
+)
+
+func (p *postgresForumRepository) AddReportForum(report models.Report) (models.Report, error) {
+	var query string
+	var target interface{}
+
+	if report.Post.Valid {
+		query = `INSERT INTO report(
+    author,
+    reason,
+    post,
+    thread,
+    forum)
+	SELECT $1, $2, id, thread, forum FROM post WHERE id = $3
+	RETURNING author, closedBy, created, forum, id, post, reason, status, thread`
+		target = report.Post.Int64
+	} else {
+		query = `INSERT INTO report(
+    author,
+    reason,
+    thread,
+    forum)
+	SELECT $1, $2, id, forum FROM thread WHERE id = $3
+	RETURNING author, closedBy, created, forum, id, post, reason, status, thread`
+		target = report.Thread
+	}
+
+	userObj, err := p.GetByNick(report.Author)
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	return p.scanReportForum(p.conn.QueryRow(query, userObj.Nickname, report.Reason, target))
+}
+
+func (p *postgresForumRepository) GetReportForum(id int64) (models.Report, error) {
+	query := `SELECT author, closedBy, created, forum, id, post, reason, status, thread FROM report WHERE id = $1`
+
+	return p.scanReportForum(p.conn.QueryRow(query, id))
+}
+
+func (p *postgresForumRepository) GetReportsForum(slug, status string, limit int, since int64,
+	desc bool, related []string) ([]models.Report, error) {
+	query := `SELECT author, closedBy, created, forum, id, post, reason, status, thread FROM report
+	WHERE forum = $1 AND status = $2 `
+
+	if desc {
+		if since > 0 {
+			query += fmt.Sprintf("AND id < %d ", since)
+		}
+		query += `ORDER BY id DESC `
+	} else {
+		if since > 0 {
+			query += fmt.Sprintf("AND id > %d ", since)
+		}
+		query += `ORDER BY id `
+	}
+	query += `LIMIT NULLIF($3, 0)`
+
+	data := make([]models.Report, 0, 0)
+	row, err := p.conn.Query(query, slug, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if row != nil {
+			row.Close()
+		}
+	}()
+
+	for row.Next() {
+		reportObj, err := p.scanReportForum(row)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, reportObj)
+	}
+	if err = row.Err(); err != nil {
+		return nil, err
+	}
+	row.Close()
+
+	if err = p.loadReportContentsForum(data, related); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (p *postgresForumRepository) CloseReportForum(id int64, status, moderator string) (models.Report, error) {
+	query := `UPDATE report SET status = $1, closedBy = $2 WHERE id = $3 AND status = 'open'
+	RETURNING author, closedBy, created, forum, id, post, reason, status, thread`
+
+	return p.scanReportForum(p.conn.QueryRow(query, status, moderator, id))
+}
+
+func (p *postgresForumRepository) IsModeratorForum(slug, nickname string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM forum WHERE slug = $1 AND "user" = $2)
+	OR EXISTS(SELECT 1 FROM moderator WHERE forum = $1 AND nickname = $2)`
+
+	var isModerator bool
+	err := p.conn.QueryRow(query, slug, nickname).Scan(&isModerator)
+	return isModerator, err
+}
+
+func (p *postgresForumRepository) AddModeratorForum(moderator models.Moderator) (models.Moderator, error) {
+	query := `INSERT INTO moderator(
+    nickname,
+    forum)
+	SELECT u.nickname, f.slug FROM users u, forum f WHERE u.nickname = $1 AND f.slug = $2
+	ON CONFLICT DO NOTHING`
+
+	var moderatorObj models.Moderator
+	userObj, err := p.GetByNick(moderator.Nickname)
+	if err != nil {
+		return moderatorObj, err
+	}
+	forumObj, err := p.GetBySlugForum(moderator.Forum)
+	if err != nil {
+		return moderatorObj, err
+	}
+
+	_, err = p.conn.Exec(query, userObj.Nickname, forumObj.Slug)
+	moderatorObj.Nickname = userObj.Nickname
+	moderatorObj.Forum = forumObj.Slug
+	return moderatorObj, err
+}
+
+func (p *postgresForumRepository) loadReportContentsForum(reports []models.Report, related []string) error {
+	if len(reports) == 0 {
+		return nil
+	}
+
+	withRelated := map[string]bool{}
+	for _, relatedObj := range related {
+		withRelated[relatedObj] = true
+	}
+
+	postIDs := make([]int64, 0, len(reports))
+	threadIDs := make([]int32, 0, len(reports))
+	for _, reportObj := range reports {
+		if reportObj.Post.Valid {
+			postIDs = append(postIDs, reportObj.Post.Int64)
+		}
+		if !reportObj.Post.Valid || withRelated["thread"] {
+			threadIDs = append(threadIDs, reportObj.Thread)
+		}
+	}
+
+	posts := map[int64]models.Post{}
+	if len(postIDs) > 0 {
+		rows, err := p.conn.Query(`SELECT * FROM post WHERE id = ANY($1::bigint[])`, postIDs)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			post, err := scanPostForum(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			posts[post.Id] = post
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	threads := map[int32]models.Thread{}
+	if len(threadIDs) > 0 {
+		rows, err := p.conn.Query(`SELECT * FROM thread WHERE id = ANY($1::int[])`, threadIDs)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			threadObj, err := scanThreadForum(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			threads[threadObj.Id] = threadObj
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	authors := map[string]string{}
+	forums := map[string]bool{}
+	for i, reportObj := range reports {
+		content := map[string]interface{}{}
+		var author, forumSlug string
+		if post, ok := posts[reportObj.Post.Int64]; ok && reportObj.Post.Valid {
+			content["post"] = post
+			author, forumSlug = post.Author, post.Forum
+			if threadObj, ok := threads[post.Thread]; ok && withRelated["thread"] {
+				content["thread"] = threadObj
+			}
+		} else if threadObj, ok := threads[reportObj.Thread]; ok && !reportObj.Post.Valid {
+			content["thread"] = threadObj
+			author, forumSlug = threadObj.Author, threadObj.Forum
+		}
+		if author != "" {
+			authors[strings.ToLower(author)] = author
+			forums[forumSlug] = true
+		}
+		reports[i].Content = content
+	}
+
+	users := map[string]models.User{}
+	if withRelated["user"] && len(authors) > 0 {
+		nicknames := make([]string, 0, len(authors))
+		for _, nickname := range authors {
+			nicknames = append(nicknames, nickname)
+		}
+		rows, err := p.conn.Query(`SELECT About, Email, FullName, Nickname, Karma FROM users
+		WHERE Nickname = ANY($1::citext[])`, nicknames)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var userObj models.User
+			err = rows.Scan(&userObj.About, &userObj.Email, &userObj.FullName, &userObj.Nickname, &userObj.Karma)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			users[strings.ToLower(userObj.Nickname)] = userObj
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	forumObjs := map[string]models.Forum{}
+	if withRelated["forum"] && len(forums) > 0 {
+		slugs := make([]string, 0, len(forums))
+		for slug := range forums {
+			slugs = append(slugs, slug)
+		}
+		rows, err := p.conn.Query(`SELECT `+forumColumns+` FROM forum WHERE slug = ANY($1::citext[])`, slugs)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			forumObj, err := scanForumForum(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			forumObjs[strings.ToLower(forumObj.Slug)] = forumObj
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	for i := range reports {
+		var author, forumSlug string
+		if post, ok := reports[i].Content["post"].(models.Post); ok {
+			author, forumSlug = post.Author, post.Forum
+		} else if threadObj, ok := reports[i].Content["thread"].(models.Thread); ok {
+			author, forumSlug = threadObj.Author, threadObj.Forum
+		}
+		if userObj, ok := users[strings.ToLower(author)]; ok {
+			reports[i].Content["author"] = userObj
+		}
+		if forumObj, ok := forumObjs[strings.ToLower(forumSlug)]; ok {
+			reports[i].Content["forum"] = forumObj
+		}
+	}
+
+	return nil
+}
+
+func (p *postgresForumRepository) scanReportForum(row rowScanner) (models.Report, error) {
+	var reportObj models.Report
+	var created time.Time
+
+	err := row.Scan(&reportObj.Author, &reportObj.Closed, &created, &reportObj.Forum, &reportObj.Id,
+		&reportObj.Post, &reportObj.Reason, &reportObj.Status, &reportObj.Thread)
+	if err != nil {
+		return models.Report{}, err
+	}
+	reportObj.Created = strfmt.DateTime(created.UTC()).String()
+	return reportObj, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+func TestGetReportsForumLoadsRelatedContent(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestUserForum(t, repo, "reporter")
+	addTestForumForum(t, repo, "reports", "author")
+	threadObj := addTestThreadForum(t, repo, "reports", "author")
+	posts := addTestPostsForum(t, repo, threadObj, models.Post{Author: "author", Message: "reported"})
+
+	postReport := models.Report{Author: "reporter", Reason: "spam"}
+	postReport.Post.Valid = true
+	postReport.Post.Int64 = posts[0].Id
+	if _, err := repo.AddReportForum(postReport); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.AddReportForum(models.Report{Author: "reporter", Reason: "off-topic",
+		Thread: threadObj.Id}); err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := repo.GetReportsForum("reports", "open", 0, 0, false, []string{"user", "forum", "thread"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want 2", len(reports))
+	}
+
+	for _, reportObj := range reports {
+		if _, ok := reportObj.Content["thread"].(models.Thread); !ok {
+			t.Errorf("report %d: thread is missing from content", reportObj.Id)
+		}
+		if author, ok := reportObj.Content["author"].(models.User); !ok || author.Nickname != "author" {
+			t.Errorf("report %d: author = %v, want author", reportObj.Id, reportObj.Content["author"])
+		}
+		if forumObj, ok := reportObj.Content["forum"].(models.Forum); !ok || forumObj.Slug != "reports" {
+			t.Errorf("report %d: forum = %v, want reports", reportObj.Id, reportObj.Content["forum"])
+		}
+	}
+	if post, ok := reports[0].Content["post"].(models.Post); !ok || post.Id != posts[0].Id {
+		t.Errorf("post report content = %v, want post %d", reports[0].Content["post"], posts[0].Id)
+	}
+	if _, ok := reports[1].Content["post"]; ok {
+		t.Errorf("thread report content has a post")
+	}
+}
+
+func TestGetReportsForumWithoutRelated(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestForumForum(t, repo, "plain", "author")
+	threadObj := addTestThreadForum(t, repo, "plain", "author")
+	if _, err := repo.AddReportForum(models.Report{Author: "author", Reason: "dup", Thread: threadObj.Id}); err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := repo.GetReportsForum("plain", "open", 0, 0, false, []string{""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || len(reports[0].Content) != 1 {
+		t.Fatalf("reports = %v, want one report with thread content only", reports)
+	}
+}
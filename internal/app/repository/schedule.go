@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+)
+
+func (p *postgresForumRepository) GetDraftsForum(nickname string, limit int) ([]models.Thread, error) {
+	query := `SELECT * FROM thread WHERE author = $1 AND status IN ('draft', 'scheduled')
+	ORDER BY publishAt NULLS LAST, id DESC LIMIT NULLIF($2, 0)`
+
+	return p.queryThreadsForum(query, nickname, limit)
+}
+
+func (p *postgresForumRepository) PublishDraftForum(id int, status, publishAt string) (models.Thread, error) {
+	query := `UPDATE thread SET status = $2, publishAt = NULLIF($3, '')::timestamptz,
+	created = COALESCE(NULLIF($3, '')::timestamptz, now())
+	WHERE id = $1 AND status = 'draft' RETURNING *`
+
+	return scanThreadForum(p.conn.QueryRow(query, id, status, publishAt))
+}
+
+func (p *postgresForumRepository) PublishDueThreadsForum(limit int) ([]models.Thread, error) {
+	query := `UPDATE thread SET status = 'published' WHERE id IN (
+		SELECT id FROM thread WHERE status = 'scheduled' AND publishAt <= now()
+		ORDER BY publishAt LIMIT $1 FOR UPDATE SKIP LOCKED)
+	RETURNING *`
+
+	return p.queryThreadsForum(query, limit)
+}
+
+func (p *postgresForumRepository) queryThreadsForum(query string, args ...interface{}) ([]models.Thread, error) {
+	data := make([]models.Thread, 0, 0)
+	row, err := p.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		threadObj, err := scanThreadForum(row)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, threadObj)
+	}
+	return data, row.Err()
+}
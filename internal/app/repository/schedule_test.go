@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+	"time"
+)
+
+func TestScheduledThreadsForum(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "alice")
+	addTestForumForum(t, repo, "forum", "alice")
+
+	past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339Nano)
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339Nano)
+	var threads []models.Thread
+	for _, thread := range []models.Thread{
+		{Title: "draft", Status: "draft"},
+		{Title: "due", Status: "scheduled", PublishAt: past, Created: past},
+		{Title: "later", Status: "scheduled", PublishAt: future, Created: future},
+	} {
+		thread.Author, thread.Forum, thread.Message = "alice", "forum", "message"
+		created, err := repo.AddThreadForum(thread)
+		if err != nil {
+			t.Fatal(err)
+		}
+		threads = append(threads, created)
+	}
+
+	visible, err := repo.GetThreadsForum(models.ThreadFilter{Forum: "forum", Nickname: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(visible) != 0 {
+		t.Fatalf("visible threads = %v, want drafts and scheduled threads hidden", visible)
+	}
+	drafts, err := repo.GetDraftsForum("ALICE", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 3 || drafts[0].Title != "due" || drafts[2].Title != "draft" {
+		t.Fatalf("drafts = %v, want due, later, draft", drafts)
+	}
+	forumObj, err := repo.GetBySlugForum("forum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forumObj.Threads != 0 {
+		t.Fatalf("forum threads = %d, want 0", forumObj.Threads)
+	}
+
+	published, err := repo.PublishDueThreadsForum(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 1 || published[0].Id != threads[1].Id || published[0].Status != "published" {
+		t.Fatalf("published = %v, want only the due thread", published)
+	}
+	if published, err = repo.PublishDueThreadsForum(10); err != nil || len(published) != 0 {
+		t.Fatalf("second run published %v, %v", published, err)
+	}
+
+	thread, err := repo.PublishDraftForum(int(threads[0].Id), "published", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thread.Status != "published" {
+		t.Fatalf("draft status = %q, want published", thread.Status)
+	}
+	if _, err = repo.PublishDraftForum(int(threads[2].Id), "published", ""); err == nil {
+		t.Fatal("publishing a scheduled thread as a draft succeeded")
+	}
+
+	if forumObj, err = repo.GetBySlugForum("forum"); err != nil {
+		t.Fatal(err)
+	}
+	if forumObj.Threads != 2 {
+		t.Fatalf("forum threads after publishing = %d, want 2", forumObj.Threads)
+	}
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+)
+
+func (p *postgresForumRepository) AddThreadSubscriptionForum(nickname string, threadID int) error {
+	query := `INSERT INTO thread_subscription(
+    nickname,
+    thread)
+	VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	if _, err := p.conn.Exec(query, nickname, threadID); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO thread_read(
+    nickname,
+    thread,
+    lastRead)
+	SELECT $1, $2, COALESCE((SELECT MAX(id) FROM post WHERE thread = $2), 0)
+	ON CONFLICT DO NOTHING`
+
+	_, err := p.conn.Exec(query, nickname, threadID)
+	return err
+}
+
+func (p *postgresForumRepository) DeleteThreadSubscriptionForum(nickname string, threadID int) error {
+	query := `DELETE FROM thread_subscription WHERE nickname = $1 AND thread = $2`
+
+	_, err := p.conn.Exec(query, nickname, threadID)
+	return err
+}
+
+func (p *postgresForumRepository) AddForumSubscriptionForum(nickname, slug string) error {
+	query := `INSERT INTO forum_subscription(
+    nickname,
+    forum,
+    lastRead)
+	VALUES ($1, $2, (SELECT COALESCE(MAX(id), 0) FROM post)) ON CONFLICT DO NOTHING`
+
+	_, err := p.conn.Exec(query, nickname, slug)
+	return err
+}
+
+func (p *postgresForumRepository) DeleteForumSubscriptionForum(nickname, slug string) error {
+	query := `DELETE FROM forum_subscription WHERE nickname = $1 AND forum = $2`
+
+	_, err := p.conn.Exec(query, nickname, slug)
+	return err
+}
+
+func (p *postgresForumRepository) MarkThreadReadForum(nickname string, threadID int, postID int64) (models.ThreadRead, error) {
+	query := `INSERT INTO thread_read(
+    nickname,
+    thread,
+    lastRead)
+	SELECT $1, $2, CASE WHEN $3::bigint > 0 THEN $3::bigint ELSE COALESCE((SELECT MAX(id) FROM post WHERE thread = $2), 0) END
+	ON CONFLICT (thread, nickname) DO UPDATE SET lastRead = GREATEST(thread_read.lastRead, EXCLUDED.lastRead)
+	RETURNING lastRead, nickname, thread`
+
+	var readObj models.ThreadRead
+	err := p.conn.QueryRow(query, nickname, threadID, postID).Scan(&readObj.LastRead, &readObj.Nickname, &readObj.Thread)
+	return readObj, err
+}
+
+func (p *postgresForumRepository) GetSubscriptionsForum(nickname string, limit int) (models.Subscriptions, error) {
+	forumsQuery := `SELECT ` + forumColumns + ` FROM forum
+	JOIN forum_subscription fs ON fs.forum = forum.slug
+	WHERE fs.nickname = $1 ORDER BY forum.slug`
+
+	threadsQuery := `SELECT thread.*, unread.count FROM thread
+	LEFT JOIN thread_subscription ts ON ts.thread = thread.id AND ts.nickname = $1
+	LEFT JOIN forum_subscription fs ON fs.forum = thread.forum AND fs.nickname = $1
+	LEFT JOIN thread_read tr ON tr.thread = thread.id AND tr.nickname = $1
+	CROSS JOIN LATERAL (SELECT COUNT(*) AS count, MAX(id) AS last FROM post
+		WHERE post.thread = thread.id AND post.status = 'published'
+		AND post.id > COALESCE(tr.lastRead, fs.lastRead)) unread
+	WHERE (ts.nickname IS NOT NULL OR (fs.nickname IS NOT NULL AND unread.count > 0))
+	AND (thread.status = 'published' OR (thread.status = 'pending' AND thread.author = $1::citext))
+	ORDER BY unread.count > 0 DESC, unread.last DESC NULLS LAST, thread.id DESC
+	LIMIT NULLIF($2, 0)`
+
+	subscriptions := models.Subscriptions{
+		Forums:  make([]models.Forum, 0, 0),
+		Threads: make([]models.Thread, 0, 0),
+	}
+
+	row, err := p.conn.Query(forumsQuery, nickname)
+	if err != nil {
+		return subscriptions, err
+	}
+	for row.Next() {
+		forumObj, err := scanForumForum(row)
+		if err != nil {
+			row.Close()
+			return subscriptions, err
+		}
+		subscriptions.Forums = append(subscriptions.Forums, forumObj)
+	}
+	row.Close()
+	if err = row.Err(); err != nil {
+		return subscriptions, err
+	}
+
+	row, err = p.conn.Query(threadsQuery, nickname, limit)
+	if err != nil {
+		return subscriptions, err
+	}
+	defer func() {
+		if row != nil {
+			row.Close()
+		}
+	}()
+
+	for row.Next() {
+		var unread int64
+
+		threadObj, err := scanThreadForum(row, &unread)
+		if err != nil {
+			return subscriptions, err
+		}
+		threadObj.Unread = &unread
+		subscriptions.Threads = append(subscriptions.Threads, threadObj)
+	}
+
+	return subscriptions, row.Err()
+}
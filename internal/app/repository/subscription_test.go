@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+func unreadByThreadForum(t *testing.T, repo *postgresForumRepository, forumSlug, nickname string) map[int32]int64 {
+	t.Helper()
+	threads, err := repo.GetThreadsForum(models.ThreadFilter{Forum: forumSlug, Nickname: nickname})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unread := map[int32]int64{}
+	for _, threadObj := range threads {
+		if threadObj.Unread == nil {
+			t.Fatalf("thread %d has no unread count", threadObj.Id)
+		}
+		unread[threadObj.Id] = *threadObj.Unread
+	}
+	return unread
+}
+
+func TestGetThreadsForumUnreadBaseline(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestUserForum(t, repo, "reader")
+	addTestForumForum(t, repo, "unread", "author")
+	opened := addTestThreadForum(t, repo, "unread", "author")
+	unopened := addTestThreadForum(t, repo, "unread", "author")
+	subscribed := addTestThreadForum(t, repo, "unread", "author")
+	for _, threadObj := range []models.Thread{opened, unopened, subscribed} {
+		addTestPostsForum(t, repo, threadObj, models.Post{Author: "author", Message: "one"},
+			models.Post{Author: "author", Message: "two"})
+	}
+
+	if _, err := repo.MarkThreadReadForum("reader", int(opened.Id), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddThreadSubscriptionForum("reader", int(subscribed.Id)); err != nil {
+		t.Fatal(err)
+	}
+	for _, threadObj := range []models.Thread{opened, unopened, subscribed} {
+		addTestPostsForum(t, repo, threadObj, models.Post{Author: "author", Message: "three"})
+	}
+
+	unread := unreadByThreadForum(t, repo, "unread", "reader")
+	want := map[int32]int64{opened.Id: 1, unopened.Id: 0, subscribed.Id: 1}
+	for id, count := range want {
+		if unread[id] != count {
+			t.Errorf("thread %d unread = %d, want %d", id, unread[id], count)
+		}
+	}
+
+	if err := repo.AddForumSubscriptionForum("reader", "unread"); err != nil {
+		t.Fatal(err)
+	}
+	addTestPostsForum(t, repo, unopened, models.Post{Author: "author", Message: "four"})
+
+	if unread = unreadByThreadForum(t, repo, "unread", "reader"); unread[unopened.Id] != 1 {
+		t.Errorf("unopened thread in a subscribed forum unread = %d, want 1", unread[unopened.Id])
+	}
+}
+
+func TestGetSubscriptionsForumOrdersForumThreadsWithNewPosts(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestUserForum(t, repo, "reader")
+	addTestForumForum(t, repo, "followed", "author")
+	addTestForumForum(t, repo, "other", "author")
+	quiet := addTestThreadForum(t, repo, "other", "author")
+	active := addTestThreadForum(t, repo, "followed", "author")
+	stale := addTestThreadForum(t, repo, "followed", "author")
+	addTestPostsForum(t, repo, stale, models.Post{Author: "author", Message: "before"})
+
+	if err := repo.AddThreadSubscriptionForum("reader", int(quiet.Id)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddForumSubscriptionForum("reader", "followed"); err != nil {
+		t.Fatal(err)
+	}
+	addTestPostsForum(t, repo, active, models.Post{Author: "author", Message: "after"})
+
+	subscriptions, err := repo.GetSubscriptionsForum("reader", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subscriptions.Threads) != 2 {
+		t.Fatalf("got %d threads, want the subscribed thread and the forum thread with new posts",
+			len(subscriptions.Threads))
+	}
+	if first := subscriptions.Threads[0]; first.Id != active.Id || *first.Unread != 1 {
+		t.Errorf("first thread = %d with %d unread, want %d with 1", first.Id, *first.Unread, active.Id)
+	}
+	if second := subscriptions.Threads[1]; second.Id != quiet.Id || *second.Unread != 0 {
+		t.Errorf("second thread = %d with %d unread, want %d with 0", second.Id, *second.Unread, quiet.Id)
+	}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+)
+
+func tagsArgForum(tags []string) interface{} {
+	if tags == nil {
+		return nil
+	}
+	return tags
+}
+
+func (p *postgresForumRepository) GetTagsForum(forumSlug string, limit int) ([]models.TagCount, error) {
+	query := `SELECT tag, COUNT(*) FROM thread, unnest(thread.tags) AS tag
+	WHERE ($1 = '' OR thread.forum = $1::citext) AND thread.status = 'published'
+	GROUP BY tag ORDER BY COUNT(*) DESC, tag LIMIT NULLIF($2, 0)`
+
+	data := make([]models.TagCount, 0, 0)
+	row, err := p.conn.Query(query, forumSlug, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var tagObj models.TagCount
+		err = row.Scan(&tagObj.Tag, &tagObj.Threads)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, tagObj)
+	}
+	return data, row.Err()
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+func TestTagsForumFilterAndCounts(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestForumForum(t, repo, "tagged", "author")
+	addTestForumForum(t, repo, "other", "author")
+
+	for _, thread := range []models.Thread{
+		{Forum: "tagged", Tags: []string{"go", "sql"}},
+		{Forum: "tagged", Tags: []string{"go"}},
+		{Forum: "other", Tags: []string{"go", "rust"}},
+	} {
+		thread.Author, thread.Message, thread.Title = "author", "message", "title"
+		if _, err := repo.AddThreadForum(thread); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	threads, err := repo.GetThreadsForum(models.ThreadFilter{Forum: "tagged", Tag: "sql"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(threads) != 1 || len(threads[0].Tags) != 2 {
+		t.Fatalf("threads tagged sql = %v, want one", threads)
+	}
+
+	tests := []struct {
+		forum string
+		want  []models.TagCount
+	}{
+		{"tagged", []models.TagCount{{Tag: "go", Threads: 2}, {Tag: "sql", Threads: 1}}},
+		{"", []models.TagCount{{Tag: "go", Threads: 3}, {Tag: "rust", Threads: 1}, {Tag: "sql", Threads: 1}}},
+	}
+	for _, tt := range tests {
+		tags, err := repo.GetTagsForum(tt.forum, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tags) != len(tt.want) {
+			t.Fatalf("forum %q tags = %v, want %v", tt.forum, tags, tt.want)
+		}
+		for i := range tags {
+			if tags[i] != tt.want[i] {
+				t.Fatalf("forum %q tags = %v, want %v", tt.forum, tags, tt.want)
+			}
+		}
+	}
+}
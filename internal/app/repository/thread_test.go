@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"testing"
+)
+
+func TestGetThreadsForumSorts(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestUserForum(t, repo, "voter")
+	addTestForumForum(t, repo, "sorts", "author")
+	discussed := addTestThreadForum(t, repo, "sorts", "author")
+	popular := addTestThreadForum(t, repo, "sorts", "author")
+	recent := addTestThreadForum(t, repo, "sorts", "author")
+
+	addTestPostsForum(t, repo, discussed, models.Post{Author: "author", Message: "one"},
+		models.Post{Author: "author", Message: "two"}, models.Post{Author: "author", Message: "three"})
+	addTestPostsForum(t, repo, recent, models.Post{Author: "author", Message: "latest"})
+	if _, err := repo.VoteForum(models.Vote{Nickname: "voter", Voice: 1}, models.Thread{Id: popular.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddThreadViewsForum(map[int32]int64{popular.Id: 10, recent.Id: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sort   string
+		window string
+		want   []int32
+	}{
+		{"hot", "", []int32{discussed.Id, recent.Id, popular.Id}},
+		{"top", "", []int32{popular.Id, recent.Id, discussed.Id}},
+		{"top", "1 day", []int32{popular.Id, recent.Id, discussed.Id}},
+		{"active", "", []int32{recent.Id, discussed.Id, popular.Id}},
+		{"views", "", []int32{popular.Id, recent.Id, discussed.Id}},
+	}
+	for _, tt := range tests {
+		threads, err := repo.GetThreadsForum(models.ThreadFilter{Forum: "sorts", Sort: tt.sort, Window: tt.window})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int32
+		for _, threadObj := range threads {
+			got = append(got, threadObj.Id)
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("sort %s: got %v, want %v", tt.sort, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Fatalf("sort %s: got %v, want %v", tt.sort, got, tt.want)
+			}
+		}
+	}
+
+	threadObj, err := repo.GetThreadByIDForum(int(discussed.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if threadObj.Posts != 3 || threadObj.LastPostAt == "" {
+		t.Errorf("discussed thread posts = %d, lastPostAt = %q; want 3 and a timestamp", threadObj.Posts,
+			threadObj.LastPostAt)
+	}
+}
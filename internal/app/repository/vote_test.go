@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestVoteForumConcurrentCountersMatchVotes(t *testing.T) {
+	repo := newTestRepositoryForum(t)
+	addTestUserForum(t, repo, "author")
+	addTestForumForum(t, repo, "votes", "author")
+	threadObj := addTestThreadForum(t, repo, "votes", "author")
+
+	voters := []string{"author"}
+	for i := 0; i < 8; i++ {
+		voters = append(voters, addTestUserForum(t, repo, fmt.Sprintf("voter%d", i)).Nickname)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(voters)*4)
+	for i, nickname := range voters {
+		for worker := 0; worker < 4; worker++ {
+			wg.Add(1)
+			go func(nickname string, seed int64) {
+				defer wg.Done()
+				random := rand.New(rand.NewSource(seed))
+				for step := 0; step < 50; step++ {
+					vote := models.Vote{Nickname: nickname, Voice: int32(random.Intn(3) - 1)}
+					if _, err := repo.VoteForum(vote, models.Thread{Id: threadObj.Id}); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}(nickname, int64(i*4+worker))
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	var votes, sum, karma, forumKarma, wantKarma int64
+	err := repo.conn.QueryRow(`SELECT votes FROM thread WHERE id = $1`, threadObj.Id).Scan(&votes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.conn.QueryRow(`SELECT COALESCE(SUM(voice), 0), COALESCE(SUM(voice) FILTER (WHERE nickname <> 'author'), 0)
+	FROM vote WHERE idThread = $1`, threadObj.Id).Scan(&sum, &wantKarma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.conn.QueryRow(`SELECT karma FROM users WHERE nickname = 'author'`).Scan(&karma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.conn.QueryRow(`SELECT COALESCE((SELECT karma FROM user_karma WHERE forum = 'votes'
+	AND nickname = 'author'), 0)`).Scan(&forumKarma)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if votes != sum {
+		t.Errorf("thread.votes = %d, SUM(voice) = %d", votes, sum)
+	}
+	if karma != wantKarma || forumKarma != wantKarma {
+		t.Errorf("author karma = %d, forum karma = %d, want SUM(voice) of other voters = %d", karma, forumKarma,
+			wantKarma)
+	}
+}
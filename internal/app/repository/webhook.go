@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"DbGODZ/internal/app/models"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/strfmt"
+	"time"
+)
+
+const deliveryColumns = `o.attempts, o.created, o.event, o.id, o.lastError, o.nextAttempt, o.payload::text, o.status,
+	o.webhook, w.secret, w.url`
+
+func (p *postgresForumRepository) AddWebhookForum(webhook models.Webhook) (models.Webhook, error) {
+	query := `INSERT INTO webhook(
+    events,
+    forum,
+    secret,
+    url)
+	VALUES ($1, $2, $3, $4) RETURNING created, events, forum, id, secret, url`
+
+	forumObj, err := p.GetBySlugForum(webhook.Forum)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	return p.scanWebhookForum(p.conn.QueryRow(query, webhook.Events, forumObj.Slug, webhook.Secret, webhook.Url))
+}
+
+func (p *postgresForumRepository) GetWebhookForum(id int) (models.Webhook, error) {
+	query := `SELECT created, events, forum, id, secret, url FROM webhook WHERE id = $1`
+
+	return p.scanWebhookForum(p.conn.QueryRow(query, id))
+}
+
+func (p *postgresForumRepository) GetWebhooksForum(slug string) ([]models.Webhook, error) {
+	query := `SELECT created, events, forum, id, secret, url FROM webhook WHERE forum = $1 ORDER BY id`
+
+	data := make([]models.Webhook, 0, 0)
+	row, err := p.conn.Query(query, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if row != nil {
+			row.Close()
+		}
+	}()
+
+	for row.Next() {
+		webhookObj, err := p.scanWebhookForum(row)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, webhookObj)
+	}
+
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) DeleteWebhookForum(id int) error {
+	query := `DELETE FROM webhook WHERE id = $1`
+
+	_, err := p.conn.Exec(query, id)
+	return err
+}
+
+func (p *postgresForumRepository) ClaimDeliveriesForum(limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	query := fmt.Sprintf(`UPDATE outbox o SET attempts = o.attempts + 1, nextAttempt = now() + $2::bigint * interval '1 millisecond'
+	FROM webhook w
+	WHERE w.id = o.webhook AND o.id IN (
+		SELECT id FROM outbox WHERE status = 'pending' AND nextAttempt <= now()
+		ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED)
+	RETURNING %s`, deliveryColumns)
+
+	return p.queryDeliveriesForum(query, limit, lease.Milliseconds())
+}
+
+func (p *postgresForumRepository) CompleteDeliveryForum(id int64) error {
+	query := `DELETE FROM outbox WHERE id = $1`
+
+	_, err := p.conn.Exec(query, id)
+	return err
+}
+
+func (p *postgresForumRepository) FailDeliveryForum(id int64, lastError string, retryIn time.Duration, dead bool) error {
+	query := `UPDATE outbox SET lastError = $1, nextAttempt = now() + $2::bigint * interval '1 millisecond',
+	status = CASE WHEN $3 THEN 'dead' ELSE 'pending' END WHERE id = $4`
+
+	_, err := p.conn.Exec(query, lastError, retryIn.Milliseconds(), dead, id)
+	return err
+}
+
+func (p *postgresForumRepository) GetDeadDeliveriesForum(slug string, limit int, since int64) ([]models.WebhookDelivery, error) {
+	query := fmt.Sprintf(`SELECT %s FROM outbox o JOIN webhook w ON w.id = o.webhook
+	WHERE w.forum = $1 AND o.status = 'dead' AND o.id > $2
+	ORDER BY o.id LIMIT NULLIF($3, 0)`, deliveryColumns)
+
+	return p.queryDeliveriesForum(query, slug, since, limit)
+}
+
+func (p *postgresForumRepository) GetDeliveryForum(id int64) (models.WebhookDelivery, error) {
+	query := fmt.Sprintf(`SELECT %s FROM outbox o JOIN webhook w ON w.id = o.webhook WHERE o.id = $1`, deliveryColumns)
+
+	return p.scanDeliveryForum(p.conn.QueryRow(query, id))
+}
+
+func (p *postgresForumRepository) RetryDeliveryForum(id int64) (models.WebhookDelivery, error) {
+	query := fmt.Sprintf(`UPDATE outbox o SET status = 'pending', attempts = 0, nextAttempt = now()
+	FROM webhook w
+	WHERE w.id = o.webhook AND o.id = $1 AND o.status = 'dead'
+	RETURNING %s`, deliveryColumns)
+
+	return p.scanDeliveryForum(p.conn.QueryRow(query, id))
+}
+
+func (p *postgresForumRepository) queryDeliveriesForum(query string, args ...interface{}) ([]models.WebhookDelivery, error) {
+	data := make([]models.WebhookDelivery, 0, 0)
+	row, err := p.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if row != nil {
+			row.Close()
+		}
+	}()
+
+	for row.Next() {
+		deliveryObj, err := p.scanDeliveryForum(row)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, deliveryObj)
+	}
+
+	return data, row.Err()
+}
+
+func (p *postgresForumRepository) scanWebhookForum(row rowScanner) (models.Webhook, error) {
+	var webhookObj models.Webhook
+	var created time.Time
+
+	err := row.Scan(&created, &webhookObj.Events, &webhookObj.Forum, &webhookObj.Id, &webhookObj.Secret, &webhookObj.Url)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	webhookObj.Created = strfmt.DateTime(created.UTC()).String()
+	return webhookObj, nil
+}
+
+func (p *postgresForumRepository) scanDeliveryForum(row rowScanner) (models.WebhookDelivery, error) {
+	var deliveryObj models.WebhookDelivery
+	var created, nextAttempt time.Time
+	var payload sql.NullString
+
+	err := row.Scan(&deliveryObj.Attempts, &created, &deliveryObj.Event, &deliveryObj.Id, &deliveryObj.LastError,
+		&nextAttempt, &payload, &deliveryObj.Status, &deliveryObj.Webhook, &deliveryObj.Secret, &deliveryObj.Url)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	deliveryObj.Payload = json.RawMessage(payload.String)
+	deliveryObj.Created = strfmt.DateTime(created.UTC()).String()
+	deliveryObj.NextAttempt = strfmt.DateTime(nextAttempt.UTC()).String()
+	return deliveryObj, nil
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	forum "DbGODZ/internal/app"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const (
+	publishBatch    = 100
+	publishInterval = 15 * time.Second
+)
+
+type Publisher struct {
+	forumRepo forum.Repository
+}
+
+func NewPublisher(fr forum.Repository) *Publisher {
+	return &Publisher{forumRepo: fr}
+}
+
+func (p *Publisher) Run() {
+	for {
+		published, err := p.Publish()
+		if err != nil {
+			log.Error().Msgf("scheduler: %s", err.Error())
+		}
+		if published < publishBatch {
+			time.Sleep(publishInterval)
+		}
+	}
+}
+
+func (p *Publisher) Publish() (int, error) {
+	threads, err := p.forumRepo.PublishDueThreadsForum(publishBatch)
+	if err != nil {
+		return 0, err
+	}
+	return len(threads), nil
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	forum "DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"errors"
+	"testing"
+)
+
+type publisherRepository struct {
+	forum.Repository
+	due    int
+	limits []int
+	err    error
+}
+
+func (r *publisherRepository) PublishDueThreadsForum(limit int) ([]models.Thread, error) {
+	r.limits = append(r.limits, limit)
+	if r.err != nil {
+		return nil, r.err
+	}
+	count := r.due
+	if count > limit {
+		count = limit
+	}
+	r.due -= count
+	return make([]models.Thread, count), nil
+}
+
+func TestPublisherPublish(t *testing.T) {
+	repo := &publisherRepository{due: publishBatch + 3}
+	publisher := NewPublisher(repo)
+
+	for _, want := range []int{publishBatch, 3, 0} {
+		published, err := publisher.Publish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if published != want {
+			t.Fatalf("published = %d, want %d", published, want)
+		}
+	}
+	for _, limit := range repo.limits {
+		if limit != publishBatch {
+			t.Fatalf("limit = %d, want %d", limit, publishBatch)
+		}
+	}
+}
+
+func TestPublisherPublishError(t *testing.T) {
+	publisher := NewPublisher(&publisherRepository{err: errors.New("connection refused")})
+
+	if published, err := publisher.Publish(); err == nil || published != 0 {
+		t.Fatalf("published = %d, err = %v, want an error", published, err)
+	}
+}
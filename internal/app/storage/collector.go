@@ -0,0 +1,57 @@
+package storage
+
+import (
+	forum "DbGODZ/internal/app"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const (
+	collectBatch    = 100
+	collectInterval = time.Minute
+)
+
+type Collector struct {
+	forumRepo forum.Repository
+	storage   Storage
+}
+
+func NewCollector(fr forum.Repository, storage Storage) *Collector {
+	return &Collector{
+		forumRepo: fr,
+		storage:   storage,
+	}
+}
+
+func (c *Collector) Run() {
+	for {
+		collected, err := c.Collect()
+		if err != nil {
+			log.Error().Msgf("attachments: %s", err.Error())
+		}
+		if collected < collectBatch {
+			time.Sleep(collectInterval)
+		}
+	}
+}
+
+func (c *Collector) Collect() (int, error) {
+	attachments, err := c.forumRepo.GetOrphanAttachmentsForum(collectBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	collected := 0
+	for _, attachment := range attachments {
+		err = c.storage.Delete(attachment.StorageKey)
+		if err == nil {
+			err = c.forumRepo.DeleteAttachmentForum(attachment.Id)
+		}
+		if err != nil {
+			log.Error().Msgf("attachments: %d: %s", attachment.Id, err.Error())
+			continue
+		}
+		collected++
+	}
+	return collected, nil
+}
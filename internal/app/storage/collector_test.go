@@ -0,0 +1,68 @@
+package storage
+
+import (
+	forum "DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"errors"
+	"testing"
+)
+
+type collectorRepository struct {
+	forum.Repository
+	orphans   []models.Attachment
+	deleted   []int64
+	deleteErr map[int64]error
+}
+
+func (r *collectorRepository) GetOrphanAttachmentsForum(limit int) ([]models.Attachment, error) {
+	return r.orphans, nil
+}
+
+func (r *collectorRepository) DeleteAttachmentForum(id int64) error {
+	if err := r.deleteErr[id]; err != nil {
+		return err
+	}
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+type collectorStorage struct {
+	Storage
+	deleted   []string
+	deleteErr map[string]error
+}
+
+func (s *collectorStorage) Delete(key string) error {
+	if err := s.deleteErr[key]; err != nil {
+		return err
+	}
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+func TestCollectorCollectContinuesAfterErrors(t *testing.T) {
+	repo := &collectorRepository{
+		orphans: []models.Attachment{
+			{Id: 1, StorageKey: "posts/1/a"},
+			{Id: 2, StorageKey: "posts/1/b"},
+			{Id: 3, StorageKey: "posts/2/c"},
+			{Id: 4, StorageKey: "posts/2/d"},
+		},
+		deleteErr: map[int64]error{3: errors.New("deadlock detected")},
+	}
+	storage := &collectorStorage{deleteErr: map[string]error{"posts/1/a": errors.New("access denied")}}
+
+	collected, err := NewCollector(repo, storage).Collect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collected != 2 {
+		t.Fatalf("collected = %d, want 2", collected)
+	}
+	if len(repo.deleted) != 2 || repo.deleted[0] != 2 || repo.deleted[1] != 4 {
+		t.Fatalf("deleted rows = %v, want [2 4]", repo.deleted)
+	}
+	if len(storage.deleted) != 3 {
+		t.Fatalf("deleted objects = %v, want every object except the failing one", storage.deleted)
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func newTestLocalStorage(t *testing.T) *LocalStorage {
+	t.Helper()
+	root, err := ioutil.TempDir("", "attachments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	storage, err := NewLocalStorage(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return storage
+}
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	storage := newTestLocalStorage(t)
+
+	if err := storage.Put("posts/1/key", "text/plain", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := storage.Get("posts/1/key")
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("Get = %q, %v; want hello", data, err)
+	}
+
+	if err = storage.Delete("posts/1/key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = storage.Get("posts/1/key"); err != ErrNotFound {
+		t.Fatalf("Get after delete error = %v, want ErrNotFound", err)
+	}
+	if err = storage.Delete("posts/1/key"); err != nil {
+		t.Fatalf("deleting a missing object = %v, want nil", err)
+	}
+}
+
+func TestLocalStorageRejectsKeysOutsideRoot(t *testing.T) {
+	storage := newTestLocalStorage(t)
+
+	for _, key := range []string{"../escape", "posts/../../escape", ""} {
+		if err := storage.Put(key, "text/plain", []byte("x")); err != ErrNotFound {
+			t.Errorf("Put(%q) error = %v, want ErrNotFound", key, err)
+		}
+		if _, err := storage.Get(key); err != ErrNotFound {
+			t.Errorf("Get(%q) error = %v, want ErrNotFound", key, err)
+		}
+	}
+}
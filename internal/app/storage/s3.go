@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/valyala/fasthttp"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const s3Timeout = 30 * time.Second
+
+type S3Storage struct {
+	endpoint  string
+	host      string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	client    *fasthttp.Client
+}
+
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey string) (*S3Storage, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("s3 endpoint must be an absolute url: %s", endpoint)
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3Storage{
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		host:      parsed.Host,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &fasthttp.Client{Name: "forum-storage"},
+	}, nil
+}
+
+func (s *S3Storage) Put(key, contentType string, data []byte) error {
+	resp, err := s.do(fasthttp.MethodPut, key, contentType, data)
+	if err != nil {
+		return err
+	}
+	defer fasthttp.ReleaseResponse(resp)
+
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return fmt.Errorf("s3 put %s: status %d", key, resp.StatusCode())
+	}
+	return nil
+}
+
+func (s *S3Storage) Get(key string) ([]byte, error) {
+	resp, err := s.do(fasthttp.MethodGet, key, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer fasthttp.ReleaseResponse(resp)
+
+	switch resp.StatusCode() {
+	case fasthttp.StatusOK:
+		return append([]byte(nil), resp.Body()...), nil
+	case fasthttp.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("s3 get %s: status %d", key, resp.StatusCode())
+	}
+}
+
+func (s *S3Storage) Delete(key string) error {
+	resp, err := s.do(fasthttp.MethodDelete, key, "", nil)
+	if err != nil {
+		return err
+	}
+	defer fasthttp.ReleaseResponse(resp)
+
+	switch resp.StatusCode() {
+	case fasthttp.StatusOK, fasthttp.StatusNoContent, fasthttp.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("s3 delete %s: status %d", key, resp.StatusCode())
+	}
+}
+
+func (s *S3Storage) do(method, key, contentType string, body []byte) (*fasthttp.Response, error) {
+	path := "/" + s.bucket + "/" + escapeKey(key)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI(s.endpoint + path)
+	req.Header.SetMethod(method)
+	if contentType != "" {
+		req.Header.SetContentType(contentType)
+	}
+	req.SetBody(body)
+	s.sign(req, method, path, body, time.Now().UTC())
+
+	resp := fasthttp.AcquireResponse()
+	err := s.client.DoTimeout(req, resp, s3Timeout)
+	if err != nil {
+		fasthttp.ReleaseResponse(resp)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *S3Storage) sign(req *fasthttp.Request, method, path string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		"",
+		"host:" + s.host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	signingKey = hmacSHA256(signingKey, s.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = strings.Replace(url.PathEscape(segment), "+", "%2B", -1)
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
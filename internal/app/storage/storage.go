@@ -0,0 +1,13 @@
+package storage
+
+import (
+	"errors"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+type Storage interface {
+	Put(key, contentType string, data []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
@@ -0,0 +1,196 @@
+package stream
+
+import (
+	forum "DbGODZ/internal/app"
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const Channel = "forum_events"
+
+const subscriptionBuffer = 64
+
+type Event struct {
+	Id     int64
+	Name   string
+	Data   []byte
+	Thread int32
+	Forum  string
+}
+
+type notification struct {
+	Event  string `json:"event"`
+	Id     int64  `json:"id"`
+	Thread int32  `json:"thread"`
+	Forum  string `json:"forum"`
+}
+
+type Subscription struct {
+	Events chan Event
+	thread int32
+	forum  string
+	hub    *Hub
+	once   sync.Once
+}
+
+type Hub struct {
+	pool        *pgx.ConnPool
+	forumRepo   forum.Repository
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+}
+
+func NewHub(pool *pgx.ConnPool, fr forum.Repository) *Hub {
+	return &Hub{
+		pool:        pool,
+		forumRepo:   fr,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+func (h *Hub) SubscribeThread(threadID int32) *Subscription {
+	return h.subscribe(&Subscription{thread: threadID})
+}
+
+func (h *Hub) SubscribeForum(slug string) *Subscription {
+	return h.subscribe(&Subscription{forum: slug})
+}
+
+func (h *Hub) subscribe(sub *Subscription) *Subscription {
+	sub.Events = make(chan Event, subscriptionBuffer)
+	sub.hub = h
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.mu.Lock()
+		delete(s.hub.subscribers, s)
+		s.hub.mu.Unlock()
+		close(s.Events)
+	})
+}
+
+func (s *Subscription) matches(event Event) bool {
+	if s.thread != 0 {
+		return s.thread == event.Thread
+	}
+	return strings.EqualFold(s.forum, event.Forum)
+}
+
+func (h *Hub) Run() {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := h.listen()
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		log.Error().Msgf("stream: %s, reconnecting in %s", err.Error(), backoff)
+		time.Sleep(backoff)
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (h *Hub) listen() error {
+	conn, err := h.pool.Acquire()
+	if err != nil {
+		return err
+	}
+	defer h.pool.Release(conn)
+
+	if err = conn.Listen(Channel); err != nil {
+		return err
+	}
+
+	for {
+		n, err := conn.WaitForNotification(context.Background())
+		if err != nil {
+			return err
+		}
+		h.dispatch(n.Payload)
+	}
+}
+
+func (h *Hub) dispatch(payload string) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		log.Error().Msgf("stream: bad notification %q: %s", payload, err.Error())
+		return
+	}
+
+	event := Event{Name: n.Event, Thread: n.Thread, Forum: n.Forum}
+	if !h.hasSubscribers(event) {
+		return
+	}
+
+	var data interface{}
+	var err error
+	switch n.Event {
+	case "post.created", "post.updated":
+		var post map[string]interface{}
+		post, err = h.forumRepo.GetPostForum(int(n.Id), []string{})
+		data = post["post"]
+		if n.Event == "post.created" {
+			event.Id = n.Id
+		}
+	case "vote.changed":
+		data, err = h.forumRepo.GetThreadByIDForum(int(n.Thread))
+	default:
+		return
+	}
+	if err != nil {
+		log.Error().Msgf("stream: %s %d: %s", n.Event, n.Id, err.Error())
+		return
+	}
+
+	if event.Data, err = json.Marshal(data); err != nil {
+		log.Error().Msgf("stream: %s %d: %s", n.Event, n.Id, err.Error())
+		return
+	}
+	h.Publish(event)
+}
+
+func (h *Hub) hasSubscribers(event Event) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscribers {
+		if sub.matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Hub) Publish(event Event) {
+	var slow []*Subscription
+
+	h.mu.RLock()
+	for sub := range h.subscribers {
+		if !sub.matches(event) {
+			continue
+		}
+		select {
+		case sub.Events <- event:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range slow {
+		sub.Close()
+	}
+}
@@ -0,0 +1,67 @@
+package views
+
+import (
+	forum "DbGODZ/internal/app"
+	"github.com/rs/zerolog/log"
+	"sync"
+	"time"
+)
+
+const flushInterval = 5 * time.Second
+
+type Counter struct {
+	forumRepo forum.Repository
+	mu        sync.Mutex
+	pending   map[int32]int64
+}
+
+func NewCounter(fr forum.Repository) *Counter {
+	return &Counter{
+		forumRepo: fr,
+		pending:   make(map[int32]int64),
+	}
+}
+
+func (c *Counter) Add(threadID int32) {
+	c.mu.Lock()
+	c.pending[threadID]++
+	c.mu.Unlock()
+}
+
+func (c *Counter) Pending(threadID int32) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pending[threadID]
+}
+
+func (c *Counter) Run() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.Flush()
+	}
+}
+
+func (c *Counter) Flush() {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = make(map[int32]int64, len(batch))
+	c.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	err := c.forumRepo.AddThreadViewsForum(batch)
+	if err == nil {
+		return
+	}
+	log.Error().Msgf("views: %s", err.Error())
+
+	c.mu.Lock()
+	for threadID, count := range batch {
+		c.pending[threadID] += count
+	}
+	c.mu.Unlock()
+}
@@ -0,0 +1,73 @@
+package views
+
+import (
+	forum "DbGODZ/internal/app"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type viewsRepository struct {
+	forum.Repository
+	err     error
+	batches []map[int32]int64
+}
+
+func (r *viewsRepository) AddThreadViewsForum(views map[int32]int64) error {
+	r.batches = append(r.batches, views)
+	return r.err
+}
+
+func TestCounterFlushBatchesPendingViews(t *testing.T) {
+	repo := &viewsRepository{}
+	counter := NewCounter(repo)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			counter.Add(int32(i%2 + 1))
+		}(i)
+	}
+	wg.Wait()
+
+	if pending := counter.Pending(1); pending != 50 {
+		t.Fatalf("pending views for thread 1 = %d, want 50", pending)
+	}
+
+	counter.Flush()
+	if len(repo.batches) != 1 || repo.batches[0][1] != 50 || repo.batches[0][2] != 50 {
+		t.Fatalf("flushed batches = %v, want one batch with 50 views per thread", repo.batches)
+	}
+	if pending := counter.Pending(1); pending != 0 {
+		t.Fatalf("pending views after flush = %d, want 0", pending)
+	}
+
+	counter.Flush()
+	if len(repo.batches) != 1 {
+		t.Fatalf("empty flush wrote a batch: %v", repo.batches)
+	}
+}
+
+func TestCounterFlushKeepsViewsOnError(t *testing.T) {
+	repo := &viewsRepository{err: errors.New("connection refused")}
+	counter := NewCounter(repo)
+	counter.Add(7)
+	counter.Add(7)
+
+	counter.Flush()
+	counter.Add(7)
+	if pending := counter.Pending(7); pending != 3 {
+		t.Fatalf("pending views after failed flush = %d, want 3", pending)
+	}
+
+	repo.err = nil
+	counter.Flush()
+	if last := repo.batches[len(repo.batches)-1]; last[7] != 3 {
+		t.Fatalf("retried batch = %v, want 3 views for thread 7", last)
+	}
+	if pending := counter.Pending(7); pending != 0 {
+		t.Fatalf("pending views after retry = %d, want 0", pending)
+	}
+}
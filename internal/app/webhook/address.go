@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"fmt"
+	"github.com/valyala/fasthttp"
+	"net"
+	"strings"
+	"time"
+)
+
+const dialTimeout = 5 * time.Second
+
+var lookupIP = net.LookupIP
+
+var blockedNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func blockedIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func resolvePublic(host string) ([]net.IP, error) {
+	if strings.EqualFold(strings.TrimSuffix(host, "."), "localhost") {
+		return nil, fmt.Errorf("webhook host %s is not allowed", host)
+	}
+
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		var err error
+		if ips, err = lookupIP(host); err != nil {
+			return nil, fmt.Errorf("can't resolve webhook host %s", host)
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("can't resolve webhook host %s", host)
+		}
+	}
+	for _, ip := range ips {
+		if blockedIP(ip) {
+			return nil, fmt.Errorf("webhook host %s resolves to a private address %s", host, ip)
+		}
+	}
+	return ips, nil
+}
+
+func CheckHost(host string) error {
+	_, err := resolvePublic(host)
+	return err
+}
+
+func dialPublic(addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := resolvePublic(host)
+	if err != nil {
+		return nil, err
+	}
+	return fasthttp.DialTimeout(net.JoinHostPort(ips[0].String(), port), dialTimeout)
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"DbGODZ/internal/app/models"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+func stubLookupIP(t *testing.T, addresses map[string][]string) {
+	t.Helper()
+	previous := lookupIP
+	lookupIP = func(host string) ([]net.IP, error) {
+		resolved, ok := addresses[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		ips := make([]net.IP, 0, len(resolved))
+		for _, address := range resolved {
+			ips = append(ips, net.ParseIP(address))
+		}
+		return ips, nil
+	}
+	t.Cleanup(func() { lookupIP = previous })
+}
+
+func TestCheckHost(t *testing.T) {
+	stubLookupIP(t, map[string][]string{
+		"hooks.example.com":  {"93.184.216.34"},
+		"rebind.example.com": {"93.184.216.34", "10.0.0.7"},
+		"metadata.internal":  {"169.254.169.254"},
+		"v6.example.com":     {"2606:4700::6810:84e5"},
+	})
+
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::6810:84e5", true},
+		{"hooks.example.com", true},
+		{"v6.example.com", true},
+		{"localhost", false},
+		{"LOCALHOST.", false},
+		{"127.0.0.1", false},
+		{"127.1.2.3", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"172.31.255.255", false},
+		{"192.168.1.1", false},
+		{"100.64.0.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00:ec2::254", false},
+		{"224.0.0.1", false},
+		{"metadata.internal", false},
+		{"rebind.example.com", false},
+		{"unknown.example.com", false},
+	}
+
+	for _, tt := range tests {
+		err := CheckHost(tt.host)
+		if (err == nil) != tt.allowed {
+			t.Errorf("CheckHost(%q) = %v, want allowed %t", tt.host, err, tt.allowed)
+		}
+	}
+}
+
+func TestDialPublicResolvesAtDeliveryTime(t *testing.T) {
+	addresses := map[string][]string{"hooks.example.com": {"93.184.216.34"}}
+	stubLookupIP(t, addresses)
+	if err := CheckHost("hooks.example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	addresses["hooks.example.com"] = []string{"127.0.0.1"}
+	conn, err := dialPublic("hooks.example.com:80")
+	if err == nil {
+		conn.Close()
+		t.Fatal("dialPublic connected to a host that now resolves to loopback")
+	}
+	if !strings.Contains(err.Error(), "private address") {
+		t.Fatalf("dialPublic error = %v, want a private address rejection", err)
+	}
+}
+
+func TestSendRejectsPrivateEndpoints(t *testing.T) {
+	d := NewDispatcher(nil)
+	err := d.send(models.WebhookDelivery{Url: "http://127.0.0.1:1/hook", Payload: []byte("{}")})
+	if err == nil || !strings.Contains(err.Error(), "private address") {
+		t.Fatalf("send error = %v, want a private address rejection", err)
+	}
+}
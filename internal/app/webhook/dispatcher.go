@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	forum "DbGODZ/internal/app"
+	"DbGODZ/internal/app/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	batchSize    = 32
+	maxAttempts  = 8
+	pollInterval = time.Second
+	lease        = time.Minute
+	timeout      = 10 * time.Second
+)
+
+var Events = []string{"thread.created", "post.created", "post.updated", "vote.changed"}
+
+type Dispatcher struct {
+	forumRepo forum.Repository
+	client    *fasthttp.Client
+}
+
+func NewDispatcher(fr forum.Repository) *Dispatcher {
+	return &Dispatcher{
+		forumRepo: fr,
+		client:    &fasthttp.Client{Name: "forum-webhooks", Dial: dialPublic},
+	}
+}
+
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func Backoff(attempts int32) time.Duration {
+	delay := 10 * time.Second << uint(attempts-1)
+	if delay > time.Hour || delay <= 0 {
+		return time.Hour
+	}
+	return delay
+}
+
+func (d *Dispatcher) Run() {
+	for {
+		deliveries, err := d.forumRepo.ClaimDeliveriesForum(batchSize, lease)
+		if err != nil {
+			log.Error().Msgf("webhook: %s", err.Error())
+		}
+		if len(deliveries) == 0 {
+			time.Sleep(pollInterval)
+			continue
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery models.WebhookDelivery) {
+				defer wg.Done()
+				d.deliver(delivery)
+			}(delivery)
+		}
+		wg.Wait()
+	}
+}
+
+func (d *Dispatcher) deliver(delivery models.WebhookDelivery) {
+	err := d.send(delivery)
+	if err == nil {
+		err = d.forumRepo.CompleteDeliveryForum(delivery.Id)
+	} else {
+		dead := delivery.Attempts >= maxAttempts
+		err = d.forumRepo.FailDeliveryForum(delivery.Id, err.Error(), Backoff(delivery.Attempts), dead)
+	}
+	if err != nil {
+		log.Error().Msgf("webhook: delivery %d: %s", delivery.Id, err.Error())
+	}
+}
+
+func (d *Dispatcher) send(delivery models.WebhookDelivery) error {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(delivery.Url)
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("X-Forum-Event", delivery.Event)
+	req.Header.Set("X-Forum-Delivery", strconv.FormatInt(delivery.Id, 10))
+	req.Header.Set("X-Forum-Signature", Sign(delivery.Secret, delivery.Payload))
+	req.SetBody(delivery.Payload)
+
+	if err := d.client.DoTimeout(req, resp, timeout); err != nil {
+		return err
+	}
+	if code := resp.StatusCode(); code < 200 || code >= 300 {
+		return fmt.Errorf("endpoint answered with status %d", code)
+	}
+	return nil
+}
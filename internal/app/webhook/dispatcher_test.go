@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	want := "sha256=03def589620c813f198fd03d7967e292b163ef0435ebf43071ce0e9519763cb7"
+	if got := Sign("secret", []byte(`{"id":1}`)); got != want {
+		t.Fatalf("Sign = %q, want %q", got, want)
+	}
+	if Sign("other", []byte(`{"id":1}`)) == want || Sign("secret", []byte(`{"id":2}`)) == want {
+		t.Fatal("Sign does not depend on both the secret and the body")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{5, 160 * time.Second},
+		{10, time.Hour},
+		{64, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
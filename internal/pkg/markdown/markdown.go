@@ -0,0 +1,62 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+	"regexp"
+	"strings"
+)
+
+const quoteClose = "[/quote]"
+
+var quoteOpen = regexp.MustCompile(`\[quote=(\d+)\]`)
+
+var converter = goldmark.New(
+	goldmark.WithExtensions(extension.Strikethrough, extension.Linkify, extension.Table),
+	goldmark.WithRendererOptions(html.WithHardWraps()),
+)
+
+func Render(message string) string {
+	var buf bytes.Buffer
+	err := converter.Convert([]byte(expandQuotes(message)), &buf)
+	if err != nil {
+		return Sanitize("<p>" + message + "</p>")
+	}
+	return Sanitize(buf.String())
+}
+
+func expandQuotes(message string) string {
+	limit := len(message)
+	for {
+		opens := quoteOpen.FindAllStringSubmatchIndex(message[:limit], -1)
+		if len(opens) == 0 {
+			return message
+		}
+
+		open := opens[len(opens)-1]
+		end := strings.Index(message[open[1]:], quoteClose)
+		if end < 0 {
+			limit = open[0]
+			continue
+		}
+
+		quote := quoteBlock(message[open[2]:open[3]], message[open[1]:open[1]+end])
+		message = message[:open[0]] + quote + message[open[1]+end+len(quoteClose):]
+		limit = open[0] + len(quote)
+	}
+}
+
+func quoteBlock(postID, body string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\n> [>>%s](/api/post/%s/details)\n>\n", postID, postID)
+	for _, line := range strings.Split(strings.Trim(body, "\r\n"), "\n") {
+		b.WriteString("> ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
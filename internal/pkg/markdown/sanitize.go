@@ -0,0 +1,127 @@
+package markdown
+
+import (
+	"golang.org/x/net/html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"blockquote": nil,
+	"br":         nil,
+	"code":       {"class"},
+	"del":        nil,
+	"em":         nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"img":        {"src", "alt", "title"},
+	"li":         nil,
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        nil,
+	"strong":     nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"align"},
+	"th":         {"align"},
+	"thead":      nil,
+	"tr":         nil,
+	"ul":         nil,
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+var droppedContent = map[string]bool{"script": true, "style": true, "iframe": true, "object": true,
+	"textarea": true, "title": true}
+
+var allowedSchemes = map[string]bool{"": true, "http": true, "https": true, "mailto": true}
+
+var attrPatterns = map[string]*regexp.Regexp{
+	"align": regexp.MustCompile(`^(left|right|center)$`),
+	"class": regexp.MustCompile(`^language-[\w-]+$`),
+	"start": regexp.MustCompile(`^\d+$`),
+}
+
+func Sanitize(input string) string {
+	var b strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(input))
+	dropped := 0
+
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			return b.String()
+		case html.TextToken:
+			if dropped == 0 {
+				b.WriteString(html.EscapeString(string(tokenizer.Text())))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if droppedContent[token.Data] {
+				if tokenType == html.StartTagToken {
+					dropped++
+				}
+				continue
+			}
+			if _, ok := allowedTags[token.Data]; ok && dropped == 0 {
+				writeStartTag(&b, token)
+			}
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			if droppedContent[token.Data] {
+				if dropped > 0 {
+					dropped--
+				}
+				continue
+			}
+			if _, ok := allowedTags[token.Data]; ok && dropped == 0 && !voidTags[token.Data] {
+				b.WriteString("</" + token.Data + ">")
+			}
+		}
+	}
+}
+
+func writeStartTag(b *strings.Builder, token html.Token) {
+	b.WriteString("<" + token.Data)
+	for _, attr := range token.Attr {
+		if attr.Namespace != "" || !allowedAttr(token.Data, attr.Key) {
+			continue
+		}
+		if (attr.Key == "href" || attr.Key == "src") && !safeURL(attr.Val) {
+			continue
+		}
+		if pattern, ok := attrPatterns[attr.Key]; ok && !pattern.MatchString(attr.Val) {
+			continue
+		}
+		b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	if token.Data == "a" {
+		b.WriteString(` rel="nofollow noopener"`)
+	}
+	b.WriteString(">")
+}
+
+func allowedAttr(tag, key string) bool {
+	for _, allowed := range allowedTags[tag] {
+		if allowed == key {
+			return true
+		}
+	}
+	return false
+}
+
+func safeURL(raw string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	return allowedSchemes[strings.ToLower(parsed.Scheme)]
+}
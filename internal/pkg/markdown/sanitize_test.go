@@ -0,0 +1,111 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"allowed markup", `<p><strong>bold</strong> <em>it</em></p>`, `<p><strong>bold</strong> <em>it</em></p>`},
+		{"https link", `<a href="https://example.com/a?b=1&amp;c=2">x</a>`,
+			`<a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener">x</a>`},
+		{"relative link", `<a href="/api/post/1/details">x</a>`, `<a href="/api/post/1/details" rel="nofollow noopener">x</a>`},
+		{"javascript url", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"uppercase javascript url", `<a href="JaVaScRiPt:alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"padded javascript url", `<a href="  javascript:alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"tab in javascript url", "<a href=\"java\tscript:alert(1)\">x</a>", `<a rel="nofollow noopener">x</a>`},
+		{"entity encoded javascript url", `<a href="jav&#x61;script&colon;alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"decimal entity javascript url", `<a href="&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;alert(1)">x</a>`,
+			`<a rel="nofollow noopener">x</a>`},
+		{"data url image", `<img src="data:image/svg+xml;base64,PHN2Zz4=" alt="a">`, `<img alt="a">`},
+		{"data url link", `<a href="data:text/html,<script>alert(1)</script>">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"vbscript url", `<a href="vbscript:msgbox(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"event handler", `<img src="/a.png" onerror="alert(1)">`, `<img src="/a.png">`},
+		{"style attribute", `<p style="background:url(javascript:alert(1))">x</p>`, `<p>x</p>`},
+		{"quote breaking title", `<a title='" onmouseover="alert(1)'>x</a>`,
+			`<a title="&#34; onmouseover=&#34;alert(1)" rel="nofollow noopener">x</a>`},
+		{"injected rel", `<a href="/x" rel="opener">x</a>`, `<a href="/x" rel="nofollow noopener">x</a>`},
+		{"code class pattern", `<code class="language-go">x</code><code class="x onclick">y</code>`,
+			`<code class="language-go">x</code><code>y</code>`},
+		{"align pattern", `<td align="left">a</td><td align="left;color:red">b</td>`, `<td align="left">a</td><td>b</td>`},
+		{"script dropped with content", `a<script>alert(1)</script>b`, `ab`},
+		{"nested dropped content", `<style><script>x</script></style>ok`, `ok`},
+		{"iframe dropped", `<iframe src="https://evil.example"></iframe>ok`, `ok`},
+		{"unknown tag keeps text", `<svg><g>text</g></svg>`, `text`},
+		{"text is escaped", `1 &lt; 2 &amp; "q"`, `1 &lt; 2 &amp; &#34;q&#34;`},
+		{"comment dropped", `<!-- <script>alert(1)</script> -->ok`, `ok`},
+		{"namespaced attribute", `<a xlink:href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"unclosed tag", `<p>open`, `<p>open`},
+		{"void end tag", `<br></br>`, `<br>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.input); got != tt.want {
+				t.Fatalf("Sanitize(%q)\n got %q\nwant %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderDropsRawHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		contains string
+		excludes []string
+	}{
+		{"inline html", `hello <b onclick="x">there</b>`, "hello", []string{"<b", "onclick"}},
+		{"html block", "<div>\n<script>alert(1)</script>\n</div>", "", []string{"<div", "<script", "alert"}},
+		{"markdown javascript link", `[x](javascript:alert(1))`, "<a", []string{"javascript"}},
+		{"markdown data image", `![x](data:image/png;base64,AAAA)`, "<img", []string{"data:"}},
+		{"autolink", `see https://example.com`, `href="https://example.com"`, nil},
+		{"entity in link", `[x](jav&#x61;script:alert(1))`, "<a", []string{"javascript", "script:"}},
+		{"emphasis", `**bold** and ~~gone~~`, "<strong>bold</strong>", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.message)
+			if !strings.Contains(got, tt.contains) {
+				t.Fatalf("Render(%q) = %q, want it to contain %q", tt.message, got, tt.contains)
+			}
+			for _, excluded := range tt.excludes {
+				if strings.Contains(got, excluded) {
+					t.Fatalf("Render(%q) = %q, must not contain %q", tt.message, got, excluded)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderQuotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		contains []string
+	}{
+		{"quote links to post", "[quote=42]earlier[/quote]reply",
+			[]string{`<blockquote>`, `href="/api/post/42/details"`, `earlier`, `<p>reply</p>`}},
+		{"nested quotes", "[quote=1][quote=2]inner[/quote]outer[/quote]",
+			[]string{`href="/api/post/1/details"`, `href="/api/post/2/details"`, `inner`, `outer`}},
+		{"unterminated quote", "[quote=3]no end", []string{`[quote=3]no end`}},
+		{"non numeric id", "[quote=x]text[/quote]", []string{`[quote=x]text[/quote]`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.message)
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Fatalf("Render(%q) = %q, want it to contain %q", tt.message, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/andybalholm/brotli"
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+)
+
+const minCompressLength = 1 << 10
+
+func Compress(req fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		req(ctx)
+		if ctx.Response.IsBodyStream() || len(ctx.Response.Header.Peek("Content-Encoding")) > 0 ||
+			len(ctx.Response.Body()) < minCompressLength || !compressibleType(ctx.Response.Header.ContentType()) {
+			return
+		}
+
+		ctx.Response.Header.Add("Vary", "Accept-Encoding")
+		var compressed []byte
+		var encoding string
+		switch {
+		case ctx.Request.Header.HasAcceptEncoding("br"):
+			var buf bytes.Buffer
+			writer := brotli.NewWriterLevel(&buf, brotli.DefaultCompression)
+			if _, err := writer.Write(ctx.Response.Body()); err != nil {
+				log.Error().Msgf(err.Error())
+				return
+			}
+			if err := writer.Close(); err != nil {
+				log.Error().Msgf(err.Error())
+				return
+			}
+			compressed, encoding = buf.Bytes(), "br"
+		case ctx.Request.Header.HasAcceptEncoding("gzip"):
+			compressed = fasthttp.AppendGzipBytesLevel(nil, ctx.Response.Body(), fasthttp.CompressDefaultCompression)
+			encoding = "gzip"
+		default:
+			return
+		}
+
+		if etag := ctx.Response.Header.Peek("ETag"); len(etag) > 0 && !bytes.HasPrefix(etag, []byte("W/")) {
+			ctx.Response.Header.Set("ETag", "W/"+string(etag))
+		}
+		ctx.Response.Header.Set("Content-Encoding", encoding)
+		ctx.Response.SetBody(compressed)
+	}
+}
+
+func compressibleType(contentType []byte) bool {
+	return bytes.HasPrefix(contentType, []byte("application/json")) || bytes.HasPrefix(contentType, []byte("text/"))
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/andybalholm/brotli"
+	"github.com/valyala/fasthttp"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompress(t *testing.T) {
+	large := `{"message":"` + strings.Repeat("a", 2*minCompressLength) + `"}`
+
+	tests := []struct {
+		name         string
+		accept       string
+		body         string
+		wantEncoding string
+	}{
+		{"brotli", "gzip, br", large, "br"},
+		{"gzip", "gzip", large, "gzip"},
+		{"identity", "", large, ""},
+		{"small body", "gzip, br", `{"id":1}`, ""},
+	}
+
+	for _, tt := range tests {
+		ctx := newTestCtx("GET", map[string]string{"Accept-Encoding": tt.accept})
+		Compress(ConditionalGET(jsonHandler(tt.body, "", time.Time{})))(ctx)
+
+		if encoding := string(ctx.Response.Header.Peek("Content-Encoding")); encoding != tt.wantEncoding {
+			t.Fatalf("%s: Content-Encoding = %q, want %q", tt.name, encoding, tt.wantEncoding)
+		}
+
+		var body []byte
+		var err error
+		switch tt.wantEncoding {
+		case "br":
+			body, err = ioutil.ReadAll(brotli.NewReader(bytes.NewReader(ctx.Response.Body())))
+		case "gzip":
+			body, err = ctx.Response.BodyGunzip()
+		default:
+			body = ctx.Response.Body()
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		if string(body) != tt.body {
+			t.Fatalf("%s: decoded body has %d bytes, want %d", tt.name, len(body), len(tt.body))
+		}
+
+		etag := string(ctx.Response.Header.Peek("ETag"))
+		if (tt.wantEncoding != "") != strings.HasPrefix(etag, "W/") {
+			t.Fatalf("%s: ETag = %q", tt.name, etag)
+		}
+	}
+}
+
+func TestCompressKeepsStreamsAndEncodedBodies(t *testing.T) {
+	ctx := newTestCtx("GET", map[string]string{"Accept-Encoding": "gzip"})
+	Compress(func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.SetContentType("application/json")
+		ctx.Response.Header.Set("Content-Encoding", "identity")
+		ctx.SetBodyString(strings.Repeat("a", 2*minCompressLength))
+	})(ctx)
+
+	if encoding := string(ctx.Response.Header.Peek("Content-Encoding")); encoding != "identity" {
+		t.Fatalf("Content-Encoding = %q, want identity", encoding)
+	}
+}
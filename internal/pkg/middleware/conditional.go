@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/valyala/fasthttp"
+	"hash/fnv"
+	"strings"
+)
+
+func ETag(body []byte) string {
+	hash := fnv.New64a()
+	hash.Write(body)
+	return fmt.Sprintf(`"%x-%x"`, len(body), hash.Sum64())
+}
+
+func ConditionalGET(req fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		req(ctx)
+		if !ctx.IsGet() || ctx.Response.StatusCode() != fasthttp.StatusOK || ctx.Response.IsBodyStream() ||
+			!bytes.HasPrefix(ctx.Response.Header.ContentType(), []byte("application/json")) {
+			return
+		}
+
+		etag := string(ctx.Response.Header.Peek("ETag"))
+		if etag == "" {
+			etag = ETag(ctx.Response.Body())
+			ctx.Response.Header.Set("ETag", etag)
+		}
+
+		if ifNoneMatch := ctx.Request.Header.Peek("If-None-Match"); len(ifNoneMatch) > 0 {
+			if etagMatches(string(ifNoneMatch), etag) {
+				notModified(ctx)
+			}
+			return
+		}
+
+		ifModifiedSince := ctx.Request.Header.Peek("If-Modified-Since")
+		lastModifiedHeader := ctx.Response.Header.Peek("Last-Modified")
+		if len(ifModifiedSince) == 0 || len(lastModifiedHeader) == 0 {
+			return
+		}
+		since, err := fasthttp.ParseHTTPDate(ifModifiedSince)
+		if err != nil {
+			return
+		}
+		lastModified, err := fasthttp.ParseHTTPDate(lastModifiedHeader)
+		if err == nil && !lastModified.After(since) {
+			notModified(ctx)
+		}
+	}
+}
+
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func notModified(ctx *fasthttp.RequestCtx) {
+	ctx.Response.ResetBody()
+	ctx.SetStatusCode(fasthttp.StatusNotModified)
+}
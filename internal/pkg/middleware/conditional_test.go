@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"github.com/valyala/fasthttp"
+	"testing"
+	"time"
+)
+
+func jsonHandler(body, etag string, lastModified time.Time) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.SetContentType("application/json")
+		if etag != "" {
+			ctx.Response.Header.Set("ETag", etag)
+		}
+		if !lastModified.IsZero() {
+			ctx.Response.Header.SetLastModified(lastModified)
+		}
+		ctx.SetBodyString(body)
+	}
+}
+
+func httpDate(t time.Time) string {
+	return string(fasthttp.AppendHTTPDate(nil, t))
+}
+
+func TestConditionalGET(t *testing.T) {
+	body := `{"id":1}`
+	etag := ETag([]byte(body))
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		method     string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{"no validators", "GET", nil, 200},
+		{"matching etag", "GET", map[string]string{"If-None-Match": etag}, 304},
+		{"weak etag", "GET", map[string]string{"If-None-Match": "W/" + etag}, 304},
+		{"etag in list", "GET", map[string]string{"If-None-Match": `"other", ` + etag}, 304},
+		{"wildcard", "GET", map[string]string{"If-None-Match": "*"}, 304},
+		{"non-matching etag", "GET", map[string]string{"If-None-Match": `"stale"`}, 200},
+		{"non-matching etag wins over date", "GET", map[string]string{"If-None-Match": `"stale"`,
+			"If-Modified-Since": httpDate(modified.Add(time.Hour))}, 200},
+		{"not modified since", "GET", map[string]string{"If-Modified-Since": httpDate(modified)}, 304},
+		{"modified since", "GET", map[string]string{
+			"If-Modified-Since": httpDate(modified.Add(-time.Hour))}, 200},
+		{"malformed date", "GET", map[string]string{"If-Modified-Since": "yesterday"}, 200},
+		{"post", "POST", map[string]string{"If-None-Match": etag}, 200},
+	}
+
+	for _, tt := range tests {
+		ctx := newTestCtx(tt.method, tt.headers)
+		ConditionalGET(jsonHandler(body, "", modified))(ctx)
+
+		if ctx.Response.StatusCode() != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", tt.name, ctx.Response.StatusCode(), tt.wantStatus)
+		}
+		if tt.wantStatus == 304 && len(ctx.Response.Body()) != 0 {
+			t.Fatalf("%s: 304 carried a body %q", tt.name, ctx.Response.Body())
+		}
+		if tt.wantStatus == 200 && string(ctx.Response.Body()) != body {
+			t.Fatalf("%s: body = %q, want %q", tt.name, ctx.Response.Body(), body)
+		}
+		if tt.method == "GET" && string(ctx.Response.Header.Peek("ETag")) != etag {
+			t.Fatalf("%s: ETag = %q, want %q", tt.name, ctx.Response.Header.Peek("ETag"), etag)
+		}
+	}
+}
+
+func TestConditionalGETKeepsHandlerETag(t *testing.T) {
+	handler := ConditionalGET(jsonHandler(`{"views":2}`, `"thread"`, time.Time{}))
+
+	ctx := newTestCtx("GET", map[string]string{"If-None-Match": ETag([]byte(`{"views":2}`))})
+	handler(ctx)
+	if ctx.Response.StatusCode() != 200 || string(ctx.Response.Header.Peek("ETag")) != `"thread"` {
+		t.Fatalf("status = %d, ETag = %q, want the handler's ETag", ctx.Response.StatusCode(),
+			ctx.Response.Header.Peek("ETag"))
+	}
+
+	ctx = newTestCtx("GET", map[string]string{"If-None-Match": `"thread"`})
+	handler(ctx)
+	if ctx.Response.StatusCode() != 304 {
+		t.Fatalf("status = %d, want 304", ctx.Response.StatusCode())
+	}
+}
+
+func TestConditionalGETMatchesWeakHandlerETag(t *testing.T) {
+	handler := ConditionalGET(jsonHandler(`{"views":2}`, `W/"thread"`, time.Time{}))
+
+	for _, ifNoneMatch := range []string{`W/"thread"`, `"thread"`} {
+		ctx := newTestCtx("GET", map[string]string{"If-None-Match": ifNoneMatch})
+		handler(ctx)
+		if ctx.Response.StatusCode() != 304 {
+			t.Fatalf("If-None-Match %s: status = %d, want 304", ifNoneMatch, ctx.Response.StatusCode())
+		}
+	}
+}
+
+func TestConditionalGETSkipsErrorsAndOtherTypes(t *testing.T) {
+	handlers := map[string]fasthttp.RequestHandler{
+		"error": func(ctx *fasthttp.RequestCtx) {
+			ctx.Response.Header.SetContentType("application/json")
+			ctx.SetStatusCode(404)
+		},
+		"text": func(ctx *fasthttp.RequestCtx) {
+			ctx.Response.Header.SetContentType("image/png")
+			ctx.SetBodyString("png")
+		},
+	}
+
+	for name, handler := range handlers {
+		ctx := newTestCtx("GET", map[string]string{"If-None-Match": "*"})
+		ConditionalGET(handler)(ctx)
+		if ctx.Response.StatusCode() == 304 || len(ctx.Response.Header.Peek("ETag")) > 0 {
+			t.Fatalf("%s: status = %d, ETag = %q", name, ctx.Response.StatusCode(), ctx.Response.Header.Peek("ETag"))
+		}
+	}
+}
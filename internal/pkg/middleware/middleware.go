@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"DbGODZ/internal/pkg/res"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+)
+
+func JSONSetContentType(req fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.Set("Content-Type", "application/json")
+		req(ctx)
+	}
+}
+
+func RequestError(ctx *fasthttp.RequestCtx, err error) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	if err == fasthttp.ErrBodyTooLarge {
+		res.SendResponse(fasthttp.StatusRequestEntityTooLarge, res.HttpError{Message: "request body is too large"}, ctx)
+		return
+	}
+	res.SendResponse(fasthttp.StatusBadRequest, res.HttpError{Message: "error when parsing request"}, ctx)
+}
+
+func RequestID(req fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		requestID := string(ctx.Request.Header.Peek("X-Request-Id"))
+		if requestID == "" {
+			buf := make([]byte, 16)
+			if _, err := rand.Read(buf); err != nil {
+				log.Error().Msgf(err.Error())
+			}
+			requestID = hex.EncodeToString(buf)
+		}
+		ctx.SetUserValue("request_id", requestID)
+		ctx.Response.Header.Set("X-Request-Id", requestID)
+		req(ctx)
+	}
+}
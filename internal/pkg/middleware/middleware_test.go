@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"DbGODZ/internal/pkg/res"
+	"encoding/json"
+	"errors"
+	"github.com/valyala/fasthttp"
+	"testing"
+)
+
+func newTestCtx(method string, headers map[string]string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	for key, value := range headers {
+		ctx.Request.Header.Set(key, value)
+	}
+	return ctx
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := RequestID(func(ctx *fasthttp.RequestCtx) {
+		seen, _ = ctx.UserValue("request_id").(string)
+	})
+
+	ctx := newTestCtx("GET", map[string]string{"X-Request-Id": "abc"})
+	handler(ctx)
+	if seen != "abc" || string(ctx.Response.Header.Peek("X-Request-Id")) != "abc" {
+		t.Fatalf("request id = %q, header = %q, want abc", seen, ctx.Response.Header.Peek("X-Request-Id"))
+	}
+
+	ctx = newTestCtx("GET", nil)
+	handler(ctx)
+	if len(seen) != 32 || string(ctx.Response.Header.Peek("X-Request-Id")) != seen {
+		t.Fatalf("generated request id = %q, header = %q", seen, ctx.Response.Header.Peek("X-Request-Id"))
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantStatus int
+	}{
+		{fasthttp.ErrBodyTooLarge, 413},
+		{errors.New("malformed request"), 400},
+	}
+
+	for _, tt := range tests {
+		ctx := &fasthttp.RequestCtx{}
+		RequestError(ctx, tt.err)
+
+		if ctx.Response.StatusCode() != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", tt.err, ctx.Response.StatusCode(), tt.wantStatus)
+		}
+		var httpErr res.HttpError
+		if err := json.Unmarshal(ctx.Response.Body(), &httpErr); err != nil || httpErr.Message == "" {
+			t.Fatalf("%s: body = %q", tt.err, ctx.Response.Body())
+		}
+		if contentType := string(ctx.Response.Header.ContentType()); contentType != "application/json" {
+			t.Fatalf("%s: Content-Type = %q", tt.err, contentType)
+		}
+	}
+}
+
+func TestJSONSetContentType(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	JSONSetContentType(func(ctx *fasthttp.RequestCtx) {})(ctx)
+
+	if contentType := string(ctx.Response.Header.ContentType()); contentType != "application/json" {
+		t.Fatalf("Content-Type = %q", contentType)
+	}
+}
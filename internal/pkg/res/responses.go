@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/valyala/fasthttp"
 	"net/http"
+	"time"
 )
 
 func SendServerError(errorMessage string, ctx *fasthttp.RequestCtx) {
@@ -27,3 +28,16 @@ func SendResponse(code int, data interface{}, ctx *fasthttp.RequestCtx) {
 func SendResponseOK(data interface{}, ctx *fasthttp.RequestCtx) {
 	SendResponse(200, data, ctx)
 }
+
+func SetLastModified(ctx *fasthttp.RequestCtx, timestamps ...string) {
+	var lastModified time.Time
+	for _, timestamp := range timestamps {
+		parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+		if err == nil && parsed.After(lastModified) {
+			lastModified = parsed
+		}
+	}
+	if !lastModified.IsZero() {
+		ctx.Response.Header.SetLastModified(lastModified)
+	}
+}